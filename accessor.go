@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+)
+
+// RequestContextAccessorBeanID is an ID of the built-in RequestContextAccessor bean.
+const RequestContextAccessorBeanID = "requestContextAccessor"
+
+type requestContextKey struct{}
+
+// RequestContextAccessor is a built-in bean that lets non-Request beans (e.g. singletons) access the context of the web
+// request they are called from, without becoming Request-scoped themselves. Go has no goroutine-local storage, so the
+// caller's context still has to be passed explicitly: the accessor resolves the request context (populated by
+// Middleware) from it. It can be injected by type: `di.inject:""`.
+type RequestContextAccessor struct {
+}
+
+// RegisterRequestContextAccessor function registers the RequestContextAccessor bean in the container under the
+// RequestContextAccessorBeanID.
+func RegisterRequestContextAccessor() (overwritten bool, err error) {
+	return RegisterBeanInstance(RequestContextAccessorBeanID, &RequestContextAccessor{})
+}
+
+// RequestContext returns the context of the web request populated by Middleware, or `false` if the passed context
+// doesn't belong to any request.
+func (*RequestContextAccessor) RequestContext(ctx context.Context) (context.Context, bool) {
+	return ScopeFromContext(ctx)
+}
+
+// Value returns the value associated with the key in the request context, or `nil` if the passed context doesn't
+// belong to any request.
+func (rca *RequestContextAccessor) Value(ctx context.Context, key interface{}) interface{} {
+	requestContext, ok := rca.RequestContext(ctx)
+	if !ok {
+		return nil
+	}
+	return requestContext.Value(key)
+}
+
+// RequestBean returns the Request-scoped bean instance of the request the passed context belongs to.
+func (rca *RequestContextAccessor) RequestBean(ctx context.Context, beanID string) (interface{}, bool) {
+	beanInstance := rca.Value(ctx, beanKey{beanID: beanID})
+	return beanInstance, beanInstance != nil
+}
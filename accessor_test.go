@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type traceIDKey struct{}
+
+type singletonBeanWithAccessor struct {
+	Accessor *RequestContextAccessor `di.inject:""`
+}
+
+func (suite *TestSuite) TestRequestContextAccessor() {
+	overwritten, err := RegisterRequestContextAccessor()
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("singletonBean", reflect.TypeOf((*singletonBeanWithAccessor)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	singletonBeanInstance := GetInstance("singletonBean").(*singletonBeanWithAccessor)
+	assert.NotNil(suite.T(), singletonBeanInstance.Accessor)
+	_, ok := singletonBeanInstance.Accessor.RequestContext(context.Background())
+	assert.False(suite.T(), ok)
+	assert.Nil(suite.T(), singletonBeanInstance.Accessor.Value(context.Background(), traceIDKey{}))
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		requestContext, ok := singletonBeanInstance.Accessor.RequestContext(ctx)
+		assert.True(suite.T(), ok)
+		assert.NotNil(suite.T(), requestContext)
+		assert.Equal(suite.T(), "trace", singletonBeanInstance.Accessor.Value(ctx, traceIDKey{}))
+		requestBeanInstance, ok := singletonBeanInstance.Accessor.RequestBean(ctx, "requestBean")
+		assert.True(suite.T(), ok)
+		assert.IsType(suite.T(), (*requestBean)(nil), requestBeanInstance)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request = request.WithContext(context.WithValue(request.Context(), traceIDKey{}, "trace"))
+	middleware.ServeHTTP(httptest.NewRecorder(), request)
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AmbiguityPolicy defines how the container reacts when more than one bean can be injected by type into the field.
+type AmbiguityPolicy int
+
+const (
+	// AmbiguityError policy makes the injection fail with AmbiguousDependencyError. This is the default policy.
+	AmbiguityError AmbiguityPolicy = iota
+	// PreferPrimary policy injects the candidate marked as primary (see MarkPrimary).
+	PreferPrimary
+	// PreferQualifierMatch policy injects the candidate with the ID matching the qualifier of the field: the value of the
+	// `di.qualifier` tag or, if there's none, the name of the field (case-insensitively).
+	PreferQualifierMatch
+	// PreferSamePackage policy injects the candidate of the type declared in the same package as the bean.
+	PreferSamePackage
+)
+
+// OptionalAmbiguityPolicy defines how the container reacts when the AmbiguityPolicy doesn't narrow the candidates for
+// the injection by type into the optional field (`di.optional:"true"` or `di.onMissing:"nil"`) down to one bean.
+type OptionalAmbiguityPolicy int
+
+const (
+	// FailOnOptionalAmbiguity policy makes the injection fail with AmbiguousDependencyError, same as for required
+	// fields. This is the default policy.
+	FailOnOptionalAmbiguity OptionalAmbiguityPolicy = iota
+	// SkipAmbiguousOptional policy leaves the field uninitialized (nil).
+	SkipAmbiguousOptional
+	// PickPrimaryForOptional policy injects the candidate marked as primary (see MarkPrimary), regardless of the
+	// AmbiguityPolicy. If there's no single primary candidate, the field is left uninitialized (nil).
+	PickPrimaryForOptional
+)
+
+var ambiguityPolicy = AmbiguityError
+
+var optionalAmbiguityPolicy = FailOnOptionalAmbiguity
+
+var primaryBeans = make(map[string]bool)
+
+// SetAmbiguityPolicy function sets the policy defining how the container reacts when more than one bean can be
+// injected by type into the field. If the policy doesn't narrow the candidates down to exactly one bean, the injection
+// fails with AmbiguousDependencyError anyway.
+func SetAmbiguityPolicy(policy AmbiguityPolicy) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	ambiguityPolicy = policy
+	invalidateInjectionPlans()
+}
+
+// SetOptionalAmbiguityPolicy function sets the policy defining how the container reacts when more than one bean can be
+// injected by type into the optional field, and the AmbiguityPolicy can't break the tie.
+func SetOptionalAmbiguityPolicy(policy OptionalAmbiguityPolicy) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	optionalAmbiguityPolicy = policy
+	invalidateInjectionPlans()
+}
+
+// MarkPrimary function marks registered beans as primary, so that they're preferred over other candidates of the same
+// type by the PreferPrimary policy. Beans registered by type can also be marked with a tag `di.primary:"true"`.
+func MarkPrimary(beanIDs ...string) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't mark beans as primary")
+	}
+	for _, beanID := range beanIDs {
+		if !isBeanRegistered(beanID) {
+			return errors.New("bean is not registered: " + beanID)
+		}
+		primaryBeans[beanID] = true
+	}
+	return nil
+}
+
+// isPrimary function checks whether the bean type is marked as primary with the `di.primary` tag.
+func isPrimary(bean reflect.Type) bool {
+	beanElement := bean.Elem()
+	if beanElement.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < beanElement.NumField(); i++ {
+		if primary.get(beanElement.Field(i)) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// breakTie function picks one of the candidates for the injection into the field of the bean according to the
+// ambiguity policy. The returned reason explains the choice, it's empty if the tie can't be broken.
+func breakTie(beanID string, field reflect.StructField, candidates []string) (string, string) {
+	var matches []string
+	var reason string
+	switch ambiguityPolicy {
+	case PreferPrimary:
+		for _, candidate := range candidates {
+			if primaryBeans[candidate] {
+				matches = append(matches, candidate)
+			}
+		}
+		reason = "the primary candidate of the type is injected"
+	case PreferQualifierMatch:
+		qualifier, ok := qualifier.lookup(field)
+		if !ok {
+			qualifier = field.Name
+		}
+		for _, candidate := range candidates {
+			beanQualifier, ok := beanQualifiers[candidate]
+			if strings.EqualFold(candidate, qualifier) || ok && strings.EqualFold(beanQualifier, qualifier) {
+				matches = append(matches, candidate)
+			}
+		}
+		reason = "the candidate matching the qualifier " + qualifier + " is injected"
+	case PreferSamePackage:
+		beanType, ok := getBeanType(beanID)
+		if !ok {
+			break
+		}
+		packagePath := typePackagePath(beanType)
+		for _, candidate := range candidates {
+			if candidateType, ok := getBeanType(candidate); ok && typePackagePath(candidateType) == packagePath {
+				matches = append(matches, candidate)
+			}
+		}
+		reason = "the candidate from the same package is injected"
+	}
+	if len(matches) != 1 {
+		return "", ""
+	}
+	if isTracing() {
+		logrus.WithFields(logrus.Fields{
+			"bean":       beanID,
+			"field":      field.Name,
+			"candidates": candidates,
+			"chosen":     matches[0],
+		}).Trace(reason)
+	}
+	return matches[0], reason
+}
+
+// breakOptionalTie function picks one of the candidates for the injection into the optional field of the bean according
+// to the optional ambiguity policy, if the ambiguity policy can't break the tie. The returned ID is empty if the field
+// should be left uninitialized.
+func breakOptionalTie(beanID string, field reflect.StructField, candidates []string) (string, string) {
+	chosen := ""
+	reason := "multiple candidates found, the optional field is left uninitialized"
+	if optionalAmbiguityPolicy == PickPrimaryForOptional {
+		for _, candidate := range candidates {
+			if !primaryBeans[candidate] {
+				continue
+			}
+			if chosen != "" {
+				chosen = ""
+				break
+			}
+			chosen = candidate
+		}
+		if chosen != "" {
+			reason = "the primary candidate of the type is injected into the optional field"
+		}
+	}
+	if isTracing() {
+		logrus.WithFields(logrus.Fields{
+			"bean":       beanID,
+			"field":      field.Name,
+			"candidates": candidates,
+			"chosen":     chosen,
+		}).Trace(reason)
+	}
+	return chosen, reason
+}
+
+func typePackagePath(beanType reflect.Type) string {
+	if beanType.Kind() == reflect.Ptr {
+		beanType = beanType.Elem()
+	}
+	return beanType.PkgPath()
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type primaryGreeter struct {
+	Primary struct{} `di.primary:"true"`
+}
+
+func (*primaryGreeter) Greet() string {
+	return "primary"
+}
+
+type qualifiedConsumer struct {
+	Qualified greeter `di.inject:"" di.qualifier:"english"`
+	French    greeter `di.inject:""`
+}
+
+type optionalGreeterConsumer struct {
+	Greeter greeter `di.inject:"" di.optional:"true"`
+}
+
+type readerConsumer struct {
+	Reader io.Reader `di.inject:""`
+}
+
+type emptyReader struct{}
+
+func (*emptyReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (suite *TestSuite) TestPreferPrimaryPolicy() {
+	SetAmbiguityPolicy(PreferPrimary)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*greeterConsumer)
+	assert.Same(suite.T(), GetInstance("primary"), consumer.Greeter)
+	assert.Len(suite.T(), consumer.Greeters, 2)
+}
+
+func (suite *TestSuite) TestPreferPrimaryPolicyWithBeansMarkedAsPrimary() {
+	SetAmbiguityPolicy(PreferPrimary)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("typed", &typedGreeter{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = MarkPrimary("typed")
+	assert.NoError(suite.T(), err)
+	err = MarkPrimary("missing")
+	assert.EqualError(suite.T(), err, "bean is not registered: missing")
+	explanation, err := Explain("consumer")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "the primary candidate of the type is injected", explanation.Dependencies[0].Reason)
+	assert.Equal(suite.T(), []string{"english", "typed"}, explanation.Dependencies[0].Candidates)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("typed"), GetInstance("consumer").(*greeterConsumer).Greeter)
+}
+
+func (suite *TestSuite) TestPreferPrimaryPolicyWithoutPrimaryCandidate() {
+	SetAmbiguityPolicy(PreferPrimary)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("typed", &typedGreeter{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, err)
+}
+
+func (suite *TestSuite) TestPreferQualifierMatchPolicy() {
+	SetAmbiguityPolicy(PreferQualifierMatch)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("french", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*qualifiedConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*qualifiedConsumer)
+	assert.Same(suite.T(), GetInstance("english"), consumer.Qualified)
+	assert.IsType(suite.T(), &frenchGreeter{}, consumer.French)
+}
+
+func (suite *TestSuite) TestPreferSamePackagePolicy() {
+	SetAmbiguityPolicy(PreferSamePackage)
+	_, err := RegisterBeanInstance("buffer", &bytes.Buffer{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("emptyReader", reflect.TypeOf((*emptyReader)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*readerConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("emptyReader"), GetInstance("consumer").(*readerConsumer).Reader)
+}
+
+func (suite *TestSuite) TestAmbiguityPolicyIsResetAndRestored() {
+	SetAmbiguityPolicy(PreferPrimary)
+	SetOptionalAmbiguityPolicy(SkipAmbiguousOptional)
+	_, err := RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	snapshot := Snapshot()
+	Reset()
+	assert.Equal(suite.T(), AmbiguityError, ambiguityPolicy)
+	assert.Equal(suite.T(), FailOnOptionalAmbiguity, optionalAmbiguityPolicy)
+	assert.Empty(suite.T(), primaryBeans)
+	Restore(snapshot)
+	assert.Equal(suite.T(), PreferPrimary, ambiguityPolicy)
+	assert.Equal(suite.T(), SkipAmbiguousOptional, optionalAmbiguityPolicy)
+	assert.True(suite.T(), primaryBeans["primary"])
+}
+
+func (suite *TestSuite) TestOptionalAmbiguityFailsByDefault() {
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*optionalGreeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, err)
+}
+
+func (suite *TestSuite) TestSkipAmbiguousOptionalPolicy() {
+	SetOptionalAmbiguityPolicy(SkipAmbiguousOptional)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*optionalGreeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	explanation, err := Explain("consumer")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "multiple candidates found, the optional field is left uninitialized",
+		explanation.Dependencies[0].Reason)
+	assert.Equal(suite.T(), []string{"english", "primary"}, explanation.Dependencies[0].Candidates)
+	assert.Empty(suite.T(), explanation.Dependencies[0].Injected)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("consumer").(*optionalGreeterConsumer).Greeter)
+}
+
+func (suite *TestSuite) TestSkipAmbiguousOptionalPolicyDoesNotApplyToRequiredFields() {
+	SetOptionalAmbiguityPolicy(SkipAmbiguousOptional)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, err)
+}
+
+func (suite *TestSuite) TestPickPrimaryForOptionalPolicy() {
+	SetOptionalAmbiguityPolicy(PickPrimaryForOptional)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("primary", reflect.TypeOf((*primaryGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*optionalGreeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	explanation, err := Explain("consumer")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "the primary candidate of the type is injected into the optional field",
+		explanation.Dependencies[0].Reason)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("primary"), GetInstance("consumer").(*optionalGreeterConsumer).Greeter)
+}
+
+func (suite *TestSuite) TestPickPrimaryForOptionalPolicyWithoutPrimaryCandidate() {
+	SetOptionalAmbiguityPolicy(PickPrimaryForOptional)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("typed", &typedGreeter{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*optionalGreeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("consumer").(*optionalGreeterConsumer).Greeter)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+)
+
+// BeanIDOf function derives the bean ID from the type: it's the package path and the name of the type the pointer
+// refers to, e.g. `github.com/acme/app/services.UserService`. Names of instantiated generic types include their type
+// arguments, so that different instantiations don't collide, e.g.
+// `github.com/acme/app/repositories.Repository[github.com/acme/app/model.User]`. It returns an empty string for
+// unnamed types.
+func BeanIDOf(beanType reflect.Type) string {
+	if beanType.Kind() == reflect.Ptr {
+		beanType = beanType.Elem()
+	}
+	if beanType.Name() == "" {
+		return ""
+	}
+	if beanType.PkgPath() == "" {
+		return beanType.Name()
+	}
+	return beanType.PkgPath() + "." + beanType.Name()
+}
+
+// BeanIDOfT function derives the bean ID from the type `T` (see BeanIDOf), e.g. `BeanIDOfT[*services.UserService]()`.
+func BeanIDOfT[T any]() string {
+	return BeanIDOf(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// RegisterBeanAuto function registers bean by type (see RegisterBean), deriving its ID from the type (see BeanIDOf).
+// Unlike RegisterBean, it never overwrites beans: registration fails if the derived ID is already taken. The returned
+// ID can be used to refer to the bean, e.g. in `di.inject` tags.
+func RegisterBeanAuto(beanType reflect.Type) (beanID string, err error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if beanType.Kind() != reflect.Ptr {
+		return "", errors.New("bean type must be a pointer")
+	}
+	beanID = BeanIDOf(beanType)
+	if beanID == "" {
+		return "", errors.New("can't derive bean ID from unnamed type: " + beanType.String())
+	}
+	if isBeanRegistered(beanID) {
+		return "", errors.New("bean ID derived from the type is already registered: " + beanID)
+	}
+	if _, err := registerBean(beanID, beanType, nil); err != nil {
+		return "", err
+	}
+	return beanID, nil
+}
+
+// RegisterBeanOf function registers bean of type `T` with the given ID (see RegisterBean), sparing the reflection
+// boilerplate, which is especially verbose for instantiated generic types, e.g.
+// `RegisterBeanOf[*repositories.Repository[model.User]]("userRepository")`.
+func RegisterBeanOf[T any](beanID string) (overwritten bool, err error) {
+	return RegisterBean(beanID, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// RegisterBeanT function registers bean of type `T` with the ID derived from the type, e.g.
+// `RegisterBeanT[*services.UserService]()` (see RegisterBeanAuto).
+func RegisterBeanT[T any]() (beanID string, err error) {
+	return RegisterBeanAuto(reflect.TypeOf((*T)(nil)).Elem())
+}
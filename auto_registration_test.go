@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type autoRegisteredService struct {
+	Dependency *singletonDependency `di.inject:"github.com/goioc/di.singletonDependency"`
+}
+
+func (suite *TestSuite) TestBeanIDOf() {
+	assert.Equal(suite.T(), "github.com/goioc/di.singletonDependency",
+		BeanIDOf(reflect.TypeOf((*singletonDependency)(nil))))
+	assert.Equal(suite.T(), "github.com/goioc/di.singletonDependency", BeanIDOfT[*singletonDependency]())
+	assert.Equal(suite.T(), "string", BeanIDOfT[string]())
+	assert.Empty(suite.T(), BeanIDOfT[*struct{}]())
+}
+
+func (suite *TestSuite) TestRegisterBeanAuto() {
+	beanID, err := RegisterBeanT[*singletonDependency]()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "github.com/goioc/di.singletonDependency", beanID)
+	beanID, err = RegisterBeanAuto(reflect.TypeOf((*autoRegisteredService)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "github.com/goioc/di.autoRegisteredService", beanID)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance(BeanIDOfT[*singletonDependency]()),
+		GetInstance(beanID).(*autoRegisteredService).Dependency)
+}
+
+func (suite *TestSuite) TestRegisterBeanAutoDetectsCollisions() {
+	SetOverwritePolicy(OverwriteWarn)
+	_, err := RegisterBeanT[*singletonDependency]()
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanT[*singletonDependency]()
+	assert.EqualError(suite.T(), err,
+		"bean ID derived from the type is already registered: github.com/goioc/di.singletonDependency")
+}
+
+func (suite *TestSuite) TestRegisterBeanAutoRequiresNamedPointerTypes() {
+	_, err := RegisterBeanT[*struct{}]()
+	assert.EqualError(suite.T(), err, "can't derive bean ID from unnamed type: *struct {}")
+	_, err = RegisterBeanT[singletonDependency]()
+	assert.EqualError(suite.T(), err, "bean type must be a pointer")
+}
+
+type genericUser struct{}
+
+type genericOrder struct{}
+
+type genericStore[T any] interface {
+	Save(item T)
+}
+
+type genericRepository[T any] struct {
+	items []T
+}
+
+func (gr *genericRepository[T]) Save(item T) {
+	gr.items = append(gr.items, item)
+}
+
+type genericService[T any] struct {
+	Store      genericStore[T]       `di.inject:""`
+	Repository *genericRepository[T] `di.inject:""`
+}
+
+func (suite *TestSuite) TestBeanIDOfGenericType() {
+	assert.Equal(suite.T(), "github.com/goioc/di.genericRepository[github.com/goioc/di.genericUser]",
+		BeanIDOfT[*genericRepository[genericUser]]())
+	assert.Equal(suite.T(), "github.com/goioc/di.genericRepository[*github.com/goioc/di.genericUser]",
+		BeanIDOfT[*genericRepository[*genericUser]]())
+	assert.NotEqual(suite.T(), BeanIDOfT[*genericRepository[genericUser]](),
+		BeanIDOfT[*genericRepository[genericOrder]]())
+}
+
+func (suite *TestSuite) TestGenericBeans() {
+	userRepositoryID, err := RegisterBeanT[*genericRepository[genericUser]]()
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanT[*genericRepository[genericOrder]]()
+	assert.NoError(suite.T(), err)
+	overwritten, err := RegisterBeanOf[*genericService[genericUser]]("userService")
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanOf[*genericService[genericOrder]]("orderService")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{userRepositoryID}, GetBeanIDsOf[genericStore[genericUser]]())
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	userService := GetInstance("userService").(*genericService[genericUser])
+	assert.Same(suite.T(), GetInstance(userRepositoryID), userService.Store)
+	assert.Same(suite.T(), userService.Repository, userService.Store)
+	orderService := GetInstance("orderService").(*genericService[genericOrder])
+	assert.Same(suite.T(), GetInstance(BeanIDOfT[*genericRepository[genericOrder]]()), orderService.Store)
+	stores, err := GetInstancesOf[genericStore[genericOrder]]()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), stores, 1)
+}
+
+func (suite *TestSuite) TestRegisterBeanOfRequiresPointerTypes() {
+	_, err := RegisterBeanOf[genericRepository[genericUser]]("repository")
+	assert.EqualError(suite.T(), err, "bean type must be a pointer")
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// BeanDefinition describes the registered bean.
+type BeanDefinition struct {
+	// ID of the bean.
+	ID string
+	// Type of the bean, nil for the beans registered with factories (since their real return type is unknown), unless
+	// the factory is registered with RegisterTypedBeanFactory.
+	Type reflect.Type
+	// Scope of the bean.
+	Scope Scope
+	// Factory is set to `true` for the beans registered with factories.
+	Factory bool
+	// Instance is set to `true` for the beans registered as pre-created instances.
+	Instance bool
+}
+
+// BeanDefinitionRegistry lets bean definition postprocessors inspect and mutate bean definitions before any bean is
+// instantiated.
+type BeanDefinitionRegistry struct{}
+
+var beanDefinitionPostprocessors []func(registry *BeanDefinitionRegistry) error
+
+// RegisterBeanDefinitionPostprocessor function registers postprocessors for bean definitions. Such postprocessor is
+// called upon container initialization, after all the beans are registered, but before any of them is instantiated. It
+// can change scopes, swap types, register or remove beans (analogous to Spring's BeanFactoryPostProcessor).
+// Postprocessors are called in registration order.
+func RegisterBeanDefinitionPostprocessor(postprocessor func(registry *BeanDefinitionRegistry) error) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register bean definition postprocessor")
+	}
+	beanDefinitionPostprocessors = append(beanDefinitionPostprocessors, postprocessor)
+	return nil
+}
+
+func postprocessBeanDefinitions() error {
+	registry := &BeanDefinitionRegistry{}
+	for _, postprocessor := range beanDefinitionPostprocessors {
+		if err := postprocessor(registry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BeanIDs method returns sorted IDs of all registered beans.
+func (bdr *BeanDefinitionRegistry) BeanIDs() []string {
+	beanIDs := make([]string, 0, len(scopes))
+	for beanID := range scopes {
+		beanIDs = append(beanIDs, beanID)
+	}
+	sort.Strings(beanIDs)
+	return beanIDs
+}
+
+// GetBeanDefinition method returns the definition of the bean.
+func (bdr *BeanDefinitionRegistry) GetBeanDefinition(beanID string) (BeanDefinition, bool) {
+	if !isBeanRegistered(beanID) {
+		return BeanDefinition{}, false
+	}
+	_, factory := beanFactories[beanID]
+	beanType, _ := getBeanType(beanID)
+	return BeanDefinition{
+		ID:       beanID,
+		Type:     beanType,
+		Scope:    scopes[beanID],
+		Factory:  factory,
+		Instance: userCreatedInstances[beanID],
+	}, true
+}
+
+// SetScope method changes the scope of the bean. Scopes of the beans registered as pre-created instances can't be
+// changed.
+func (bdr *BeanDefinitionRegistry) SetScope(beanID string, beanScope Scope) error {
+	return setScope(beanID, beanScope)
+}
+
+func setScope(beanID string, beanScope Scope) error {
+	if !isBeanRegistered(beanID) {
+		return errors.New("bean is not registered: " + beanID)
+	}
+	if userCreatedInstances[beanID] {
+		return errors.New("scope of the bean instance can't be changed: " + beanID)
+	}
+	if !isSupportedScope(beanScope) {
+		return errors.New("unsupported scope: " + string(beanScope))
+	}
+	scopes[beanID] = beanScope
+	if beanScope != Singleton {
+		delete(lazyBeans, beanID)
+	}
+	return nil
+}
+
+// SetType method swaps the type of the bean registered by type, keeping its scope.
+func (bdr *BeanDefinitionRegistry) SetType(beanID string, beanType reflect.Type) error {
+	if _, ok := beans[beanID]; !ok || userCreatedInstances[beanID] {
+		return errors.New("bean is not registered by type: " + beanID)
+	}
+	registration := saveBeanRegistration(beanID)
+	unregisterBean(beanID)
+	_, err := registerBean(beanID, beanType, &registration.beanScope)
+	if err == nil && !isBeanRegistered(beanID) {
+		err = errors.New("profile of the type is not active: " + beanType.String())
+	}
+	if err != nil {
+		restoreBeanRegistration(beanID, registration)
+		return err
+	}
+	return nil
+}
+
+// RegisterBean method registers the bean by type (see RegisterBean function).
+func (bdr *BeanDefinitionRegistry) RegisterBean(beanID string, beanType reflect.Type) (overwritten bool, err error) {
+	return registerBean(beanID, beanType, nil)
+}
+
+// RemoveBean method removes the bean from the container.
+func (bdr *BeanDefinitionRegistry) RemoveBean(beanID string) error {
+	if !isBeanRegistered(beanID) {
+		return errors.New("bean is not registered: " + beanID)
+	}
+	unregisterBean(beanID)
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestSuite) TestBeanDefinitionPostprocessor() {
+	overwritten, err := RegisterBean("handler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("counter", reflect.TypeOf((*prototypeCounter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanInstance("gateway", &stubGateway{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("obsolete", reflect.TypeOf((*debugHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanDefinitionPostprocessor(func(registry *BeanDefinitionRegistry) error {
+		assert.Equal(suite.T(), []string{"counter", "gateway", "handler", "obsolete"}, registry.BeanIDs())
+		definition, ok := registry.GetBeanDefinition("gateway")
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), BeanDefinition{ID: "gateway", Type: reflect.TypeOf((*stubGateway)(nil)),
+			Scope: Singleton, Instance: true}, definition)
+		_, ok = registry.GetBeanDefinition("unknown")
+		assert.False(suite.T(), ok)
+		assert.Error(suite.T(), registry.SetScope("gateway", Prototype))
+		assert.Error(suite.T(), registry.SetScope("counter", "unknown"))
+		assert.NoError(suite.T(), registry.SetScope("counter", Singleton))
+		assert.Error(suite.T(), registry.SetType("gateway", reflect.TypeOf((*adminHandler)(nil))))
+		assert.Error(suite.T(), registry.SetType("handler", reflect.TypeOf(adminHandler{})))
+		assert.NoError(suite.T(), registry.SetType("handler", reflect.TypeOf((*adminHandler)(nil))))
+		assert.NoError(suite.T(), registry.RemoveBean("obsolete"))
+		assert.Error(suite.T(), registry.RemoveBean("obsolete"))
+		overwritten, err := registry.RegisterBean("added", reflect.TypeOf((*debugHandler)(nil)))
+		assert.False(suite.T(), overwritten)
+		return err
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("counter"), GetInstance("counter"))
+	assert.IsType(suite.T(), &adminHandler{}, GetInstance("handler"))
+	assert.IsType(suite.T(), &debugHandler{}, GetInstance("added"))
+	_, err = GetInstanceSafe("obsolete")
+	assert.Error(suite.T(), err)
+	assert.Error(suite.T(), RegisterBeanDefinitionPostprocessor(func(*BeanDefinitionRegistry) error { return nil }))
+}
+
+func (suite *TestSuite) TestBeanDefinitionPostprocessorRejectedTypeKeepsDefinition() {
+	_, err := RegisterBean("debug", reflect.TypeOf((*debugHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("handler", reflect.TypeOf((*userHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ConfigureBean("handler").Lazy().Primary().DependsOn("debug").Qualifier("user").Err())
+	err = RegisterBeanDefinitionPostprocessor(func(registry *BeanDefinitionRegistry) error {
+		assert.Error(suite.T(), registry.SetType("handler", reflect.TypeOf(adminHandler{})))
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), primaryBeans["handler"])
+	assert.True(suite.T(), lazyBeans["handler"])
+	assert.Equal(suite.T(), []string{"debug"}, beanDependencies["handler"])
+	assert.Equal(suite.T(), "user", beanQualifiers["handler"])
+	assert.True(suite.T(), beanGroups["handler"]["handlers"])
+	assert.IsType(suite.T(), &userHandler{}, GetInstance("handler"))
+}
+
+func (suite *TestSuite) TestBeanDefinitionPostprocessorError() {
+	err := RegisterBeanDefinitionPostprocessor(func(*BeanDefinitionRegistry) error {
+		return errors.New("failure")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "failure")
+}
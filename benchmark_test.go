@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchmarkDependency struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+type benchmarkBean struct {
+	Scope      Scope                `di.scope:"prototype"`
+	dependency *benchmarkDependency `di.inject:""`
+}
+
+type benchmarkBeanWithDependencies struct {
+	Scope       Scope                `di.scope:"prototype"`
+	dependency1 *benchmarkDependency `di.inject:"dependency"`
+	dependency2 *benchmarkDependency `di.inject:"dependency"`
+	dependency3 *benchmarkDependency `di.inject:"dependency"`
+	dependency4 *benchmarkDependency `di.inject:"dependency"`
+	dependency5 *benchmarkDependency `di.inject:"dependency"`
+	singleton   *benchmarkSingleton  `di.inject:""`
+}
+
+type benchmarkSingleton struct{}
+
+type benchmarkRequestBean struct {
+	Scope      Scope                `di.scope:"request"`
+	dependency *benchmarkDependency `di.inject:""`
+}
+
+func initializeBenchmarkContainer(b *testing.B) {
+	b.Helper()
+	Reset()
+	b.Cleanup(Reset)
+	if _, err := RegisterBean("dependency", reflect.TypeOf((*benchmarkDependency)(nil))); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := RegisterBeanInstance("singleton", &benchmarkSingleton{}); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := RegisterBean("bean", reflect.TypeOf((*benchmarkBean)(nil))); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := RegisterBean("beanWithDependencies", reflect.TypeOf((*benchmarkBeanWithDependencies)(nil))); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := RegisterBean("requestBean", reflect.TypeOf((*benchmarkRequestBean)(nil))); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := RegisterBeanFactory("slowBean", Prototype, func(context.Context) (interface{}, error) {
+		time.Sleep(10 * time.Microsecond)
+		return &benchmarkDependency{}, nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+	if err := InitializeContainer(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkSingletonLookup(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = GetInstance("singleton")
+	}
+}
+
+func BenchmarkSingletonLookupParallel(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = GetInstance("singleton")
+		}
+	})
+}
+
+func BenchmarkPrototypeCreation(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = GetInstance("bean")
+	}
+}
+
+func BenchmarkPrototypeCreationWithDependencies(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = GetInstance("beanWithDependencies")
+	}
+}
+
+func BenchmarkPrototypeCreationParallel(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = GetInstance("bean")
+		}
+	})
+}
+
+func BenchmarkSlowFactoryPrototypeCreationParallel(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = GetInstance("slowBean")
+		}
+	})
+}
+
+func BenchmarkMiddleware(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	recorder := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(recorder, request)
+	}
+}
+
+func BenchmarkMiddlewareWithRequestBean(b *testing.B) {
+	initializeBenchmarkContainer(b)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = MustFromContext[*benchmarkRequestBean](r.Context(), "requestBean")
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	recorder := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(recorder, request)
+	}
+}
+
+func (suite *TestSuite) TestPrototypesAreCreatedConcurrently() {
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	overwritten, err := RegisterBeanFactory("bean", Prototype, func(context.Context) (interface{}, error) {
+		barrier.Done()
+		barrier.Wait() // would never return if instances were created one at a time
+		return &benchmarkDependency{}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var created sync.WaitGroup
+	created.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer created.Done()
+			_ = GetInstance("bean")
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		created.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("prototype instances are not created concurrently")
+	}
+}
+
+func (suite *TestSuite) TestSingletonsAreLookedUpConcurrently() {
+	singleton := &benchmarkSingleton{}
+	overwritten, err := RegisterBeanInstance("singleton", singleton)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterAlias("alias", "singleton")
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var lookups sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		lookups.Add(1)
+		go func() {
+			defer lookups.Done()
+			for j := 0; j < 1000; j++ {
+				assert.Same(suite.T(), singleton, GetInstance("singleton"))
+				assert.Same(suite.T(), singleton, GetInstance("alias"))
+			}
+		}()
+	}
+	lookups.Wait()
+	mock := &benchmarkSingleton{}
+	restore := OverrideBeanInstance("singleton", mock)
+	assert.Same(suite.T(), mock, GetInstance("singleton"))
+	restore()
+	assert.Same(suite.T(), singleton, GetInstance("singleton"))
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+)
+
+// Builder is a fluent builder of the container: registrations are collected by its methods and only applied to the
+// container by Build, which initializes it right away. This makes the registration phase explicit, so that it can't be
+// interleaved with lookups of beans.
+type Builder struct {
+	registrations []func() error
+}
+
+// NewBuilder function creates an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Bean method registers bean by type (see RegisterBean).
+func (b *Builder) Bean(beanID string, beanType reflect.Type) *Builder {
+	return b.register(func() error {
+		_, err := RegisterBean(beanID, beanType)
+		return err
+	})
+}
+
+// Instance method registers the pre-created instance of the bean (see RegisterBeanInstance).
+func (b *Builder) Instance(beanID string, beanInstance interface{}) *Builder {
+	return b.register(func() error {
+		_, err := RegisterBeanInstance(beanID, beanInstance)
+		return err
+	})
+}
+
+// Value method registers the value bean (see RegisterBeanValue).
+func (b *Builder) Value(beanID string, beanValue interface{}) *Builder {
+	return b.register(func() error {
+		_, err := RegisterBeanValue(beanID, beanValue)
+		return err
+	})
+}
+
+// Factory method registers the bean factory (see RegisterBeanFactory).
+func (b *Builder) Factory(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, error)) *Builder {
+	return b.register(func() error {
+		_, err := RegisterBeanFactory(beanID, beanScope, beanFactory)
+		return err
+	})
+}
+
+// Postprocessor method registers the postprocessor of beans of the given type (see RegisterBeanPostprocessor).
+func (b *Builder) Postprocessor(beanType reflect.Type, postprocessor func(bean interface{}) error) *Builder {
+	return b.register(func() error {
+		return RegisterBeanPostprocessor(beanType, postprocessor)
+	})
+}
+
+func (b *Builder) register(registration func() error) *Builder {
+	b.registrations = append(b.registrations, registration)
+	return b
+}
+
+// Build method applies the collected registrations to the container and initializes it. If any of the registrations
+// or the initialization fails, or the context is canceled, the container is restored to the state it was in before the
+// call. It fails if the container is already initialized.
+func (b *Builder) Build(ctx context.Context) (*Container, error) {
+	if atomic.LoadInt32(&containerInitialized) == 1 {
+		return nil, errors.New("container is already initialized: can't build it")
+	}
+	snapshot := Snapshot()
+	if err := b.build(ctx); err != nil {
+		Restore(snapshot)
+		return nil, err
+	}
+	return container, nil
+}
+
+func (b *Builder) build(ctx context.Context) error {
+	for _, registration := range b.registrations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := registration(); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return InitializeContainer()
+}
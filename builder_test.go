@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type builtService struct {
+	Dependency *singletonDependency `di.inject:"dependency"`
+	Greeter    greeter              `di.inject:"greeter"`
+	Port       int                  `di.inject:"port"`
+	processed  bool
+}
+
+func (suite *TestSuite) TestBuilder() {
+	c, err := NewBuilder().
+		Bean("service", reflect.TypeOf((*builtService)(nil))).
+		Instance("dependency", &singletonDependency{}).
+		Value("port", 8080).
+		Factory("greeter", Singleton, func(context.Context) (interface{}, error) {
+			return &englishGreeter{}, nil
+		}).
+		Postprocessor(reflect.TypeOf((*builtService)(nil)), func(bean interface{}) error {
+			bean.(*builtService).processed = true
+			return nil
+		}).
+		Build(context.Background())
+	assert.NoError(suite.T(), err)
+	service := c.GetInstance("service").(*builtService)
+	assert.Same(suite.T(), GetInstance("dependency"), service.Dependency)
+	assert.Same(suite.T(), GetInstance("greeter"), service.Greeter)
+	assert.Equal(suite.T(), 8080, service.Port)
+	assert.True(suite.T(), service.processed)
+}
+
+func (suite *TestSuite) TestBuilderRestoresContainerOnFailure() {
+	_, err := RegisterBean("existing", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = NewBuilder().
+		Instance("dependency", &singletonDependency{}).
+		Factory("failing", Singleton, func(context.Context) (interface{}, error) {
+			return nil, errors.New("factory failed")
+		}).
+		Build(context.Background())
+	assert.EqualError(suite.T(), err, "factory failed")
+	assert.Equal(suite.T(), map[string]reflect.Type{"existing": reflect.TypeOf((*singletonDependency)(nil))},
+		GetBeanTypes())
+	_, err = NewBuilder().
+		Value("invalid", []string{}).
+		Bean("dependency", reflect.TypeOf((*singletonDependency)(nil))).
+		Build(context.Background())
+	assert.EqualError(suite.T(), err, "bean value must be a struct or a primitive: invalid")
+	assert.Len(suite.T(), GetBeanTypes(), 1)
+}
+
+func (suite *TestSuite) TestBuilderWithCanceledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewBuilder().Bean("dependency", reflect.TypeOf((*singletonDependency)(nil))).Build(ctx)
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+	assert.Empty(suite.T(), GetBeanTypes())
+}
+
+func (suite *TestSuite) TestBuilderFailsIfContainerIsInitialized() {
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = NewBuilder().Build(context.Background())
+	assert.EqualError(suite.T(), err, "container is already initialized: can't build it")
+}
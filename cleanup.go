@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// beanCleanup is a cleanup function returned by the bean factory along with the bean instance.
+type beanCleanup struct {
+	beanID       string
+	beanInstance interface{}
+	cleanup      func()
+}
+
+// containerCleanups keep cleanup functions to be called on Close, in creation order.
+var containerCleanups []beanCleanup
+var containerCleanupsLock sync.Mutex
+
+// RegisterBeanFactoryWithCleanup function registers bean, provided the bean factory that returns the cleanup function
+// along with the bean instance, e.g. to release resources that aren't closed by the bean itself (listeners, temporary
+// directories, etc.). The cleanup function is called when the bean goes out of scope: on Close for Singleton beans, at
+// the end of the scope for Request-scoped beans and Prototype beans created within the scope (see Middleware and
+// BeginScope). Cleanup functions of all other beans are called on Close. The cleanup function may be nil, it's ignored
+// if the factory returns the error. Return value of `overwritten` is set to `true` if the bean with the same `beanID`
+// has been registered already.
+func RegisterBeanFactoryWithCleanup(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, func(), error)) (overwritten bool, err error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean factory")
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{})
+	if err != nil {
+		return false, err
+	}
+	unregisterBean(beanID)
+	scopes[beanID] = beanScope
+	beanFactories[beanID] = func(ctx context.Context) (interface{}, error) {
+		beanInstance, cleanup, err := beanFactory(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if cleanup == nil {
+			return beanInstance, nil
+		}
+		if err := checkFactoryResult(beanID, beanInstance); err != nil {
+			cleanup()
+			return nil, err
+		}
+		registerCleanup(ctx, beanCleanup{beanID: beanID, beanInstance: beanInstance, cleanup: cleanup})
+		return beanInstance, nil
+	}
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
+}
+
+// registerCleanup function registers the cleanup function in the scope the bean is created in, or in the container.
+func registerCleanup(ctx context.Context, cleanup beanCleanup) {
+	if beanScope := scopes[cleanup.beanID]; beanScope == Request || beanScope == Prototype {
+		if requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext); ok {
+			requestContext.scope.addCleanup(cleanup)
+			return
+		}
+	}
+	containerCleanupsLock.Lock()
+	defer containerCleanupsLock.Unlock()
+	containerCleanups = append(containerCleanups, cleanup)
+}
+
+// runContainerCleanups function calls cleanup functions registered in the container in reverse creation order.
+func runContainerCleanups() {
+	containerCleanupsLock.Lock()
+	cleanups := containerCleanups
+	containerCleanups = nil
+	containerCleanupsLock.Unlock()
+	runCleanups(cleanups)
+}
+
+// takeContainerCleanups function removes cleanup functions of the bean instance from the ones registered in the
+// container and returns them.
+func takeContainerCleanups(beanInstance interface{}) []beanCleanup {
+	containerCleanupsLock.Lock()
+	defer containerCleanupsLock.Unlock()
+	var taken []beanCleanup
+	remaining := containerCleanups[:0]
+	for _, cleanup := range containerCleanups {
+		if cleanup.beanInstance == beanInstance {
+			taken = append(taken, cleanup)
+		} else {
+			remaining = append(remaining, cleanup)
+		}
+	}
+	containerCleanups = remaining
+	return taken
+}
+
+// runCleanups function calls cleanup functions in reverse order.
+func runCleanups(cleanups []beanCleanup) {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i].run()
+	}
+}
+
+func (bc beanCleanup) run() {
+	bc.cleanup()
+	emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: bc.beanID, Bean: bc.beanInstance})
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cleanupBean struct {
+	name string
+}
+
+// cleanupRecorder records calls of cleanup functions.
+type cleanupRecorder struct {
+	lock  sync.Mutex
+	calls []string
+}
+
+func (cr *cleanupRecorder) factory(name string) func(context.Context) (interface{}, func(), error) {
+	return func(context.Context) (interface{}, func(), error) {
+		return &cleanupBean{name: name}, func() {
+			cr.lock.Lock()
+			defer cr.lock.Unlock()
+			cr.calls = append(cr.calls, name)
+		}, nil
+	}
+}
+
+func (cr *cleanupRecorder) called() []string {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	return append([]string(nil), cr.calls...)
+}
+
+func (suite *TestSuite) TestSingletonCleanupsAreCalledOnClose() {
+	recorder := &cleanupRecorder{}
+	overwritten, err := RegisterBeanFactoryWithCleanup("first", Singleton, recorder.factory("first"))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("dependent", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("first")
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactoryWithCleanup("second", Singleton, recorder.factory("second"))
+	assert.NoError(suite.T(), err)
+	var closedBeans []string
+	err = RegisterContainerHook(func(event ContainerEvent) {
+		if event.Type == BeanClosed {
+			closedBeans = append(closedBeans, event.BeanID)
+		}
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), recorder.called())
+	Close()
+	assert.ElementsMatch(suite.T(), []string{"first", "second"}, recorder.called())
+	assert.ElementsMatch(suite.T(), []string{"first", "second"}, closedBeans)
+}
+
+func (suite *TestSuite) TestCleanupsAreNotCalledOnReset() {
+	recorder := &cleanupRecorder{}
+	_, err := RegisterBeanFactoryWithCleanup("bean", Singleton, recorder.factory("bean"))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	Reset()
+	Close()
+	assert.Empty(suite.T(), recorder.called())
+}
+
+func (suite *TestSuite) TestRequestBeanCleanupIsCalledAfterRequest() {
+	recorder := &cleanupRecorder{}
+	_, err := RegisterBeanFactoryWithCleanup("requestBean", Request, recorder.factory("requestBean"))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beanInstance, ok := FromContext[*cleanupBean](r.Context(), "requestBean")
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), "requestBean", beanInstance.name)
+		assert.Empty(suite.T(), recorder.called())
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(suite.T(), []string{"requestBean"}, recorder.called())
+	Close()
+	assert.Equal(suite.T(), []string{"requestBean"}, recorder.called())
+}
+
+func (suite *TestSuite) TestPrototypeCleanupIsCalledAtScopeEnd() {
+	recorder := &cleanupRecorder{}
+	_, err := RegisterBeanFactoryWithCleanup("prototype", Prototype, func(ctx context.Context) (interface{}, func(), error) {
+		name := "outside"
+		if _, ok := ScopeFromContext(ctx); ok {
+			name = "inside"
+		}
+		return recorder.factory(name)(ctx)
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("prototype")
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	_, err = GetInstanceSafeCtx(ctx, "prototype")
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafeCtx(ctx, "prototype")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), recorder.called())
+	done()
+	assert.Equal(suite.T(), []string{"inside", "inside"}, recorder.called())
+	Close()
+	assert.Equal(suite.T(), []string{"inside", "inside", "outside"}, recorder.called())
+}
+
+func (suite *TestSuite) TestCleanupIsCalledForRejectedNilBean() {
+	cleanedUp := false
+	_, err := RegisterBeanFactoryWithCleanup("bean", Singleton, func(context.Context) (interface{}, func(), error) {
+		return nil, func() {
+			cleanedUp = true
+		}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean factory returned nil: bean")
+	assert.True(suite.T(), cleanedUp)
+}
+
+func (suite *TestSuite) TestFactoryWithCleanupReturningError() {
+	_, err := RegisterBeanFactoryWithCleanup("bean", Singleton, func(context.Context) (interface{}, func(), error) {
+		return nil, func() {
+			suite.T().Fatal("cleanup must not be called")
+		}, errors.New("cannot create the bean")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "cannot create the bean")
+	Close()
+}
@@ -0,0 +1,520 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const diImportPath = "github.com/goioc/di"
+
+// bean describes the wiring of a single bean type.
+type bean struct {
+	TypeName string
+	BeanID   string
+	Scope    string
+	// Injector is set if the reflection-free injector should be generated.
+	Injector bool
+	// Reason explains why the injector can't be generated.
+	Reason string
+	Fields []dependencyField
+}
+
+// dependencyField is a field injected by the generated injector.
+type dependencyField struct {
+	Name   string
+	Type   string
+	BeanID string
+}
+
+// proxy describes the interceptor proxy of an interface (see di.RegisterProxy).
+type proxy struct {
+	InterfaceName string
+	TypeName      string
+	Methods       []proxyMethod
+}
+
+// proxyMethod is a method of the proxied interface.
+type proxyMethod struct {
+	Name string
+	// Params is the parameter list of the method, e.g. `arg0 string, arg1 ...int`.
+	Params string
+	// Args are the arguments passed to interceptors, e.g. `arg0, arg1`.
+	Args string
+	// CallArgs are the arguments passed to the method of the target, e.g. `arg0, arg1...`.
+	CallArgs string
+	// Result is the type of the non-error result of the method, if any.
+	Result string
+	// Error is set if the method returns an error (as the last result).
+	Error bool
+}
+
+// ConstructorName method returns the name of the function creating the proxy.
+func (p proxy) ConstructorName() string {
+	name := []rune(p.TypeName)
+	name[0] = unicode.ToUpper(name[0])
+	return "new" + string(name)
+}
+
+// Results method returns the result list of the method, as declared in its signature.
+func (m proxyMethod) Results() string {
+	switch {
+	case m.Result != "" && m.Error:
+		return "(" + m.Result + ", error)"
+	case m.Error:
+		return "error"
+	}
+	return m.Result
+}
+
+// AccessorName method returns the name of the typed accessor of the bean, exported only for exported types.
+func (b bean) AccessorName() string {
+	if b.Scope == "request" {
+		return b.TypeName + "FromContext"
+	}
+	name := []rune(b.TypeName)
+	if unicode.IsUpper(name[0]) {
+		return "Get" + b.TypeName
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return "get" + string(name)
+}
+
+var wiringTemplate = template.Must(template.New("wiring").Parse(`// Code generated by dicodegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .StandardImports}}
+	{{.}}
+{{- end}}
+{{range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// RegisterBeans function registers beans {{- if .Proxies}} and proxies{{end}} of the package in the container.
+func RegisterBeans() error {
+{{- range .Beans}}
+	if _, err := di.RegisterBean({{printf "%q" .BeanID}}, reflect.TypeOf((*{{.TypeName}})(nil))); err != nil {
+		return err
+	}
+{{- end}}
+{{- range .Proxies}}
+	if err := di.RegisterProxy({{.ConstructorName}}); err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+}
+{{range .Beans}}
+{{- if eq .Scope "request"}}
+// {{.AccessorName}} function returns the {{printf "%q" .BeanID}} bean from the request context.
+func {{.AccessorName}}(ctx context.Context) *{{.TypeName}} {
+	return di.MustFromContext[*{{.TypeName}}](ctx, {{printf "%q" .BeanID}})
+}
+{{- else if eq .Scope "singleton"}}
+// {{.AccessorName}} function returns the {{printf "%q" .BeanID}} bean.
+func {{.AccessorName}}() *{{.TypeName}} {
+	return di.GetInstance({{printf "%q" .BeanID}}).(*{{.TypeName}})
+}
+{{- else}}
+// {{.AccessorName}} function returns the {{printf "%q" .BeanID}} bean, the context is propagated to the created instances.
+func {{.AccessorName}}(ctx context.Context) *{{.TypeName}} {
+	return di.GetInstanceCtx(ctx, {{printf "%q" .BeanID}}).(*{{.TypeName}})
+}
+{{- end}}
+{{if .Injector}}
+// InjectDependencies method injects dependencies of the {{printf "%q" .BeanID}} bean without reflection.
+func (bean *{{.TypeName}}) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+{{- if .Fields}}
+	var dependency interface{}
+	var err error
+{{- end}}
+{{- range .Fields}}
+	dependency, err = resolve({{printf "%q" .BeanID}})
+	if err != nil {
+		return err
+	}
+	bean.{{.Name}} = dependency.({{.Type}})
+{{- end}}
+	return nil
+}
+{{else if .Reason}}
+// {{.TypeName}} is injected using reflection: {{.Reason}}.
+{{end}}
+{{- end}}
+{{- range $proxy := .Proxies}}
+// {{.TypeName}} is a proxy of {{.InterfaceName}}, calling the interceptors of the wrapped bean (see di.RegisterInterceptor).
+type {{.TypeName}} struct {
+	target    {{.InterfaceName}}
+	intercept di.InterceptFunc
+}
+
+// {{.ConstructorName}} function creates the proxy of {{.InterfaceName}}.
+func {{.ConstructorName}}(target {{.InterfaceName}}, intercept di.InterceptFunc) {{.InterfaceName}} {
+	return &{{.TypeName}}{target: target, intercept: intercept}
+}
+{{range .Methods}}
+// {{.Name}} method calls the interceptors of the {{.Name}} method.
+func (proxy *{{$proxy.TypeName}}) {{.Name}}({{.Params}}) {{.Results}} {
+{{- if .Result}}
+	result, err := proxy.intercept({{printf "%q" .Name}}, []interface{}{ {{- .Args -}} }, func() (interface{}, error) {
+{{- if .Error}}
+		return proxy.target.{{.Name}}({{.CallArgs}})
+{{- else}}
+		return proxy.target.{{.Name}}({{.CallArgs}}), nil
+{{- end}}
+	})
+{{- else}}
+	_, err := proxy.intercept({{printf "%q" .Name}}, []interface{}{ {{- .Args -}} }, func() (interface{}, error) {
+{{- if .Error}}
+		return nil, proxy.target.{{.Name}}({{.CallArgs}})
+{{- else}}
+		proxy.target.{{.Name}}({{.CallArgs}})
+		return nil, nil
+{{- end}}
+	})
+{{- end}}
+{{- if not .Error}}
+	if err != nil {
+		panic(err)
+	}
+{{- end}}
+{{- if .Result}}
+	value, _ := result.({{.Result}})
+{{- if .Error}}
+	return value, err
+{{- else}}
+	return value
+{{- end}}
+{{- else if .Error}}
+	return err
+{{- end}}
+}
+{{end}}
+{{- end}}`))
+
+// generate function generates the wiring code of the beans (and the proxies of the interfaces) declared in the package
+// located in the directory.
+func generate(dir string, outputName string, specs []beanSpec, proxies []string, inject bool) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var packageName string
+	typeSpecs := make(map[string]*ast.TypeSpec)
+	typeFiles := make(map[string]*ast.File)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == outputName {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		packageName = file.Name.Name
+		for _, declaration := range file.Decls {
+			genDecl, ok := declaration.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				typeSpecs[typeSpec.Name.Name] = typeSpec
+				typeFiles[typeSpec.Name.Name] = file
+			}
+		}
+	}
+	if packageName == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+	imports := map[string]bool{strconv.Quote(diImportPath): true}
+	if len(specs) > 0 {
+		imports[strconv.Quote("reflect")] = true
+	}
+	var beans []bean
+	for _, spec := range specs {
+		typeSpec, ok := typeSpecs[spec.typeName]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not found in %s", spec.typeName, dir)
+		}
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok || typeSpec.TypeParams != nil {
+			return nil, fmt.Errorf("type %s is not a struct", spec.typeName)
+		}
+		b := bean{TypeName: spec.typeName, BeanID: spec.beanID, Scope: "singleton"}
+		fieldImports, err := analyzeFields(&b, structType, typeFiles[spec.typeName])
+		if err != nil {
+			return nil, err
+		}
+		if b.Scope != "singleton" {
+			imports[strconv.Quote("context")] = true
+		}
+		if inject && b.Reason == "" {
+			b.Injector = true
+			for _, fieldImport := range fieldImports {
+				imports[fieldImport] = true
+			}
+		}
+		if !inject {
+			b.Reason = ""
+		}
+		beans = append(beans, b)
+	}
+	var proxyTypes []proxy
+	for _, interfaceName := range proxies {
+		typeSpec, ok := typeSpecs[interfaceName]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not found in %s", interfaceName, dir)
+		}
+		interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
+		if !ok || typeSpec.TypeParams != nil {
+			return nil, fmt.Errorf("type %s is not an interface", interfaceName)
+		}
+		p, proxyImports, err := analyzeMethods(interfaceName, interfaceType, typeFiles[interfaceName])
+		if err != nil {
+			return nil, err
+		}
+		for _, proxyImport := range proxyImports {
+			imports[proxyImport] = true
+		}
+		proxyTypes = append(proxyTypes, p)
+	}
+	var standardImports, otherImports []string
+	for spec := range imports {
+		path := spec[strings.Index(spec, `"`)+1:]
+		if strings.Contains(path[:strings.IndexAny(path, `/"`)], ".") {
+			otherImports = append(otherImports, spec)
+		} else {
+			standardImports = append(standardImports, spec)
+		}
+	}
+	sort.Strings(standardImports)
+	sort.Strings(otherImports)
+	var source bytes.Buffer
+	err = wiringTemplate.Execute(&source, struct {
+		Package         string
+		StandardImports []string
+		Imports         []string
+		Beans           []bean
+		Proxies         []proxy
+	}{packageName, standardImports, otherImports, beans, proxyTypes})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(source.Bytes())
+}
+
+// analyzeFields function reads `di` tags of the struct, filling the scope and the injected fields of the bean. It
+// returns import specs required by the types of the injected fields.
+func analyzeFields(b *bean, structType *ast.StructType, file *ast.File) ([]string, error) {
+	var fieldImports []string
+	for _, field := range structType.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		unquotedTag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tag := reflect.StructTag(unquotedTag)
+		if scope, ok := tag.Lookup("di.scope"); ok {
+			b.Scope = scope
+		}
+		beanToInject, injected := tag.Lookup("di.inject")
+		_, valueInjected := tag.Lookup("di.value")
+		_, groupInjected := tag.Lookup("di.inject.group")
+		if !injected && !valueInjected && !groupInjected {
+			continue
+		}
+		if b.Reason != "" {
+			continue
+		}
+		if len(field.Names) != 1 {
+			b.Reason = "embedded fields are not supported"
+			continue
+		}
+		name := field.Names[0].Name
+		switch {
+		case valueInjected:
+			b.Reason = "field " + name + " is injected with a value"
+			continue
+		case groupInjected:
+			b.Reason = "field " + name + " is injected with a group"
+			continue
+		case beanToInject == "":
+			b.Reason = "field " + name + " is injected by type"
+			continue
+		}
+		_, optional := tag.Lookup("di.optional")
+		_, onMissing := tag.Lookup("di.onMissing")
+		if optional || onMissing {
+			b.Reason = "field " + name + " is optional"
+			continue
+		}
+		typeImports, ok := resolveTypeImports(field.Type, file)
+		if !ok {
+			b.Reason = "field " + name + " is a collection, a provider or a proxy"
+			continue
+		}
+		fieldType, err := formatNode(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		fieldImports = append(fieldImports, typeImports...)
+		b.Fields = append(b.Fields, dependencyField{Name: name, Type: fieldType, BeanID: beanToInject})
+	}
+	return fieldImports, nil
+}
+
+// analyzeMethods function reads methods of the interface, returning its proxy and the import specs required by the
+// types of parameters and results of the methods.
+func analyzeMethods(interfaceName string, interfaceType *ast.InterfaceType, file *ast.File) (proxy, []string, error) {
+	name := []rune(interfaceName)
+	name[0] = unicode.ToLower(name[0])
+	p := proxy{InterfaceName: interfaceName, TypeName: string(name) + "Proxy"}
+	var proxyImports []string
+	for _, method := range interfaceType.Methods.List {
+		funcType, ok := method.Type.(*ast.FuncType)
+		if !ok || len(method.Names) != 1 {
+			return proxy{}, nil, fmt.Errorf("interface %s embeds other interfaces: proxies can't be generated",
+				interfaceName)
+		}
+		m := proxyMethod{Name: method.Names[0].Name}
+		var params, args, callArgs []string
+		for _, param := range funcType.Params.List {
+			paramType, err := formatNode(param.Type)
+			if err != nil {
+				return proxy{}, nil, err
+			}
+			for i := 0; i < len(param.Names) || (i == 0 && len(param.Names) == 0); i++ {
+				arg := "arg" + strconv.Itoa(len(args))
+				params = append(params, arg+" "+paramType)
+				args = append(args, arg)
+				if _, variadic := param.Type.(*ast.Ellipsis); variadic {
+					arg += "..."
+				}
+				callArgs = append(callArgs, arg)
+			}
+			proxyImports = append(proxyImports, collectTypeImports(param.Type, file)...)
+		}
+		m.Params, m.Args, m.CallArgs = strings.Join(params, ", "), strings.Join(args, ", "), strings.Join(callArgs, ", ")
+		var results []string
+		if funcType.Results != nil {
+			for _, result := range funcType.Results.List {
+				resultType, err := formatNode(result.Type)
+				if err != nil {
+					return proxy{}, nil, err
+				}
+				for i := 0; i < len(result.Names) || (i == 0 && len(result.Names) == 0); i++ {
+					results = append(results, resultType)
+				}
+				proxyImports = append(proxyImports, collectTypeImports(result.Type, file)...)
+			}
+		}
+		if len(results) > 0 && results[len(results)-1] == "error" {
+			m.Error, results = true, results[:len(results)-1]
+		}
+		if len(results) > 1 {
+			return proxy{}, nil, fmt.Errorf("method %s of interface %s has more than one non-error result: proxies "+
+				"can't be generated", m.Name, interfaceName)
+		}
+		if len(results) == 1 {
+			m.Result = results[0]
+		}
+		p.Methods = append(p.Methods, m)
+	}
+	return p, proxyImports, nil
+}
+
+func formatNode(node ast.Node) (string, error) {
+	var source bytes.Buffer
+	if err := format.Node(&source, token.NewFileSet(), node); err != nil {
+		return "", err
+	}
+	return source.String(), nil
+}
+
+// collectTypeImports function returns the import specs required by the type expression.
+func collectTypeImports(typeExpression ast.Expr, file *ast.File) []string {
+	var typeImports []string
+	ast.Inspect(typeExpression, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if packageIdent, ok := selector.X.(*ast.Ident); ok {
+				if importSpec, ok := findImport(packageIdent.Name, file); ok {
+					typeImports = append(typeImports, importSpec)
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return typeImports
+}
+
+// resolveTypeImports function checks that the type of the field is a (pointer to a) named type and returns the import
+// specs it requires. Collections, functions and generic types (e.g. di.Provider) are not supported.
+func resolveTypeImports(fieldType ast.Expr, file *ast.File) ([]string, bool) {
+	if star, ok := fieldType.(*ast.StarExpr); ok {
+		fieldType = star.X
+	}
+	switch expression := fieldType.(type) {
+	case *ast.Ident:
+		return nil, true
+	case *ast.SelectorExpr:
+		packageIdent, ok := expression.X.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		if importSpec, ok := findImport(packageIdent.Name, file); ok {
+			return []string{importSpec}, true
+		}
+	}
+	return nil, false
+}
+
+// findImport function returns the import spec of the package with the given name (the di package is always imported).
+func findImport(packageName string, file *ast.File) (string, bool) {
+	for _, importSpec := range file.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil || path == diImportPath {
+			continue
+		}
+		if importSpec.Name != nil {
+			if importSpec.Name.Name == packageName {
+				return importSpec.Name.Name + " " + importSpec.Path.Value, true
+			}
+			continue
+		}
+		if path[strings.LastIndex(path, "/")+1:] == packageName {
+			return importSpec.Path.Value, true
+		}
+	}
+	return "", false
+}
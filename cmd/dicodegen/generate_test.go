@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goioc/di"
+	"github.com/goioc/di/cmd/dicodegen/testdata/beans"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSpecs = []beanSpec{
+	{typeName: "UserService", beanID: "userService"},
+	{typeName: "userRepository", beanID: "repository"},
+	{typeName: "Session", beanID: "session"},
+	{typeName: "RequestData", beanID: "requestData"},
+	{typeName: "Notifications", beanID: "notifications"},
+}
+
+var testProxies = []string{"Repository", "Notifier"}
+
+func TestParseBeanSpecs(t *testing.T) {
+	specs, err := parseBeanSpecs("UserService, userRepository=repository,Session,RequestData,Notifications")
+	assert.NoError(t, err)
+	assert.Equal(t, testSpecs, specs)
+	_, err = parseBeanSpecs("UserService=")
+	assert.EqualError(t, err, `invalid bean type: "UserService="`)
+}
+
+func TestGenerateMatchesCommittedCode(t *testing.T) {
+	source, err := generate(filepath.Join("testdata", "beans"), "wire_gen.go", testSpecs, testProxies, true)
+	assert.NoError(t, err)
+	expected, err := os.ReadFile(filepath.Join("testdata", "beans", "wire_gen.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(source))
+}
+
+func TestGenerateWithoutInjectors(t *testing.T) {
+	source, err := generate(filepath.Join("testdata", "beans"), "wire_gen.go", testSpecs, nil, false)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(source), "InjectDependencies")
+	assert.NotContains(t, string(source), "net/http")
+	assert.NotContains(t, string(source), "injected using reflection")
+	assert.Contains(t, string(source), "func GetUserService() *UserService")
+}
+
+func TestGenerateFailsOnUnknownType(t *testing.T) {
+	_, err := generate(filepath.Join("testdata", "beans"), "wire_gen.go", []beanSpec{{typeName: "Unknown", beanID: "unknown"}}, nil, true)
+	assert.EqualError(t, err, "type Unknown is not found in "+filepath.Join("testdata", "beans"))
+	_, err = generate(filepath.Join("testdata", "beans"), "wire_gen.go", []beanSpec{{typeName: "Repository", beanID: "repository"}}, nil, true)
+	assert.EqualError(t, err, "type Repository is not a struct")
+}
+
+func TestGeneratedWiring(t *testing.T) {
+	defer di.Reset()
+	client := &http.Client{}
+	_, err := di.RegisterBeanInstance("httpClient", client)
+	assert.NoError(t, err)
+	assert.NoError(t, beans.RegisterBeans())
+	assert.NoError(t, di.InitializeContainer())
+	service := beans.GetUserService()
+	assert.Equal(t, "user 42", service.Repository().FindUser("42"))
+	assert.Same(t, client, service.Client())
+	assert.Equal(t, "en", beans.GetSession(context.Background()).Locale)
+	ctx, done := di.BeginScope(context.Background())
+	defer done()
+	assert.Same(t, service, beans.RequestDataFromContext(ctx).Service())
+}
+
+func TestGeneratedProxies(t *testing.T) {
+	defer di.Reset()
+	_, err := di.RegisterBeanInstance("httpClient", &http.Client{})
+	assert.NoError(t, err)
+	assert.NoError(t, beans.RegisterBeans())
+	var calls []string
+	err = di.RegisterInterceptor(di.MatchBeans("repository", "notifications"), func(inv di.Invocation) (interface{}, error) {
+		calls = append(calls, inv.Method)
+		if inv.Method == "Reset" {
+			return nil, errors.New("reset is forbidden")
+		}
+		result, err := inv.Proceed()
+		if user, ok := result.(string); ok {
+			return strings.ToUpper(user), err
+		}
+		return result, err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, di.InitializeContainer())
+	assert.Equal(t, "USER 42", beans.GetUserService().Repository().FindUser("42"))
+	notifier := di.GetInstance("notifications").(beans.Notifier)
+	assert.NoError(t, notifier.Notify(context.Background(), "admin", "support"))
+	assert.EqualError(t, notifier.Notify(context.Background()), "no recipients")
+	request, err := notifier.Request("42")
+	assert.NoError(t, err)
+	assert.Equal(t, "/notifications/42", request.URL.Path)
+	assert.Equal(t, 2, notifier.Sent())
+	assert.PanicsWithError(t, "reset is forbidden", notifier.Reset)
+	assert.Equal(t, []string{"FindUser", "Notify", "Notify", "Request", "Sent", "Reset"}, calls)
+}
+
+func TestGenerateProxiesOnly(t *testing.T) {
+	source, err := generate(filepath.Join("testdata", "beans"), "wire_gen.go", nil, []string{"Repository"}, false)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(source), "reflect")
+	assert.Contains(t, string(source), "func newRepositoryProxy(target Repository, intercept di.InterceptFunc) Repository")
+	_, err = generate(filepath.Join("testdata", "beans"), "wire_gen.go", nil, []string{"UserService"}, false)
+	assert.EqualError(t, err, "type UserService is not an interface")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Command dicodegen generates static wiring code for beans of the github.com/goioc/di container. It reads `di` tags of
+// the selected struct types of a package and emits a file (`wire_gen.go` by default) with explicit registration calls
+// and typed accessors. With `-inject`, it also generates reflection-free `InjectDependencies` methods (see
+// di.DependencyInjector) for the beans whose dependencies can be resolved statically. With `-proxies`, it generates
+// proxies of the listed interfaces, so that beans implementing them can be intercepted (see di.RegisterInterceptor).
+//
+// Usage:
+//
+//	//go:generate go run github.com/goioc/di/cmd/dicodegen -types UserService,userRepository=repository -inject
+//	//go:generate go run github.com/goioc/di/cmd/dicodegen -proxies Repository -output proxies_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package containing the beans")
+	types := flag.String("types", "", "comma-separated list of bean types: `Type` or `Type=beanID`")
+	output := flag.String("output", "wire_gen.go", "name of the generated file (relative to -dir)")
+	inject := flag.Bool("inject", false, "generate reflection-free dependency injectors")
+	proxies := flag.String("proxies", "", "comma-separated list of interfaces to generate interceptor proxies for")
+	flag.Parse()
+	if *types == "" && *proxies == "" {
+		fmt.Fprintln(os.Stderr, "dicodegen: -types or -proxies is mandatory")
+		flag.Usage()
+		os.Exit(2)
+	}
+	specs, err := parseBeanSpecs(*types)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dicodegen:", err)
+		os.Exit(2)
+	}
+	source, err := generate(*dir, filepath.Base(*output), specs, parseProxies(*proxies), *inject)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dicodegen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *output), source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "dicodegen:", err)
+		os.Exit(1)
+	}
+}
+
+// beanSpec is a bean type selected for the generation.
+type beanSpec struct {
+	typeName string
+	beanID   string
+}
+
+func parseBeanSpecs(types string) ([]beanSpec, error) {
+	var specs []beanSpec
+	for _, spec := range strings.Split(types, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		typeName, beanID, found := strings.Cut(spec, "=")
+		if !found {
+			beanID = defaultBeanID(typeName)
+		}
+		if typeName == "" || beanID == "" {
+			return nil, fmt.Errorf("invalid bean type: %q", spec)
+		}
+		specs = append(specs, beanSpec{typeName: typeName, beanID: beanID})
+	}
+	return specs, nil
+}
+
+func parseProxies(proxies string) []string {
+	var interfaceNames []string
+	for _, interfaceName := range strings.Split(proxies, ",") {
+		if interfaceName = strings.TrimSpace(interfaceName); interfaceName != "" {
+			interfaceNames = append(interfaceNames, interfaceName)
+		}
+	}
+	return interfaceNames
+}
+
+// defaultBeanID function derives the bean ID from the type name, e.g. `UserService` becomes `userService`.
+func defaultBeanID(typeName string) string {
+	if typeName == "" {
+		return ""
+	}
+	return strings.ToLower(typeName[:1]) + typeName[1:]
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package beans is used to test the code generation.
+package beans
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/goioc/di"
+)
+
+// UserService is a singleton wired by the generated injector.
+type UserService struct {
+	repository Repository   `di.inject:"repository"`
+	client     *http.Client `di.inject:"httpClient"`
+}
+
+// Repository is an interface of the repository.
+type Repository interface {
+	FindUser(id string) string
+}
+
+type userRepository struct{}
+
+func (*userRepository) FindUser(id string) string {
+	return "user " + id
+}
+
+// Notifier is an interface of the notifier, its proxy is generated.
+type Notifier interface {
+	Notify(ctx context.Context, recipients ...string) error
+	Request(id string) (*http.Request, error)
+	Sent() int
+	Reset()
+}
+
+// Notifications is a notifier counting notifications.
+type Notifications struct {
+	sent int
+}
+
+// Notify method counts notifications.
+func (n *Notifications) Notify(_ context.Context, recipients ...string) error {
+	if len(recipients) == 0 {
+		return errors.New("no recipients")
+	}
+	n.sent += len(recipients)
+	return nil
+}
+
+// Request method creates the request of the notification.
+func (n *Notifications) Request(id string) (*http.Request, error) {
+	return http.NewRequest(http.MethodPost, "/notifications/"+id, nil)
+}
+
+// Sent method returns the number of sent notifications.
+func (n *Notifications) Sent() int {
+	return n.sent
+}
+
+// Reset method resets the counter of sent notifications.
+func (n *Notifications) Reset() {
+	n.sent = 0
+}
+
+// Session is a prototype that can't be wired by the generated injector.
+type Session struct {
+	Scope  Scope  `di.scope:"prototype"`
+	Locale string `di.value:"${locale:en}"`
+}
+
+// Scope is an alias of di.Scope, so that `di.scope` tags can be declared.
+type Scope = di.Scope
+
+// RequestData is a request-scoped bean.
+type RequestData struct {
+	Scope   Scope        `di.scope:"request"`
+	service *UserService `di.inject:"userService"`
+	ctx     context.Context
+}
+
+// SetContext method stores the request context.
+func (rd *RequestData) SetContext(ctx context.Context) {
+	rd.ctx = ctx
+}
+
+// Service method returns the injected service.
+func (rd *RequestData) Service() *UserService {
+	return rd.service
+}
+
+// Repository method returns the injected repository.
+func (us *UserService) Repository() Repository {
+	return us.repository
+}
+
+// Client method returns the injected client.
+func (us *UserService) Client() *http.Client {
+	return us.client
+}
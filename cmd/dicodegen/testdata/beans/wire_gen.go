@@ -0,0 +1,177 @@
+// Code generated by dicodegen. DO NOT EDIT.
+
+package beans
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/goioc/di"
+)
+
+// RegisterBeans function registers beans and proxies of the package in the container.
+func RegisterBeans() error {
+	if _, err := di.RegisterBean("userService", reflect.TypeOf((*UserService)(nil))); err != nil {
+		return err
+	}
+	if _, err := di.RegisterBean("repository", reflect.TypeOf((*userRepository)(nil))); err != nil {
+		return err
+	}
+	if _, err := di.RegisterBean("session", reflect.TypeOf((*Session)(nil))); err != nil {
+		return err
+	}
+	if _, err := di.RegisterBean("requestData", reflect.TypeOf((*RequestData)(nil))); err != nil {
+		return err
+	}
+	if _, err := di.RegisterBean("notifications", reflect.TypeOf((*Notifications)(nil))); err != nil {
+		return err
+	}
+	if err := di.RegisterProxy(newRepositoryProxy); err != nil {
+		return err
+	}
+	if err := di.RegisterProxy(newNotifierProxy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUserService function returns the "userService" bean.
+func GetUserService() *UserService {
+	return di.GetInstance("userService").(*UserService)
+}
+
+// InjectDependencies method injects dependencies of the "userService" bean without reflection.
+func (bean *UserService) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+	var dependency interface{}
+	var err error
+	dependency, err = resolve("repository")
+	if err != nil {
+		return err
+	}
+	bean.repository = dependency.(Repository)
+	dependency, err = resolve("httpClient")
+	if err != nil {
+		return err
+	}
+	bean.client = dependency.(*http.Client)
+	return nil
+}
+
+// getUserRepository function returns the "repository" bean.
+func getUserRepository() *userRepository {
+	return di.GetInstance("repository").(*userRepository)
+}
+
+// InjectDependencies method injects dependencies of the "repository" bean without reflection.
+func (bean *userRepository) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+	return nil
+}
+
+// GetSession function returns the "session" bean, the context is propagated to the created instances.
+func GetSession(ctx context.Context) *Session {
+	return di.GetInstanceCtx(ctx, "session").(*Session)
+}
+
+// Session is injected using reflection: field Locale is injected with a value.
+
+// RequestDataFromContext function returns the "requestData" bean from the request context.
+func RequestDataFromContext(ctx context.Context) *RequestData {
+	return di.MustFromContext[*RequestData](ctx, "requestData")
+}
+
+// InjectDependencies method injects dependencies of the "requestData" bean without reflection.
+func (bean *RequestData) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+	var dependency interface{}
+	var err error
+	dependency, err = resolve("userService")
+	if err != nil {
+		return err
+	}
+	bean.service = dependency.(*UserService)
+	return nil
+}
+
+// GetNotifications function returns the "notifications" bean.
+func GetNotifications() *Notifications {
+	return di.GetInstance("notifications").(*Notifications)
+}
+
+// InjectDependencies method injects dependencies of the "notifications" bean without reflection.
+func (bean *Notifications) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+	return nil
+}
+
+// repositoryProxy is a proxy of Repository, calling the interceptors of the wrapped bean (see di.RegisterInterceptor).
+type repositoryProxy struct {
+	target    Repository
+	intercept di.InterceptFunc
+}
+
+// newRepositoryProxy function creates the proxy of Repository.
+func newRepositoryProxy(target Repository, intercept di.InterceptFunc) Repository {
+	return &repositoryProxy{target: target, intercept: intercept}
+}
+
+// FindUser method calls the interceptors of the FindUser method.
+func (proxy *repositoryProxy) FindUser(arg0 string) string {
+	result, err := proxy.intercept("FindUser", []interface{}{arg0}, func() (interface{}, error) {
+		return proxy.target.FindUser(arg0), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	value, _ := result.(string)
+	return value
+}
+
+// notifierProxy is a proxy of Notifier, calling the interceptors of the wrapped bean (see di.RegisterInterceptor).
+type notifierProxy struct {
+	target    Notifier
+	intercept di.InterceptFunc
+}
+
+// newNotifierProxy function creates the proxy of Notifier.
+func newNotifierProxy(target Notifier, intercept di.InterceptFunc) Notifier {
+	return &notifierProxy{target: target, intercept: intercept}
+}
+
+// Notify method calls the interceptors of the Notify method.
+func (proxy *notifierProxy) Notify(arg0 context.Context, arg1 ...string) error {
+	_, err := proxy.intercept("Notify", []interface{}{arg0, arg1}, func() (interface{}, error) {
+		return nil, proxy.target.Notify(arg0, arg1...)
+	})
+	return err
+}
+
+// Request method calls the interceptors of the Request method.
+func (proxy *notifierProxy) Request(arg0 string) (*http.Request, error) {
+	result, err := proxy.intercept("Request", []interface{}{arg0}, func() (interface{}, error) {
+		return proxy.target.Request(arg0)
+	})
+	value, _ := result.(*http.Request)
+	return value, err
+}
+
+// Sent method calls the interceptors of the Sent method.
+func (proxy *notifierProxy) Sent() int {
+	result, err := proxy.intercept("Sent", []interface{}{}, func() (interface{}, error) {
+		return proxy.target.Sent(), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	value, _ := result.(int)
+	return value
+}
+
+// Reset method calls the interceptors of the Reset method.
+func (proxy *notifierProxy) Reset() {
+	_, err := proxy.intercept("Reset", []interface{}{}, func() (interface{}, error) {
+		proxy.target.Reset()
+		return nil, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// EmptyCollectionPolicy defines how the container reacts when no beans are found for the injection into the slice or
+// map field that is not marked as optional. Optional fields (`di.optional:"true"` or `di.onMissing:"nil"`) are always
+// left nil in this case.
+type EmptyCollectionPolicy int
+
+const (
+	// InjectEmptyCollection policy injects an empty (non-nil) slice or map. This is the default policy.
+	InjectEmptyCollection EmptyCollectionPolicy = iota
+	// FailOnEmptyCollection policy makes the injection fail, since at least one bean is required, same as for the
+	// missing pointer or interface dependency.
+	FailOnEmptyCollection
+)
+
+var emptyCollectionPolicy = InjectEmptyCollection
+
+// Keyed interface can be implemented by beans injected into maps, so that they're keyed by the returned value rather
+// than by their bean IDs (e.g. handlers keyed by their route or command names). Beans registered by type can also
+// declare their key with a tag `di.key:"name"`, which takes precedence over the method.
+type Keyed interface {
+	Key() string
+}
+
+// SetEmptyCollectionPolicy function sets the policy defining how the container reacts when no beans are found for the
+// injection into the non-optional slice or map field. Regardless of the policy, the injection into the field tagged
+// with `di.onMissing:"error"` fails if there are no beans to inject.
+func SetEmptyCollectionPolicy(policy EmptyCollectionPolicy) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	emptyCollectionPolicy = policy
+	invalidateInjectionPlans()
+}
+
+// checkCollectionSize function configures the collection injection step for the case when there are not enough beans
+// to inject. If the field is tagged with `di.min`, the step fails if there are fewer beans than required. Otherwise, if
+// there are no beans to inject, the optional field is left nil, and either an empty collection is injected or the step
+// fails, depending on the `di.onMissing` tag and the EmptyCollectionPolicy.
+func checkCollectionSize(beanID string, field reflect.StructField, onMissingDependency onMissingPolicy,
+	step *injectionStep) {
+	step.emptyCollection = onMissingDependency != onMissingNil
+	if minTag, ok := minSize.lookup(field); ok {
+		minBeans, err := strconv.Atoi(minTag)
+		if err != nil || minBeans < 0 {
+			step.err = errors.New("invalid di.min value: " + minTag)
+			return
+		}
+		if len(step.beanIDs) < minBeans {
+			step.err = errors.New("at least " + minTag + " implementations required for the injection into field " +
+				field.Name + " of bean " + beanID + ", found " + strconv.Itoa(len(step.beanIDs)) + ": " +
+				field.Type.String())
+		}
+		return
+	}
+	if len(step.beanIDs) > 0 || !step.emptyCollection {
+		return
+	}
+	_, explicitPolicy := onMissing.lookup(field)
+	if onMissingDependency == onMissingError && (explicitPolicy || emptyCollectionPolicy == FailOnEmptyCollection) {
+		step.err = errors.New("at least one implementation required for the injection into field " + field.Name +
+			" of bean " + beanID + ": " + field.Type.String())
+	}
+}
+
+// mapKey function returns the key of the bean injected into the map: the value of its `di.key` tag, the result of its
+// Key method (see Keyed) or, by default, its ID.
+func mapKey(beanID string, instance interface{}) string {
+	if beanType, ok := getBeanType(beanID); ok && beanType.Kind() == reflect.Ptr &&
+		beanType.Elem().Kind() == reflect.Struct {
+		for i := 0; i < beanType.Elem().NumField(); i++ {
+			if keyTag := key.get(beanType.Elem().Field(i)); keyTag != "" {
+				return keyTag
+			}
+		}
+	}
+	if keyed, ok := instance.(Keyed); ok {
+		return keyed.Key()
+	}
+	return beanID
+}
+
+// injectMapEntry function puts the bean into the map field of another bean, failing if its key is already taken.
+func injectMapEntry(beanID string, field reflect.StructField, fieldToInject reflect.Value, beanToInject string,
+	instanceToInject interface{}) error {
+	entryKey := reflect.ValueOf(mapKey(beanToInject, instanceToInject))
+	if fieldToInject.MapIndex(entryKey).IsValid() {
+		return errors.New("duplicate key " + entryKey.String() + " of bean " + beanToInject +
+			" for the injection into field " + field.Name + " of bean " + beanID)
+	}
+	fieldToInject.SetMapIndex(entryKey, reflect.ValueOf(instanceToInject))
+	return nil
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type collectionConsumer struct {
+	Greeters    []greeter          `di.inject:""`
+	GreeterMap  map[string]greeter `di.inject:""`
+	Optional    []greeter          `di.inject:"" di.optional:"true"`
+	OptionalMap map[string]greeter `di.inject:"" di.onMissing:"nil"`
+}
+
+type requiredCollectionConsumer struct {
+	Greeters []greeter `di.inject:"" di.onMissing:"error"`
+}
+
+type requiredGroupConsumer struct {
+	Greeters []greeter `di.inject.group:"greeters" di.onMissing:"error"`
+}
+
+func (suite *TestSuite) TestEmptyCollectionsAreInjectedByDefault() {
+	_, err := RegisterBean("consumer", reflect.TypeOf((*collectionConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*collectionConsumer)
+	assert.NotNil(suite.T(), consumer.Greeters)
+	assert.Empty(suite.T(), consumer.Greeters)
+	assert.NotNil(suite.T(), consumer.GreeterMap)
+	assert.Empty(suite.T(), consumer.GreeterMap)
+	assert.Nil(suite.T(), consumer.Optional)
+	assert.Nil(suite.T(), consumer.OptionalMap)
+}
+
+func (suite *TestSuite) TestFailOnEmptyCollection() {
+	SetEmptyCollectionPolicy(FailOnEmptyCollection)
+	_, err := RegisterBean("consumer", reflect.TypeOf((*collectionConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "at least one implementation required for the injection into field Greeters "+
+		"of bean consumer: []di.greeter")
+}
+
+func (suite *TestSuite) TestFailOnEmptyCollectionWithCandidates() {
+	SetEmptyCollectionPolicy(FailOnEmptyCollection)
+	_, err := RegisterBean("consumer", reflect.TypeOf((*collectionConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*collectionConsumer)
+	assert.Len(suite.T(), consumer.Greeters, 1)
+	assert.Len(suite.T(), consumer.GreeterMap, 1)
+	assert.Len(suite.T(), consumer.Optional, 1)
+	assert.Len(suite.T(), consumer.OptionalMap, 1)
+}
+
+func (suite *TestSuite) TestOnMissingErrorRequiresCollectionElements() {
+	_, err := RegisterBean("consumer", reflect.TypeOf((*requiredCollectionConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "at least one implementation required for the injection into field Greeters "+
+		"of bean consumer: []di.greeter")
+	Reset()
+	_, err = RegisterBean("consumer", reflect.TypeOf((*requiredGroupConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "at least one implementation required for the injection into field Greeters "+
+		"of bean consumer: []di.greeter")
+}
+
+type pluginHost struct {
+	Plugins map[string]greeter `di.inject:"" di.min:"2"`
+}
+
+func (suite *TestSuite) TestMinCollectionSize() {
+	_, err := RegisterBean("host", reflect.TypeOf((*pluginHost)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "at least 2 implementations required for the injection into field Plugins of "+
+		"bean host, found 1: map[string]di.greeter")
+	Reset()
+	_, err = RegisterBean("host", reflect.TypeOf((*pluginHost)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("french", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), GetInstance("host").(*pluginHost).Plugins, 2)
+}
+
+func (suite *TestSuite) TestMinCollectionSizeOfGroup() {
+	type GroupHost struct {
+		Plugins []greeter `di.inject.group:"plugins" di.min:"1"`
+	}
+	_, err := RegisterBean("host", reflect.TypeOf((*GroupHost)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "at least 1 implementations required for the injection into field Plugins of "+
+		"bean host, found 0: []di.greeter")
+}
+
+func (suite *TestSuite) TestInvalidMinCollectionSize() {
+	type InvalidHost struct {
+		Plugins []greeter `di.inject:"" di.min:"-1"`
+	}
+	type PointerHost struct {
+		Plugin greeter `di.inject:"" di.min:"1"`
+	}
+	_, err := RegisterBean("host", reflect.TypeOf((*InvalidHost)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "invalid di.min value: -1")
+	Reset()
+	_, err = RegisterBean("host", reflect.TypeOf((*PointerHost)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "di.min can only be used for slices and maps: Plugin")
+}
+
+type helloCommand struct {
+	Key struct{} `di.key:"hello"`
+}
+
+func (*helloCommand) Greet() string {
+	return "hello"
+}
+
+type byeCommand struct{}
+
+func (*byeCommand) Greet() string {
+	return "bye"
+}
+
+func (*byeCommand) Key() string {
+	return "bye"
+}
+
+type commandRouter struct {
+	Commands map[string]greeter `di.inject:""`
+	Group    map[string]greeter `di.inject.group:"commands"`
+}
+
+func (suite *TestSuite) TestMapInjectionKeys() {
+	_, err := RegisterBean("router", reflect.TypeOf((*commandRouter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("helloCommand", reflect.TypeOf((*helloCommand)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("byeCommand", &byeCommand{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = AddToGroup("commands", "helloCommand", "byeCommand")
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	router := GetInstance("router").(*commandRouter)
+	assert.Equal(suite.T(), map[string]greeter{
+		"hello":   GetInstance("helloCommand").(greeter),
+		"bye":     GetInstance("byeCommand").(greeter),
+		"english": GetInstance("english").(greeter),
+	}, router.Commands)
+	assert.Equal(suite.T(), map[string]greeter{
+		"hello": GetInstance("helloCommand").(greeter),
+		"bye":   GetInstance("byeCommand").(greeter),
+	}, router.Group)
+}
+
+func (suite *TestSuite) TestDuplicateMapInjectionKeys() {
+	type Router struct {
+		Commands map[string]greeter `di.inject:""`
+	}
+	_, err := RegisterBean("router", reflect.TypeOf((*Router)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("helloCommand", reflect.TypeOf((*helloCommand)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("hello", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "duplicate key hello of bean helloCommand for the injection into field "+
+		"Commands of bean router")
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// CommandInfo holds the CLI command executed in the scope created by WrapCommand. Request-scoped beans can have it
+// injected by type (`di.inject:""`), e.g. to read flags or the config path, other code can retrieve it with
+// CommandInfoFromContext.
+type CommandInfo struct {
+	// Command is the executed command, e.g. *cobra.Command.
+	Command interface{}
+	// Args are the positional arguments of the command.
+	Args []string
+}
+
+var commandInfoType = reflect.TypeOf((*CommandInfo)(nil))
+
+// WrapCommand function wraps the CLI command, so that every execution of it gets its own set of Request-scoped beans,
+// just like web requests do with Middleware. Beans are created lazily in the context passed to the command, and the
+// ones implementing io.Closer are closed when the command returns (see SetRequestBeansClosePolicy). If the command has
+// a `Context() context.Context` method (like *cobra.Command does), the scope is derived from its context. The signature
+// of the returned function matches the one of cobra's `RunE`:
+//
+//	cmd.RunE = di.WrapCommand(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+//		session := di.MustFromContext[*Session](ctx, "session")
+//		// ...
+//	})
+func WrapCommand[C any](run func(ctx context.Context, cmd C, args []string) error) func(cmd C, args []string) error {
+	return func(cmd C, args []string) error {
+		var ctx context.Context
+		if contextual, ok := interface{}(cmd).(interface{ Context() context.Context }); ok {
+			ctx = contextual.Context()
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		requestContext := beginScope(ctx)
+		requestContext.scope.command = &CommandInfo{Command: cmd, Args: args}
+		defer requestContext.scope.close()
+		return run(requestContext, cmd, args)
+	}
+}
+
+// CommandInfoFromContext function returns CommandInfo of the scope the passed context belongs to, or `false` if it
+// doesn't belong to any or the scope is not created by WrapCommand.
+func CommandInfoFromContext(ctx context.Context) (*CommandInfo, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext)
+	if !ok || requestContext.scope.command == nil {
+		return nil, false
+	}
+	return requestContext.scope.command, true
+}
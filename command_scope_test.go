@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cliCommand struct {
+	ctx   context.Context
+	flags map[string]string
+}
+
+func (cc *cliCommand) Context() context.Context {
+	return cc.ctx
+}
+
+type cliSession struct {
+	Scope   Scope        `di.scope:"request"`
+	Command *CommandInfo `di.inject:""`
+	closed  bool
+}
+
+func (cs *cliSession) Close() error {
+	cs.closed = true
+	return nil
+}
+
+type commandContextKey struct{}
+
+func (suite *TestSuite) TestWrapCommand() {
+	overwritten, err := RegisterBean("session", reflect.TypeOf((*cliSession)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	var sessions []*cliSession
+	run := WrapCommand(func(ctx context.Context, cmd *cliCommand, args []string) error {
+		assert.Equal(suite.T(), "value", ctx.Value(commandContextKey{}))
+		info, ok := CommandInfoFromContext(ctx)
+		assert.True(suite.T(), ok)
+		assert.Same(suite.T(), cmd, info.Command)
+		assert.Equal(suite.T(), args, info.Args)
+		_, ok = RequestInfoFromContext(ctx)
+		assert.True(suite.T(), ok)
+		session := MustFromContext[*cliSession](ctx, "session")
+		assert.Same(suite.T(), info, session.Command)
+		assert.False(suite.T(), session.closed)
+		sessions = append(sessions, session)
+		if config := session.Command.Command.(*cliCommand).flags["config"]; config == "" {
+			return errors.New("config is not set")
+		}
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), commandContextKey{}, "value")
+	assert.NoError(suite.T(), run(&cliCommand{ctx: ctx, flags: map[string]string{"config": "app.yaml"}}, []string{"a"}))
+	assert.EqualError(suite.T(), run(&cliCommand{ctx: ctx}, nil), "config is not set")
+	assert.Len(suite.T(), sessions, 2)
+	assert.NotSame(suite.T(), sessions[0], sessions[1])
+	assert.True(suite.T(), sessions[0].closed)
+	assert.True(suite.T(), sessions[1].closed)
+	_, ok := CommandInfoFromContext(context.Background())
+	assert.False(suite.T(), ok)
+}
+
+func (suite *TestSuite) TestWrapCommandWithoutContext() {
+	overwritten, err := RegisterBean("session", reflect.TypeOf((*cliSession)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	run := WrapCommand(func(ctx context.Context, cmd string, args []string) error {
+		session := MustFromContext[*cliSession](ctx, "session")
+		assert.Equal(suite.T(), "migrate", session.Command.Command)
+		return nil
+	})
+	assert.NoError(suite.T(), run("migrate", nil))
+	assert.NoError(suite.T(), WrapCommand(func(ctx context.Context, cmd *cliCommand, args []string) error {
+		_, ok := CommandInfoFromContext(ctx)
+		assert.True(suite.T(), ok)
+		return nil
+	})(&cliCommand{}, nil))
+}
+
+func (suite *TestSuite) TestCommandInfoOutsideOfCommand() {
+	overwritten, err := RegisterBean("session", reflect.TypeOf((*cliSession)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	_, ok := CommandInfoFromContext(ctx)
+	assert.False(suite.T(), ok)
+	scope, ok := RequestScopeFromContext(ctx)
+	assert.True(suite.T(), ok)
+	_, err = scope.Get("session")
+	assert.ErrorContains(suite.T(), err, "*di.CommandInfo is not available in the scope of bean session: "+
+		"it's only set by WrapCommand")
+}
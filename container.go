@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// Container is a handle of the IoC container, that is passed to ContainerAware beans, so that they can query their
+// peers. Note that GetBeanTypes and GetBeanScopes can't be called while the container is being initialized (e.g. from
+// PostConstruct of singletons).
+type Container struct{}
+
+// BeanNameAware is an interface marking beans that need to know their own bean ID.
+type BeanNameAware interface {
+	// SetBeanName method will be called on a bean upon its initialization, before PostConstruct.
+	SetBeanName(beanID string)
+}
+
+// ContainerAware is an interface marking beans that need a reference to the container.
+type ContainerAware interface {
+	// SetContainer method will be called on a bean upon its initialization, before PostConstruct.
+	SetContainer(container *Container)
+}
+
+var container = &Container{}
+
+// GetInstance method returns bean instance by its ID (see GetInstance function).
+func (c *Container) GetInstance(beanID string) interface{} {
+	return GetInstance(beanID)
+}
+
+// GetInstanceSafe method returns bean instance by its ID (see GetInstanceSafe function).
+func (c *Container) GetInstanceSafe(beanID string) (interface{}, error) {
+	return GetInstanceSafe(beanID)
+}
+
+// GetInstanceSafeCtx method returns bean instance by its ID, propagating the context (see GetInstanceSafeCtx function).
+func (c *Container) GetInstanceSafeCtx(ctx context.Context, beanID string) (interface{}, error) {
+	return GetInstanceSafeCtx(ctx, beanID)
+}
+
+// GetBeanTypes method returns a map (copy) of beans registered in the container (see GetBeanTypes function).
+func (c *Container) GetBeanTypes() map[string]reflect.Type {
+	return GetBeanTypes()
+}
+
+// GetBeanScopes method returns a map (copy) of bean scopes registered in the container (see GetBeanScopes function).
+func (c *Container) GetBeanScopes() map[string]Scope {
+	return GetBeanScopes()
+}
+
+// setAwareness function passes the bean ID and the container to the beans implementing BeanNameAware and
+// ContainerAware.
+func setAwareness(beanID string, instance interface{}) {
+	if impl, ok := instance.(BeanNameAware); ok {
+		impl.SetBeanName(beanID)
+	}
+	if impl, ok := instance.(ContainerAware); ok {
+		impl.SetContainer(container)
+	}
+}
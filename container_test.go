@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dispatcherBean struct {
+	Scope     Scope `di.scope:"prototype"`
+	beanID    string
+	container *Container
+}
+
+func (db *dispatcherBean) SetBeanName(beanID string) {
+	db.beanID = beanID
+}
+
+func (db *dispatcherBean) SetContainer(container *Container) {
+	db.container = container
+}
+
+type registryBean struct {
+	beanID string
+}
+
+func (rb *registryBean) SetBeanName(beanID string) {
+	rb.beanID = beanID
+}
+
+func (suite *TestSuite) TestAwareBeans() {
+	overwritten, err := RegisterBean("dispatcher", reflect.TypeOf((*dispatcherBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("registry", reflect.TypeOf((*registryBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "registry", GetInstance("registry").(*registryBean).beanID)
+	dispatcher := GetInstance("dispatcher").(*dispatcherBean)
+	assert.Equal(suite.T(), "dispatcher", dispatcher.beanID)
+	assert.NotNil(suite.T(), dispatcher.container)
+	assert.Same(suite.T(), GetInstance("registry"), dispatcher.container.GetInstance("registry"))
+	assert.Equal(suite.T(), GetBeanTypes(), dispatcher.container.GetBeanTypes())
+	assert.Equal(suite.T(), map[string]Scope{"dispatcher": Prototype, "registry": Singleton}, dispatcher.container.GetBeanScopes())
+	_, err = dispatcher.container.GetInstanceSafe("unknown")
+	assert.Error(suite.T(), err)
+}
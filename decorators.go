@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// beanDecorator is a function wrapping the bean instance, see Decorate.
+type beanDecorator func(inner interface{}) (interface{}, error)
+
+// beanDecorators keep decorators of the beans, in registration order.
+var beanDecorators = make(map[string][]beanDecorator)
+
+// Decorate function registers the decorator of the bean: upon every creation of the bean (after its PostConstruct and
+// postprocessors), the instance is passed to the decorator, and the returned one (e.g. a caching decorator around the
+// repository) is stored in the container and injected into other beans instead, by ID or by type. Decorated beans
+// should be injected by interfaces, since decorators are usually of different types. Multiple decorators are applied in
+// registration order, so the last registered one is the outermost. Decorators are dropped along with the bean, if it's
+// re-registered or overridden.
+func Decorate(beanID string, decorator func(inner interface{}) (interface{}, error)) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't decorate bean")
+	}
+	if decorator == nil {
+		return errors.New("decorator can't be nil")
+	}
+	beanID = resolveAlias(beanID)
+	if !isBeanRegistered(beanID) {
+		return errors.New("bean is not registered: " + beanID)
+	}
+	beanDecorators[beanID] = append(beanDecorators[beanID], decorator)
+	return nil
+}
+
+// decorateBean function applies decorators of the bean to the instance.
+func decorateBean(beanID string, instance interface{}) (interface{}, error) {
+	for _, decorator := range beanDecorators[beanID] {
+		decoratedInstance, err := decorator(instance)
+		if err != nil {
+			return nil, err
+		}
+		if decoratedInstance == nil {
+			return nil, errors.New("decorator returned nil instance of bean: " + beanID)
+		}
+		instance = decoratedInstance
+	}
+	return instance, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userStore interface {
+	Find(id string) string
+}
+
+type databaseUserStore struct {
+	queries int
+}
+
+func (dus *databaseUserStore) Find(id string) string {
+	dus.queries++
+	return "user " + id
+}
+
+type cachingUserStore struct {
+	inner userStore
+	cache map[string]string
+}
+
+func (cus *cachingUserStore) Find(id string) string {
+	if user, ok := cus.cache[id]; ok {
+		return user
+	}
+	cus.cache[id] = cus.inner.Find(id)
+	return cus.cache[id]
+}
+
+type userStoreConsumer struct {
+	byID   userStore `di.inject:"userStore"`
+	byType userStore `di.inject:""`
+}
+
+func cachingDecorator(inner interface{}) (interface{}, error) {
+	return &cachingUserStore{inner: inner.(userStore), cache: make(map[string]string)}, nil
+}
+
+func (suite *TestSuite) TestDecorate() {
+	overwritten, err := RegisterBean("userStore", reflect.TypeOf((*databaseUserStore)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*userStoreConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Decorate("userStore", cachingDecorator))
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	store := GetInstance("userStore").(*cachingUserStore)
+	consumer := GetInstance("consumer").(*userStoreConsumer)
+	assert.Same(suite.T(), store, consumer.byID)
+	assert.Same(suite.T(), store, consumer.byType)
+	assert.Equal(suite.T(), "user 42", consumer.byID.Find("42"))
+	assert.Equal(suite.T(), "user 42", consumer.byType.Find("42"))
+	assert.Equal(suite.T(), 1, store.inner.(*databaseUserStore).queries)
+}
+
+type countingUserStore struct {
+	userStore
+	layer string
+}
+
+func (suite *TestSuite) TestDecoratePrototype() {
+	type prototypeUserStore struct {
+		databaseUserStore
+		Scope Scope `di.scope:"prototype"`
+	}
+	overwritten, err := RegisterBean("userStore", reflect.TypeOf((*prototypeUserStore)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	for _, layer := range []string{"inner", "outer"} {
+		layer := layer
+		err = Decorate("userStore", func(inner interface{}) (interface{}, error) {
+			return &countingUserStore{userStore: inner.(userStore), layer: layer}, nil
+		})
+		assert.NoError(suite.T(), err)
+	}
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	outer := GetInstance("userStore").(*countingUserStore)
+	assert.Equal(suite.T(), "outer", outer.layer)
+	assert.Equal(suite.T(), "inner", outer.userStore.(*countingUserStore).layer)
+	assert.NotSame(suite.T(), outer, GetInstance("userStore"))
+}
+
+func (suite *TestSuite) TestDecorateErrors() {
+	assert.EqualError(suite.T(), Decorate("userStore", cachingDecorator), "bean is not registered: userStore")
+	overwritten, err := RegisterBean("userStore", reflect.TypeOf((*databaseUserStore)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.EqualError(suite.T(), Decorate("userStore", nil), "decorator can't be nil")
+	err = Decorate("userStore", func(inner interface{}) (interface{}, error) {
+		return nil, errors.New("cache is not available")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "cache is not available")
+	assert.Error(suite.T(), Decorate("userStore", cachingDecorator))
+}
+
+func (suite *TestSuite) TestDecoratorsAreDroppedUponReregistration() {
+	overwritten, err := RegisterBean("userStore", reflect.TypeOf((*databaseUserStore)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Decorate("userStore", cachingDecorator))
+	overwritten, err = RegisterBean("userStore", reflect.TypeOf((*databaseUserStore)(nil)))
+	assert.True(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &databaseUserStore{}, GetInstance("userStore"))
+}
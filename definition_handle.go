@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+)
+
+// lazyBeans keep IDs of Singleton beans created upon the first lookup (or injection) instead of the container
+// initialization.
+var lazyBeans = make(map[string]bool)
+
+// beanDependencies keep IDs of the beans that must be instantiated before the bean, see BeanDefinitionHandle.DependsOn.
+var beanDependencies = make(map[string][]string)
+
+// beanQualifiers keep qualifiers of the beans matched by the PreferQualifierMatch policy along with their IDs.
+var beanQualifiers = make(map[string]string)
+
+// lazySingletons hold instances of lazy Singleton beans created after the container initialization started.
+var lazySingletons = &refreshScope{entries: make(map[string]*requestScopeEntry)}
+
+// BeanDefinitionHandle lets the definition of the registered bean be tweaked before the container is initialized, e.g.
+// for the types from third-party packages that can't be tagged:
+//
+//	err := di.ConfigureBean("client").WithScope(di.Prototype).Primary().DependsOn("config").Err()
+//
+// Methods are applied right away; once any of them fails, the rest are skipped and Err returns the error.
+type BeanDefinitionHandle struct {
+	beanID string
+	err    error
+}
+
+// ConfigureBean function returns the handle of the registered bean definition.
+func ConfigureBean(beanID string) *BeanDefinitionHandle {
+	return &BeanDefinitionHandle{beanID: beanID}
+}
+
+// WithScope method changes the scope of the bean (see BeanDefinitionRegistry.SetScope).
+func (h *BeanDefinitionHandle) WithScope(beanScope Scope) *BeanDefinitionHandle {
+	return h.apply(func() error {
+		if err := setScope(h.beanID, beanScope); err != nil {
+			return err
+		}
+		invalidateInjectionPlans()
+		return nil
+	})
+}
+
+// Lazy method makes the Singleton bean created upon the first lookup (or injection into another bean) instead of the
+// container initialization.
+func (h *BeanDefinitionHandle) Lazy() *BeanDefinitionHandle {
+	return h.apply(func() error {
+		if scopes[h.beanID] != Singleton {
+			return errors.New("only singleton beans can be lazy: " + h.beanID)
+		}
+		if userCreatedInstances[h.beanID] {
+			return errors.New("bean instance can't be lazy: " + h.beanID)
+		}
+		lazyBeans[h.beanID] = true
+		return nil
+	})
+}
+
+// Primary method marks the bean as primary (see MarkPrimary).
+func (h *BeanDefinitionHandle) Primary() *BeanDefinitionHandle {
+	return h.apply(func() error {
+		primaryBeans[h.beanID] = true
+		return nil
+	})
+}
+
+// DependsOn method makes sure the given beans are instantiated before the bean, even though it doesn't reference them
+// (e.g. the bean relies on the side effects of their initialization). Singleton dependencies of Singleton beans are
+// also initialized first.
+func (h *BeanDefinitionHandle) DependsOn(beanIDs ...string) *BeanDefinitionHandle {
+	return h.apply(func() error {
+		for _, beanID := range beanIDs {
+			if beanID == h.beanID {
+				return errors.New("bean can't depend on itself: " + beanID)
+			}
+		}
+		beanDependencies[h.beanID] = append(beanDependencies[h.beanID], beanIDs...)
+		return nil
+	})
+}
+
+// Qualifier method sets the qualifier of the bean: the PreferQualifierMatch policy matches it against qualifiers of the
+// fields, along with the bean ID.
+func (h *BeanDefinitionHandle) Qualifier(qualifier string) *BeanDefinitionHandle {
+	return h.apply(func() error {
+		beanQualifiers[h.beanID] = qualifier
+		invalidateInjectionPlans()
+		return nil
+	})
+}
+
+// Err method returns the error of the first method that failed.
+func (h *BeanDefinitionHandle) Err() error {
+	return h.err
+}
+
+func (h *BeanDefinitionHandle) apply(change func() error) *BeanDefinitionHandle {
+	if h.err != nil {
+		return h
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		h.err = errors.New("container is already initialized: can't change bean definition")
+	} else if !isBeanRegistered(h.beanID) {
+		h.err = errors.New("bean is not registered: " + h.beanID)
+	} else {
+		h.err = change()
+	}
+	return h
+}
+
+// isEagerSingleton function checks if the bean is a Singleton bean created upon the container initialization.
+func isEagerSingleton(beanID string) bool {
+	return scopes[beanID] == Singleton && !lazyBeans[beanID]
+}
+
+// instantiateDependencies function instantiates the beans the bean depends on (see BeanDefinitionHandle.DependsOn).
+func instantiateDependencies(ctx context.Context, beanID string, chain *dependencyChain) error {
+	for _, dependencyID := range beanDependencies[beanID] {
+		dependencyID = resolveAlias(dependencyID)
+		if !isBeanRegistered(dependencyID) {
+			return errors.New("bean " + beanID + " depends on the bean that is not registered: " + dependencyID)
+		}
+		if isEagerSingleton(dependencyID) && atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+			if _, created := singletonInstances[dependencyID]; !created {
+				if err := createSingletonFactoryInstance(dependencyID, chain); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err := getDependencyInstance(ctx, beanID, dependencyID, chain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderByDependencies function sorts IDs of the beans, so that every bean follows the beans it depends on.
+func orderByDependencies(beanIDs []string) ([]string, error) {
+	sort.Strings(beanIDs)
+	ordered := make([]string, 0, len(beanIDs))
+	included := make(map[string]bool, len(beanIDs))
+	for _, beanID := range beanIDs {
+		included[beanID] = true
+	}
+	visited := make(map[string]bool, len(beanIDs))
+	chain := newDependencyChain()
+	var visit func(beanID string) error
+	visit = func(beanID string) error {
+		if visited[beanID] {
+			return nil
+		}
+		if chain.contains(beanID) {
+			return chain.circularDependencyError(beanID)
+		}
+		chain.push(beanID)
+		defer chain.pop()
+		for _, dependencyID := range beanDependencies[beanID] {
+			if dependencyID = resolveAlias(dependencyID); included[dependencyID] {
+				if err := visit(dependencyID); err != nil {
+					return err
+				}
+			}
+		}
+		visited[beanID] = true
+		ordered = append(ordered, beanID)
+		return nil
+	}
+	for _, beanID := range beanIDs {
+		if err := visit(beanID); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
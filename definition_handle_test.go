@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var initializedBeans []string
+
+type orderedBean struct {
+	id     string
+	closed bool
+}
+
+func (ob *orderedBean) PostConstruct() error {
+	initializedBeans = append(initializedBeans, ob.id)
+	return nil
+}
+
+func (ob *orderedBean) Close() error {
+	ob.closed = true
+	return nil
+}
+
+func (suite *TestSuite) registerOrderedBean(beanID string, beanScope Scope) {
+	_, err := RegisterBeanFactory(beanID, beanScope, func(context.Context) (interface{}, error) {
+		return &orderedBean{id: beanID}, nil
+	})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TestSuite) TestConfigureBeanScope() {
+	_, err := RegisterBean("bean", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("bean").WithScope(Singleton).Err()
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("bean"), GetInstance("bean"))
+}
+
+func (suite *TestSuite) TestConfigureBeanPooledAndTenantScopes() {
+	_, err := RegisterBean("pooled", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("tenant", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ConfigureBean("pooled").WithScope(Pooled).Err())
+	assert.NoError(suite.T(), ConfigureBean("tenant").WithScope(Tenant).Err())
+	assert.Equal(suite.T(), Pooled, GetBeanScopes()["pooled"])
+	assert.Equal(suite.T(), Tenant, GetBeanScopes()["tenant"])
+	assert.EqualError(suite.T(), ConfigureBean("pooled").WithScope("unknown").Err(), "unsupported scope: unknown")
+}
+
+func (suite *TestSuite) TestLazyBean() {
+	initializedBeans = nil
+	suite.registerOrderedBean("lazy", Singleton)
+	err := ConfigureBean("lazy").Lazy().Err()
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), initializedBeans)
+	bean := GetInstance("lazy").(*orderedBean)
+	assert.Same(suite.T(), bean, GetInstance("lazy"))
+	assert.Equal(suite.T(), []string{"lazy"}, initializedBeans)
+	Close()
+	assert.True(suite.T(), bean.closed)
+}
+
+type lazyBeanConsumer struct {
+	Lazy *orderedBean `di.inject:"lazy"`
+}
+
+func (suite *TestSuite) TestLazyBeanInjectedIntoSingleton() {
+	_, err := RegisterBean("lazy", reflect.TypeOf((*orderedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*lazyBeanConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("lazy").Lazy().Err()
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("lazy"), GetInstance("consumer").(*lazyBeanConsumer).Lazy)
+}
+
+func (suite *TestSuite) TestConfigureBeanPrimaryAndQualifier() {
+	SetAmbiguityPolicy(PreferPrimary)
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("french", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("french").Primary().Qualifier("english").Err()
+	assert.NoError(suite.T(), err)
+	snapshot := Snapshot()
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &frenchGreeter{}, GetInstance("consumer").(*greeterConsumer).Greeter)
+	Restore(snapshot)
+	SetAmbiguityPolicy(PreferQualifierMatch)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*qualifiedConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	// both beans match the qualifier "english" now
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, err)
+}
+
+func (suite *TestSuite) TestDependsOn() {
+	initializedBeans = nil
+	for _, beanID := range []string{"a", "b", "c"} {
+		suite.registerOrderedBean(beanID, Singleton)
+	}
+	suite.registerOrderedBean("lazy", Singleton)
+	suite.registerOrderedBean("prototype", Prototype)
+	err := ConfigureBean("a").DependsOn("c", "lazy").Err()
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("c").DependsOn("b").Err()
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("lazy").Lazy().Err()
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("prototype").DependsOn("lazy").Err()
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	// the lazy bean is initialized right away, as it's created upon the creation of the bean depending on it
+	assert.Equal(suite.T(), []string{"lazy", "b", "c", "a"}, initializedBeans)
+	_ = GetInstance("prototype")
+	assert.Equal(suite.T(), []string{"lazy", "b", "c", "a", "prototype"}, initializedBeans)
+}
+
+func (suite *TestSuite) TestCircularDependsOn() {
+	suite.registerOrderedBean("a", Singleton)
+	suite.registerOrderedBean("b", Singleton)
+	assert.NoError(suite.T(), ConfigureBean("a").DependsOn("b").Err())
+	assert.NoError(suite.T(), ConfigureBean("b").DependsOn("a").Err())
+	err := InitializeContainer()
+	assert.IsType(suite.T(), &CircularDependencyError{}, err)
+	Reset()
+	_, err = RegisterBean("c", reflect.TypeOf((*orderedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("d", reflect.TypeOf((*orderedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ConfigureBean("c").DependsOn("d").Err())
+	assert.NoError(suite.T(), ConfigureBean("d").DependsOn("c").Err())
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "circular dependency detected for bean: c (c -> d -> c)")
+}
+
+func (suite *TestSuite) TestConfigureBeanErrors() {
+	suite.registerOrderedBean("prototype", Prototype)
+	_, err := RegisterBeanInstance("instance", &orderedBean{})
+	assert.NoError(suite.T(), err)
+	err = ConfigureBean("missing").Primary().Err()
+	assert.EqualError(suite.T(), err, "bean is not registered: missing")
+	err = ConfigureBean("prototype").Lazy().Primary().Err()
+	assert.EqualError(suite.T(), err, "only singleton beans can be lazy: prototype")
+	assert.False(suite.T(), primaryBeans["prototype"])
+	err = ConfigureBean("instance").Lazy().Err()
+	assert.EqualError(suite.T(), err, "bean instance can't be lazy: instance")
+	err = ConfigureBean("instance").WithScope(Prototype).Err()
+	assert.EqualError(suite.T(), err, "scope of the bean instance can't be changed: instance")
+	err = ConfigureBean("prototype").DependsOn("prototype").Err()
+	assert.EqualError(suite.T(), err, "bean can't depend on itself: prototype")
+	err = ConfigureBean("prototype").DependsOn("missing").Err()
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("prototype")
+	assert.EqualError(suite.T(), err, "bean prototype depends on the bean that is not registered: missing")
+	err = ConfigureBean("prototype").Primary().Err()
+	assert.EqualError(suite.T(), err, "container is already initialized: can't change bean definition")
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+var aliases = make(map[string]string)
+var registeredTypes = make(map[string]reflect.Type)
+
+// definitionsManifest is a structure of the manifest read by LoadDefinitions.
+type definitionsManifest struct {
+	Beans map[string]beanDefinitionManifest `yaml:"beans"`
+}
+
+type beanDefinitionManifest struct {
+	Type    string   `yaml:"type"`
+	Scope   Scope    `yaml:"scope"`
+	Aliases []string `yaml:"aliases"`
+}
+
+// RegisterType function adds the bean type to the type registry under the given name, so that it can be referred to
+// from the manifest loaded by LoadDefinitions. `beanType` should be a reference type, same as for RegisterBean.
+func RegisterType(name string, beanType reflect.Type) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if beanType == nil || beanType.Kind() != reflect.Ptr {
+		return errors.New("bean type must be a pointer")
+	}
+	registeredTypes[name] = beanType
+	return nil
+}
+
+// RegisterAlias function registers an alternative ID for the bean: the bean can be retrieved and injected by any of
+// them.
+func RegisterAlias(alias string, beanID string) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	return registerAlias(alias, beanID)
+}
+
+func registerAlias(alias string, beanID string) error {
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register alias")
+	}
+	if isBeanRegistered(alias) {
+		return errors.New("alias conflicts with registered bean: " + alias)
+	}
+	aliases[alias] = beanID
+	return nil
+}
+
+// LoadDefinitions function reads bean definitions from the YAML (or JSON) manifest and registers them in the container.
+// Bean types are referred to by names from the type registry (see RegisterType), so that implementations can be
+// switched without recompiling. The manifest looks like this:
+//
+//	beans:
+//	  paymentGateway:
+//	    type: stubPaymentGateway  # name from the type registry
+//	    scope: prototype          # optional, the `di.scope` tag of the type is used otherwise
+//	    aliases: [gateway]        # optional
+//
+// The whole manifest is validated before registering any of the beans, so that an invalid manifest doesn't leave the
+// container partially populated.
+func LoadDefinitions(r io.Reader) error {
+	var manifest definitionsManifest
+	if err := yaml.NewDecoder(r).Decode(&manifest); err != nil && err != io.EOF {
+		return errors.New("can't parse bean definitions: " + err.Error())
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register new bean")
+	}
+	beanIDs := make([]string, 0, len(manifest.Beans))
+	for beanID := range manifest.Beans {
+		beanIDs = append(beanIDs, beanID)
+	}
+	sort.Strings(beanIDs)
+	if err := validateDefinitions(manifest, beanIDs); err != nil {
+		return err
+	}
+	for _, beanID := range beanIDs {
+		definition := manifest.Beans[beanID]
+		var beanScope *Scope
+		if definition.Scope != "" {
+			beanScope = &definition.Scope
+		}
+		if _, err := registerBean(beanID, registeredTypes[definition.Type], beanScope); err != nil {
+			return err
+		}
+		for _, alias := range definition.Aliases {
+			if err := registerAlias(alias, beanID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateDefinitions function checks all the definitions of the manifest (types, scopes, conflicts of IDs and aliases)
+// the same way as the registration does.
+func validateDefinitions(manifest definitionsManifest, beanIDs []string) error {
+	aliasedBeanIDs := make(map[string]string)
+	for _, beanID := range beanIDs {
+		definition := manifest.Beans[beanID]
+		beanType, ok := registeredTypes[definition.Type]
+		if !ok {
+			return errors.New("type is not registered: " + definition.Type)
+		}
+		var beanScope *Scope
+		if definition.Scope != "" {
+			if !isSupportedScope(definition.Scope) {
+				return errors.New("unsupported scope: " + string(definition.Scope))
+			}
+			beanScope = &definition.Scope
+		}
+		if _, _, err := checkBeanType(beanID, beanType, beanScope); err != nil {
+			return errors.New("invalid definition of bean " + beanID + ": " + err.Error())
+		}
+		if overwritePolicy == OverwriteError && isBeanRegistered(beanID) {
+			return errors.New("bean with such ID is already registered: " + beanID)
+		}
+		for _, alias := range definition.Aliases {
+			if _, ok := manifest.Beans[alias]; ok || isBeanRegistered(alias) {
+				return errors.New("alias conflicts with registered bean: " + alias)
+			}
+			if aliasedBeanID, ok := aliasedBeanIDs[alias]; ok {
+				return errors.New("alias " + alias + " is defined for beans " + aliasedBeanID + " and " + beanID)
+			}
+			aliasedBeanIDs[alias] = beanID
+		}
+	}
+	return nil
+}
+
+// resolveAlias function returns the ID of the bean the alias refers to, or the passed ID itself if it's not an alias.
+func resolveAlias(beanID string) string {
+	if isBeanRegistered(beanID) {
+		return beanID
+	}
+	if aliasedBeanID, ok := aliases[beanID]; ok {
+		return aliasedBeanID
+	}
+	return beanID
+}
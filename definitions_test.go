@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type gatewayClient struct {
+	gateway *stubGateway `di.inject:"gateway"`
+}
+
+type stubGateway struct {
+	name string
+}
+
+func (suite *TestSuite) TestLoadDefinitions() {
+	assert.NoError(suite.T(), RegisterType("stubGateway", reflect.TypeOf((*stubGateway)(nil))))
+	assert.NoError(suite.T(), RegisterType("gatewayClient", reflect.TypeOf((*gatewayClient)(nil))))
+	err := LoadDefinitions(strings.NewReader(`
+beans:
+  paymentGateway:
+    type: stubGateway
+    scope: prototype
+    aliases: [gateway]
+  gatewayClient:
+    type: gatewayClient
+`))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Prototype, scopes["paymentGateway"])
+	assert.Equal(suite.T(), Singleton, scopes["gatewayClient"])
+	assert.IsType(suite.T(), &stubGateway{}, GetInstance("gateway"))
+	assert.NotSame(suite.T(), GetInstance("gateway"), GetInstance("paymentGateway"))
+	assert.NotNil(suite.T(), GetInstance("gatewayClient").(*gatewayClient).gateway)
+}
+
+func (suite *TestSuite) TestLoadDefinitionsFromJSON() {
+	assert.NoError(suite.T(), RegisterType("stubGateway", reflect.TypeOf((*stubGateway)(nil))))
+	err := LoadDefinitions(strings.NewReader(`{"beans": {"paymentGateway": {"type": "stubGateway"}}}`))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("paymentGateway"), GetInstance("paymentGateway"))
+}
+
+func (suite *TestSuite) TestLoadDefinitionsErrors() {
+	assert.Error(suite.T(), RegisterType("stubGateway", reflect.TypeOf(stubGateway{})))
+	assert.Error(suite.T(), LoadDefinitions(strings.NewReader(`beans: [`)))
+	assert.Error(suite.T(), LoadDefinitions(strings.NewReader(`{"beans": {"paymentGateway": {"type": "unknown"}}}`)))
+	assert.NoError(suite.T(), RegisterType("stubGateway", reflect.TypeOf((*stubGateway)(nil))))
+	err := LoadDefinitions(strings.NewReader(`{"beans": {"paymentGateway": {"type": "stubGateway", "scope": "unknown"}}}`))
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestLoadDefinitionsAllScopes() {
+	assert.NoError(suite.T(), RegisterType("stubGateway", reflect.TypeOf((*stubGateway)(nil))))
+	err := LoadDefinitions(strings.NewReader(`
+beans:
+  refreshGateway: {type: stubGateway, scope: refresh}
+  pooledGateway: {type: stubGateway, scope: pooled}
+  tenantGateway: {type: stubGateway, scope: tenant}
+`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Refresh, scopes["refreshGateway"])
+	assert.Equal(suite.T(), Pooled, scopes["pooledGateway"])
+	assert.Equal(suite.T(), Tenant, scopes["tenantGateway"])
+}
+
+func (suite *TestSuite) TestLoadDefinitionsValidatesWholeManifest() {
+	assert.NoError(suite.T(), RegisterType("stubGateway", reflect.TypeOf((*stubGateway)(nil))))
+	for _, manifest := range []string{`
+beans:
+  a: {type: stubGateway}
+  b: {type: unknown}
+`, `
+beans:
+  a: {type: stubGateway}
+  b: {type: stubGateway, scope: unknown}
+`, `
+beans:
+  a: {type: stubGateway, aliases: [gateway]}
+  b: {type: stubGateway, aliases: [gateway]}
+`, `
+beans:
+  a: {type: stubGateway, aliases: [b]}
+  b: {type: stubGateway}
+`} {
+		assert.Error(suite.T(), LoadDefinitions(strings.NewReader(manifest)), manifest)
+		assert.False(suite.T(), isBeanRegistered("a"), manifest)
+		assert.Empty(suite.T(), aliases, manifest)
+	}
+}
+
+func (suite *TestSuite) TestRegisterAlias() {
+	overwritten, err := RegisterBeanInstance("paymentGateway", &stubGateway{name: "stub"})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.Error(suite.T(), RegisterAlias("paymentGateway", "gateway"))
+	assert.NoError(suite.T(), RegisterAlias("gateway", "paymentGateway"))
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("paymentGateway"), GetInstance("gateway"))
+	assert.Error(suite.T(), RegisterAlias("anotherGateway", "paymentGateway"))
+}
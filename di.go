@@ -18,11 +18,14 @@ import (
 	"context"
 	"errors"
 	"io"
+	"math"
 	"reflect"
+	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
-	"unsafe"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -40,31 +43,96 @@ const (
 	// context). If the bean implements Close() method, then this method will be called upon corresponding context's
 	// cancellation.
 	Request Scope = "request"
+	// Refresh is a scope of bean that exists in one copy, just like Singleton, but is created lazily and is re-created
+	// after RefreshScope call. Such beans are meant to be injected into other beans through the Refreshable proxy.
+	Refresh Scope = "refresh"
+	// Pooled is a scope of bean whose instances are borrowed from a pool upon retrieval (or injection) and are returned
+	// to it when the owning context ends. It's meant for allocation-heavy beans, such as buffers or codecs.
+	Pooled Scope = "pooled"
+	// Tenant is a scope of bean that exists in one copy per tenant: instances are cached per tenant ID extracted from
+	// the context (see ConfigureTenantScope).
+	Tenant Scope = "tenant"
 )
 
 type tag string
 
 const (
-	scope    tag = "di.scope"
-	inject   tag = "di.inject"
-	optional tag = "di.optional"
+	scope        tag = "di.scope"
+	inject       tag = "di.inject"
+	optional     tag = "di.optional"
+	onMissing    tag = "di.onMissing"
+	profile      tag = "di.profile"
+	value        tag = "di.value"
+	group        tag = "di.group"
+	injectGroup  tag = "di.inject.group"
+	primary      tag = "di.primary"
+	qualifier    tag = "di.qualifier"
+	defaultBean  tag = "di.default"
+	includeSelf  tag = "di.includeSelf"
+	exclude      tag = "di.exclude"
+	minSize      tag = "di.min"
+	key          tag = "di.key"
+	injectStruct tag = "di.inject.struct"
+)
+
+// onMissingPolicy defines what happens when the dependency to be injected is not found in the container.
+type onMissingPolicy string
+
+const (
+	// onMissingNil leaves the field uninitialized (nil).
+	onMissingNil onMissingPolicy = "nil"
+	// onMissingDefault injects the default bean registered for the field type with RegisterDefaultBean.
+	onMissingDefault onMissingPolicy = "default"
+	// onMissingError fails the injection.
+	onMissingError onMissingPolicy = "error"
 )
 
 const (
 	unsupportedDependencyType        = "unsupported dependency type: all injections must be done by pointer, interface, slice or map"
 	beanAlreadyRegistered            = "bean with such ID is already registered, overwriting it"
-	requestScopedBeansCantBeInjected = "request-scoped beans can't be injected: they can only be retrieved from the web-context"
+	unsupportedValueType             = "unsupported value type: values can only be injected into fields of string, bool, numeric or time.Duration types"
+	requestScopedBeansCantBeInjected = "request-scoped beans can't be injected: they can only be injected into other request-scoped beans or retrieved from the web-context"
 )
 
 var initializeShutdownLock sync.Mutex
-var createInstanceLock sync.Mutex
 var containerInitialized int32
+var initializedSingletons atomic.Pointer[singletonsView]
 var beans = make(map[string]reflect.Type)
 var beanFactories = make(map[string]func(context.Context) (interface{}, error))
 var scopes = make(map[string]Scope)
 var singletonInstances = make(map[string]interface{})
 var userCreatedInstances = make(map[string]bool)
-var beanPostprocessors = make(map[reflect.Type][]func(bean interface{}) error)
+var beanPostprocessors = make(map[reflect.Type][]beanPostprocessor)
+var defaultBeans = make(map[reflect.Type]string)
+
+// OverwritePolicy defines how the container reacts on registration of a bean with an ID that is already registered.
+type OverwritePolicy int
+
+const (
+	// OverwriteWarn policy logs a warning and overwrites the registered bean. This is the default policy.
+	OverwriteWarn OverwritePolicy = iota
+	// OverwriteError policy makes the registration fail with an error.
+	OverwriteError
+	// OverwriteIgnore policy silently overwrites the registered bean.
+	OverwriteIgnore
+)
+
+var overwritePolicy = OverwriteWarn
+
+var nilBeansAllowed int32
+
+// SetNilBeansAllowed function defines whether beans can be nil. By default, registration of nil instances and nil
+// results of bean factories fail with an error, because such beans would only panic later, upon usage. Allow them if
+// nil beans are intentional (e.g. optional integrations): nil beans are neither initialized nor injected.
+func SetNilBeansAllowed(allowed bool) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if allowed {
+		atomic.StoreInt32(&nilBeansAllowed, 1)
+	} else {
+		atomic.StoreInt32(&nilBeansAllowed, 0)
+	}
+}
 
 // InitializingBean is an interface marking beans that need to be additionally initialized after the container is ready.
 type InitializingBean interface {
@@ -74,47 +142,138 @@ type InitializingBean interface {
 
 // ContextAwareBean is an interface marking beans that can accept context. Mostly meant to be used with Request-scoped
 // beans (HTTP request context will be propagated for them). For all other beans it's gonna be `context.Background()`.
+// Alternatively, the same context is injected into a field of type `context.Context` tagged with `di.inject:""`.
 type ContextAwareBean interface {
 	// SetContext method will be called on a bean after its creation.
 	SetContext(ctx context.Context)
 }
 
+// DependencyInjector is an interface marking beans that inject their dependencies themselves, without reflection
+// (usually it's implemented by the code generated with `dicodegen`). If a bean implements it, its `di.inject` and
+// `di.value` tags are not processed: `InjectDependencies` is called instead, receiving the function that resolves
+// dependencies by their IDs (with the same scope checks as for the regular injection).
+type DependencyInjector interface {
+	// InjectDependencies method will be called on a bean after its creation.
+	InjectDependencies(resolve func(beanID string) (interface{}, error)) error
+}
+
 func init() {
 	logrus.SetFormatter(&logrus.TextFormatter{})
 }
 
 // RegisterBeanPostprocessor function registers postprocessors for beans. Postprocessor is a function that can perform
 // some actions on beans after their creation by the container (and self-initialization with PostConstruct).
+// Postprocessors are registered with DefaultPriority and run in registration order.
 func RegisterBeanPostprocessor(beanType reflect.Type, postprocessor func(bean interface{}) error) error {
+	return RegisterBeanPostprocessorWithPriority(beanType, DefaultPriority, postprocessor)
+}
+
+// SetOverwritePolicy function sets the policy defining how the container reacts on registration of a bean with an ID
+// that is already registered.
+func SetOverwritePolicy(policy OverwritePolicy) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	overwritePolicy = policy
+}
+
+// RegisterDefaultBean function marks the bean with the given ID as a default implementation for the given type. Such
+// bean is injected into fields of this type tagged with `di.onMissing:"default"` when the dependency itself is not
+// found in the container. The bean should be registered in the container as well.
+func RegisterDefaultBean(beanType reflect.Type, beanID string) error {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
 	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
-		return errors.New("container is already initialized: can't register bean postprocessor")
+		return errors.New("container is already initialized: can't register default bean")
 	}
-	beanPostprocessors[beanType] = append(beanPostprocessors[beanType], postprocessor)
+	defaultBeans[beanType] = beanID
 	return nil
 }
 
-// InitializeContainer function initializes the IoC container.
+// containerInitialization is the initialization of the container in progress.
+type containerInitialization struct {
+	done chan struct{}
+	// joined is closed once some other InitializeContainer call starts waiting for this initialization.
+	joined     chan struct{}
+	joinedOnce sync.Once
+	err        error
+}
+
+var currentInitializationLock sync.Mutex
+var currentInitialization *containerInitialization
+
+// InitializeContainer function initializes the IoC container. Singletons are wired in two phases: first all of them
+// are created, then their dependencies are injected, so circular references between fields of Singleton beans are
+// tolerated (but not between Singleton beans created by factories, unless they're resolved with ResolveLate). Then
+// singletons are initialized. Like sync.Once, concurrent calls block until the initialization in progress completes
+// and return its result, while calls made after it has completed return the error.
 func InitializeContainer() error {
+	currentInitializationLock.Lock()
+	if initialization := currentInitialization; initialization != nil {
+		currentInitializationLock.Unlock()
+		initialization.joinedOnce.Do(func() {
+			close(initialization.joined)
+		})
+		<-initialization.done
+		return initialization.err
+	}
+	initialization := &containerInitialization{done: make(chan struct{}), joined: make(chan struct{})}
+	currentInitialization = initialization
+	currentInitializationLock.Unlock()
+	initialization.err = initializeContainer()
+	currentInitializationLock.Lock()
+	currentInitialization = nil
+	currentInitializationLock.Unlock()
+	close(initialization.done)
+	return initialization.err
+}
+
+func initializeContainer() error {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
 	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
 		return errors.New("container is already initialized: reinitialization is not supported")
 	}
-	err := createSingletonInstances()
+	start := time.Now()
+	err := postprocessBeanDefinitions()
 	if err != nil {
 		return err
 	}
+	invalidateInjectionPlans()
+	err = createSingletonInstances()
+	if err != nil {
+		return err
+	}
+	// types of the beans created by factories are known now, so plans built by the factories must be rebuilt
+	invalidateInjectionPlans()
 	err = injectSingletonDependencies()
 	if err != nil {
 		return err
 	}
+	err = bindLateDependencies()
+	if err != nil {
+		return err
+	}
 	atomic.StoreInt32(&containerInitialized, 1)
 	err = initializeSingletonInstances()
 	if err != nil {
 		return err
 	}
+	publishSingletons()
+	err = runInvokers()
+	if err != nil {
+		return err
+	}
+	err = startScheduler()
+	if err != nil {
+		return err
+	}
+	err = startWorkers()
+	if err != nil {
+		stopScheduler(true)
+		return err
+	}
+	completeStartupReport(start)
+	emitContainerEvent(ContainerEvent{Type: ContainerInitialized})
 	return nil
 }
 
@@ -125,68 +284,182 @@ func InitializeContainer() error {
 func RegisterBean(beanID string, beanType reflect.Type) (overwritten bool, err error) {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
-	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
-		return false, errors.New("container is already initialized: can't register new bean")
+	return registerBean(beanID, beanType, nil)
+}
+
+// RegisterBeanWithScope function registers bean by type, similar to RegisterBean, but with the given scope, which
+// overrides the `di.scope` tag (if any). This way the scope of third-party or generated types can be set without
+// changing them.
+func RegisterBeanWithScope(beanID string, beanType reflect.Type, beanScope Scope) (overwritten bool, err error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if !isSupportedScope(beanScope) {
+		return false, errors.New("unsupported scope: " + string(beanScope))
 	}
+	return registerBean(beanID, beanType, &beanScope)
+}
+
+// isSupportedScope function checks whether the scope is one of the supported ones.
+func isSupportedScope(beanScope Scope) bool {
+	switch beanScope {
+	case Singleton, Prototype, Request, Refresh, Pooled, Tenant:
+		return true
+	}
+	return false
+}
+
+// checkBeanType function checks the bean type (and its tags) before the registration. It returns the scope of the bean
+// (the passed one, or the one set by the `di.scope` tag), and `false` if the bean's profile is not active.
+func checkBeanType(beanID string, beanType reflect.Type, beanScope *Scope) (*Scope, bool, error) {
 	if beanType.Kind() != reflect.Ptr {
-		return false, errors.New("bean type must be a pointer")
+		return nil, false, errors.New("bean type must be a pointer")
 	}
-	var existingBeanType reflect.Type
-	var ok bool
-	if existingBeanType, ok = beans[beanID]; ok {
-		logrus.WithFields(logrus.Fields{
-			"id":              beanID,
-			"registered bean": existingBeanType,
-			"new bean":        beanType,
-		}).Warn(beanAlreadyRegistered)
+	if err := validateTags(beanID, beanType); err != nil {
+		return nil, false, err
 	}
-	beanScope, err := getScope(beanType)
+	if !isProfileActive(beanType) {
+		return nil, false, nil
+	}
+	if beanScope == nil {
+		var err error
+		beanScope, err = getScope(beanType)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	fields, err := injectableFields(beanType.Elem())
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
-	beanTypeElement := beanType.Elem()
-	for i := 0; i < beanTypeElement.NumField(); i++ {
-		field := beanTypeElement.Field(i)
-		if _, ok := field.Tag.Lookup(string(inject)); !ok {
+	for _, field := range fields {
+		if _, ok := value.lookup(field); ok {
+			if !isValueKindSupported(field.Type) {
+				return nil, false, errors.New(unsupportedValueType)
+			}
+			continue
+		}
+		if _, ok := injectGroup.lookup(field); ok {
+			if (field.Type.Kind() != reflect.Slice && field.Type.Kind() != reflect.Map) ||
+				(field.Type.Elem().Kind() != reflect.Ptr && field.Type.Elem().Kind() != reflect.Interface) {
+				return nil, false, errors.New(unsupportedDependencyType)
+			}
 			continue
 		}
-		if field.Type.Kind() != reflect.Ptr && field.Type.Kind() != reflect.Interface &&
-			field.Type.Kind() != reflect.Slice && field.Type.Kind() != reflect.Map {
-			return false, errors.New(unsupportedDependencyType)
+		beanToInject, ok := inject.lookup(field)
+		if !ok {
+			continue
 		}
+		if isValueKindSupported(field.Type) && beanToInject != "" { // primitive values are injected by ID only
+			continue
+		}
+		if field.Type.Kind() != reflect.Ptr && field.Type.Kind() != reflect.Interface && field.Type.Kind() != reflect.Struct &&
+			field.Type.Kind() != reflect.Slice && field.Type.Kind() != reflect.Map && field.Type.Kind() != reflect.Func &&
+			field.Type.Kind() != reflect.Chan {
+			return nil, false, errors.New(unsupportedDependencyType)
+		}
+	}
+	return beanScope, true, nil
+}
+
+// registerBean function registers bean by type. If `beanScope` is nil, the scope is taken from the `di.scope` tag.
+func registerBean(beanID string, beanType reflect.Type, beanScope *Scope) (overwritten bool, err error) {
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean")
+	}
+	beanScope, active, err := checkBeanType(beanID, beanType, beanScope)
+	if err != nil {
+		return false, err
+	}
+	if !active {
+		logrus.WithField("id", beanID).Trace("bean profile is not active, skipping registration")
+		return false, nil
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{"new bean": beanType})
+	if err != nil {
+		return false, err
 	}
+	unregisterBean(beanID)
 	beans[beanID] = beanType
 	scopes[beanID] = *beanScope
-	return ok, nil
+	for _, group := range getGroups(beanType) {
+		addToGroup(group, beanID)
+	}
+	if isPrimary(beanType) {
+		primaryBeans[beanID] = true
+	}
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
 }
 
 // RegisterBeanInstance function registers bean, provided the pre-created instance of this bean, the scope of such beans
-// are always `Singleton`. `beanInstance` can only be a reference or an interface. Return value of `overwritten` is set
-// to `true` if the bean with the same `beanID` has been registered already.
+// are always `Singleton`. `beanInstance` can only be a reference, an interface, a function (see isFunctionBean) or a
+// channel (e.g. shared by the producer and the consumer beans). Return value of `overwritten` is set to `true` if the
+// bean with the same `beanID` has been registered already.
 func RegisterBeanInstance(beanID string, beanInstance interface{}) (overwritten bool, err error) {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
+	return registerBeanInstance(beanID, beanInstance)
+}
+
+func registerBeanInstance(beanID string, beanInstance interface{}) (overwritten bool, err error) {
 	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
 		return false, errors.New("container is already initialized: can't register new bean")
 	}
 	beanType := reflect.TypeOf(beanInstance)
-	if beanType.Kind() != reflect.Ptr {
-		return false, errors.New("bean instance must be a pointer")
+	if beanType == nil {
+		return false, errors.New("bean instance can't be nil")
 	}
-	var existingBeanType reflect.Type
-	var ok bool
-	if existingBeanType, ok = beans[beanID]; ok {
-		logrus.WithFields(logrus.Fields{
-			"id":                beanID,
-			"registered bean":   existingBeanType,
-			"new bean instance": beanType,
-		}).Warn(beanAlreadyRegistered)
+	if beanType.Kind() != reflect.Ptr && beanType.Kind() != reflect.Func && beanType.Kind() != reflect.Chan {
+		return false, errors.New("bean instance must be a pointer, a function or a channel")
+	}
+	if isNilBean(beanInstance) && atomic.LoadInt32(&nilBeansAllowed) == 0 {
+		return false, errors.New("bean instance is a nil pointer: " + beanID)
+	}
+	if !isProfileActive(beanType) {
+		logrus.WithField("id", beanID).Trace("bean profile is not active, skipping registration")
+		return false, nil
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{"new bean instance": beanType})
+	if err != nil {
+		return false, err
 	}
+	unregisterBean(beanID)
 	beans[beanID] = beanType
 	scopes[beanID] = Singleton
 	singletonInstances[beanID] = beanInstance
 	userCreatedInstances[beanID] = true
-	return ok, nil
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
+}
+
+// RegisterBeanValue function registers bean, provided the struct value (not a pointer), e.g. an immutable
+// configuration, or the value of a primitive type (string, bool, numeric or time.Duration), e.g. a configuration value.
+// The scope of such beans is always `Singleton`, but every lookup and injection gets its own copy of the value, so the
+// bean can't be modified. Struct values can only be injected into fields of the same struct type (by ID or by type) or
+// of interfaces the struct implements with value receivers. Primitive values can only be injected by ID, into fields of
+// the same type. Return value of `overwritten` is set to `true` if the bean with the same `beanID` has been registered
+// already.
+func RegisterBeanValue(beanID string, beanValue interface{}) (overwritten bool, err error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean")
+	}
+	beanType := reflect.TypeOf(beanValue)
+	if beanType == nil || (beanType.Kind() != reflect.Struct && !isValueKindSupported(beanType)) {
+		return false, errors.New("bean value must be a struct or a primitive: " + beanID)
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{"new bean value": beanType})
+	if err != nil {
+		return false, err
+	}
+	unregisterBean(beanID)
+	beans[beanID] = beanType
+	scopes[beanID] = Singleton
+	singletonInstances[beanID] = beanValue
+	userCreatedInstances[beanID] = true
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
 }
 
 // RegisterBeanFactory function registers bean, provided the bean factory that will be used by the container in order to
@@ -196,20 +469,59 @@ func RegisterBeanInstance(beanID string, beanInstance interface{}) (overwritten
 func RegisterBeanFactory(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, error)) (overwritten bool, err error) {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
+	return registerBeanFactory(beanID, beanScope, beanFactory)
+}
+
+func registerBeanFactory(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, error)) (overwritten bool, err error) {
 	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
 		return false, errors.New("container is already initialized: can't register new bean factory")
 	}
-	var existingBeanType reflect.Type
-	var ok bool
-	if existingBeanType, ok = beans[beanID]; ok {
-		logrus.WithFields(logrus.Fields{
-			"id":              beanID,
-			"registered bean": existingBeanType,
-		}).Warn(beanAlreadyRegistered)
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{})
+	if err != nil {
+		return false, err
 	}
+	unregisterBean(beanID)
 	scopes[beanID] = beanScope
 	beanFactories[beanID] = beanFactory
-	return ok, nil
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
+}
+
+// checkOverwriting function checks whether the bean with such ID is registered already and acts according to the
+// overwrite policy.
+func checkOverwriting(beanID string, fields logrus.Fields) (bool, error) {
+	if !isBeanRegistered(beanID) {
+		return false, nil
+	}
+	switch overwritePolicy {
+	case OverwriteError:
+		return false, errors.New("bean with such ID is already registered: " + beanID)
+	case OverwriteWarn:
+		fields["id"] = beanID
+		fields["registered bean"] = beans[beanID]
+		logrus.WithFields(fields).Warn(beanAlreadyRegistered)
+	}
+	return true, nil
+}
+
+// unregisterBean function removes all the traces of the bean definition from the container.
+func unregisterBean(beanID string) {
+	delete(beans, beanID)
+	delete(beanFactories, beanID)
+	delete(scopes, beanID)
+	delete(singletonInstances, beanID)
+	delete(userCreatedInstances, beanID)
+	delete(beanModules, beanID)
+	delete(beanGroups, beanID)
+	delete(resolvingBeanFactories, beanID)
+	delete(factoryBeanTypes, beanID)
+	delete(sampledBeanTypes, beanID)
+	delete(primaryBeans, beanID)
+	delete(lazyBeans, beanID)
+	delete(beanDependencies, beanID)
+	delete(beanQualifiers, beanID)
+	delete(beanDecorators, beanID)
+	invalidateInjectionPlans()
 }
 
 func getScope(bean reflect.Type) (*Scope, error) {
@@ -218,7 +530,7 @@ func getScope(bean reflect.Type) (*Scope, error) {
 	beanElement := bean.Elem()
 	for i := 0; i < beanElement.NumField(); i++ {
 		field := beanElement.Field(i)
-		beanScope, ok = field.Tag.Lookup(string(scope))
+		beanScope, ok = scope.lookup(field)
 		if ok {
 			break
 		}
@@ -226,6 +538,9 @@ func getScope(bean reflect.Type) (*Scope, error) {
 	singleton := Singleton
 	prototype := Prototype
 	request := Request
+	refresh := Refresh
+	pooled := Pooled
+	tenant := Tenant
 	if !ok {
 		return &singleton, nil
 	}
@@ -236,6 +551,12 @@ func getScope(bean reflect.Type) (*Scope, error) {
 		return &prototype, nil
 	case string(Request):
 		return &request, nil
+	case string(Refresh):
+		return &refresh, nil
+	case string(Pooled):
+		return &pooled, nil
+	case string(Tenant):
+		return &tenant, nil
 	}
 	return nil, errors.New("unsupported scope: " + beanScope)
 }
@@ -248,7 +569,7 @@ func injectSingletonDependencies() error {
 		if _, ok := beanFactories[beanID]; ok {
 			continue
 		}
-		err := injectDependencies(beanID, instance, make(map[string]bool))
+		err := injectDependencies(context.Background(), beanID, instance, newDependencyChain())
 		if err != nil {
 			return err
 		}
@@ -256,111 +577,56 @@ func injectSingletonDependencies() error {
 	return nil
 }
 
-func injectDependencies(beanID string, instance interface{}, chain map[string]bool) error {
-	logrus.WithField("beanID", beanID).Trace("injecting dependencies")
-	instanceType := beans[beanID]
-	instanceElement := instanceType.Elem()
-	for i := 0; i < instanceElement.NumField(); i++ {
-		field := instanceElement.Field(i)
-		beanToInject, ok := field.Tag.Lookup(string(inject))
-		if !ok {
-			continue
-		}
-		optionalDependency, err := isOptional(field)
-		if err != nil {
-			return err
-		}
-		fieldToInject := reflect.ValueOf(instance).Elem().Field(i)
-		fieldToInject = reflect.NewAt(fieldToInject.Type(), unsafe.Pointer(fieldToInject.UnsafeAddr())).Elem()
-		switch fieldToInject.Kind() {
-		case reflect.Ptr, reflect.Interface:
-			if beanToInject == "" { // injecting by type, gotta find the candidate first
-				candidates := findInjectionCandidates(fieldToInject.Type())
-				if len(candidates) < 1 {
-					if optionalDependency {
-						continue
-					}
-					return errors.New("no candidates found for the injection")
-				}
-				if len(candidates) > 1 {
-					return errors.New("more then one candidate found for the injection")
-				}
-				beanToInject = candidates[0]
-			}
-			beanToInjectType := beans[beanToInject]
-			logInjection(beanID, instanceElement, beanToInject, beanToInjectType)
-			beanScope, beanFound := scopes[beanToInject]
-			if !beanFound {
-				if optionalDependency {
-					logrus.Trace("no dependency found, injecting nil since the dependency marked as optional")
-					continue
-				}
-				return errors.New("no dependency found")
-			}
-			if beanScope == Request {
-				return errors.New(requestScopedBeansCantBeInjected)
-			}
-			instanceToInject, err := getInstance(context.Background(), beanToInject, chain)
-			if err != nil {
-				return err
-			}
-			fieldToInject.Set(reflect.ValueOf(instanceToInject))
-		case reflect.Slice:
-			if fieldToInject.Type().Elem().Kind() != reflect.Ptr && fieldToInject.Type().Elem().Kind() != reflect.Interface {
-				return errors.New(unsupportedDependencyType)
-			}
-			candidates := findInjectionCandidates(fieldToInject.Type().Elem())
-			if len(candidates) < 1 {
-				if !optionalDependency {
-					fieldToInject.Set(reflect.MakeSlice(fieldToInject.Type(), 0, 0))
-				}
-				continue
-			}
-			fieldToInject.Set(reflect.MakeSlice(fieldToInject.Type(), len(candidates), len(candidates)))
-			for i, beanToInject := range candidates {
-				beanToInjectType := beans[beanToInject]
-				logInjection(beanID, instanceElement, beanToInject, beanToInjectType)
-				if scopes[beanToInject] == Request {
-					return errors.New(requestScopedBeansCantBeInjected)
-				}
-				instanceToInject, err := getInstance(context.Background(), beanToInject, chain)
-				if err != nil {
-					return err
-				}
-				fieldToInject.Index(i).Set(reflect.ValueOf(instanceToInject))
-			}
-		case reflect.Map:
-			if fieldToInject.Type().Elem().Kind() != reflect.Ptr && fieldToInject.Type().Elem().Kind() != reflect.Interface {
-				return errors.New(unsupportedDependencyType)
-			}
-			candidates := findInjectionCandidates(fieldToInject.Type().Elem())
-			if len(candidates) < 1 {
-				if !optionalDependency {
-					fieldToInject.Set(reflect.MakeMap(fieldToInject.Type()))
-				}
-				continue
-			}
-			fieldToInject.Set(reflect.MakeMap(fieldToInject.Type()))
-			for _, beanToInject := range candidates {
-				beanToInjectType := beans[beanToInject]
-				logInjection(beanID, instanceElement, beanToInject, beanToInjectType)
-				if scopes[beanToInject] == Request {
-					return errors.New(requestScopedBeansCantBeInjected)
-				}
-				instanceToInject, err := getInstance(context.Background(), beanToInject, chain)
-				if err != nil {
-					return err
-				}
-				fieldToInject.SetMapIndex(reflect.ValueOf(beanToInject), reflect.ValueOf(instanceToInject))
+func injectDependencies(ctx context.Context, beanID string, instance interface{}, chain *dependencyChain) error {
+	if isTracing() {
+		logrus.WithField("beanID", beanID).Trace("injecting dependencies")
+	}
+	if injector, ok := instance.(DependencyInjector); ok {
+		return injector.InjectDependencies(func(dependencyID string) (interface{}, error) {
+			dependencyID = resolveAlias(dependencyID)
+			if !isBeanRegistered(dependencyID) {
+				return nil, errors.New("no dependency found: " + dependencyID)
 			}
-		default:
-			return errors.New(unsupportedDependencyType)
+			return getDependencyInstance(ctx, beanID, dependencyID, chain)
+		})
+	}
+	return getInjectionPlan(beanID).execute(ctx, beanID, instance, chain)
+}
+
+// getDependencyInstance function returns an instance of the dependency to be injected into the bean. Newly created
+// dependencies receive the context of the bean being injected. Request-scoped dependencies are only available for
+// Request-scoped beans: they are taken from the request scope of the context, so that all beans of one request share
+// the same instance.
+func getDependencyInstance(ctx context.Context, beanID string, dependencyID string, chain *dependencyChain) (interface{}, error) {
+	if scopes[dependencyID] == Refresh && scopes[beanID] == Singleton {
+		return nil, errors.New("refresh-scoped beans can't be injected into singletons directly: use di.Refreshable instead")
+	}
+	if scopes[dependencyID] == Tenant && (scopes[beanID] == Singleton || scopes[beanID] == Refresh) {
+		return nil, errors.New("tenant-scoped beans can't be injected into singletons: retrieve them with GetInstanceCtx instead")
+	}
+	if scopes[dependencyID] == Pooled {
+		if err := checkPooledDependency(beanID); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	if scopes[dependencyID] != Request {
+		return getInstance(ctx, dependencyID, chain)
+	}
+	if scopes[beanID] != Request {
+		return nil, errors.New(requestScopedBeansCantBeInjected)
+	}
+	requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext)
+	if !ok {
+		return nil, errors.New(requestScopedBeansCantBeInjected)
+	}
+	return requestContext.scope.getInstance(dependencyID, chain)
 }
 
-func logInjection(beanID string, instanceElement reflect.Type, beanToInject string, beanToInjectType reflect.Type) {
+func logInjection(beanID string, instanceElement reflect.Type, beanToInject string) {
+	if !isTracing() {
+		return
+	}
+	beanToInjectType, _ := getBeanType(beanToInject)
 	logrus.WithFields(logrus.Fields{
 		"bean":               beanID,
 		"beanType":           instanceElement,
@@ -370,7 +636,7 @@ func logInjection(beanID string, instanceElement reflect.Type, beanToInject stri
 }
 
 func isOptional(field reflect.StructField) (bool, error) {
-	optionalTag := field.Tag.Get(string(optional))
+	optionalTag := optional.get(field)
 	value, err := strconv.ParseBool(optionalTag)
 	if optionalTag != "" && err != nil {
 		return false, errors.New("invalid di.optional value: " + optionalTag)
@@ -378,74 +644,225 @@ func isOptional(field reflect.StructField) (bool, error) {
 	return value, nil
 }
 
+// isSelfIncluded function checks if the bean should be among the candidates for the injection into its own field (which
+// is not the case by default, so that e.g. a decorator injecting all implementations of its interface doesn't receive
+// itself).
+func isSelfIncluded(field reflect.StructField) (bool, error) {
+	includeSelfTag := includeSelf.get(field)
+	value, err := strconv.ParseBool(includeSelfTag)
+	if includeSelfTag != "" && err != nil {
+		return false, errors.New("invalid di.includeSelf value: " + includeSelfTag)
+	}
+	return value, nil
+}
+
+// excludeCandidates function removes beans listed in the `di.exclude` tag of the field (comma-separated IDs or aliases)
+// from the candidates for the collection injection.
+func excludeCandidates(field reflect.StructField, candidates []string) []string {
+	excludeTag, ok := exclude.lookup(field)
+	if !ok {
+		return candidates
+	}
+	excluded := make(map[string]bool)
+	for _, beanID := range strings.Split(excludeTag, ",") {
+		excluded[resolveAlias(strings.TrimSpace(beanID))] = true
+	}
+	filtered := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !excluded[candidate] {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+func getOnMissingPolicy(field reflect.StructField) (onMissingPolicy, error) {
+	optionalDependency, err := isOptional(field)
+	if err != nil {
+		return "", err
+	}
+	onMissingTag, ok := onMissing.lookup(field)
+	if !ok {
+		if optionalDependency {
+			return onMissingNil, nil
+		}
+		return onMissingError, nil
+	}
+	if _, ok := optional.lookup(field); ok {
+		return "", errors.New("di.optional and di.onMissing can't be used together")
+	}
+	switch policy := onMissingPolicy(onMissingTag); policy {
+	case onMissingNil, onMissingDefault, onMissingError:
+		return policy, nil
+	}
+	return "", errors.New("invalid di.onMissing value: " + onMissingTag)
+}
+
 func findInjectionCandidates(fieldToInjectType reflect.Type) []string {
 	var candidates []string
-	for beanID, beanType := range beans {
-		if beanType.AssignableTo(fieldToInjectType) {
-			candidates = append(candidates, beanID)
+	for _, beanTypes := range []map[string]reflect.Type{beans, factoryBeanTypes, sampledBeanTypes} {
+		for beanID, beanType := range beanTypes {
+			if beanType.AssignableTo(fieldToInjectType) && !isValueKindSupported(beanType) {
+				candidates = append(candidates, beanID)
+			}
 		}
 	}
 	return candidates
 }
 
+// findInjectionCandidatesFor function finds candidates for the injection into the field of the bean. The bean itself
+// is excluded from the candidates unless the field is tagged with `di.includeSelf:"true"`.
+func findInjectionCandidatesFor(beanID string, field reflect.StructField,
+	fieldToInjectType reflect.Type) ([]string, error) {
+	selfIncluded, err := isSelfIncluded(field)
+	if err != nil {
+		return nil, err
+	}
+	candidates := findInjectionCandidates(fieldToInjectType)
+	if selfIncluded {
+		return candidates, nil
+	}
+	for i, candidate := range candidates {
+		if candidate == beanID {
+			return append(candidates[:i], candidates[i+1:]...), nil
+		}
+	}
+	return candidates, nil
+}
+
 func createSingletonInstances() error {
 	for beanID := range beans {
-		if scopes[beanID] != Singleton {
+		if !isEagerSingleton(beanID) {
 			continue
 		}
 		if _, ok := userCreatedInstances[beanID]; ok {
 			continue
 		}
-		instance, err := createInstance(context.Background(), beanID)
+		start := time.Now()
+		instance, err := createInstance(context.Background(), beanID, nil)
 		if err != nil {
 			return err
 		}
+		recordCreation(beanID, start)
 		singletonInstances[beanID] = instance
 		logrus.WithFields(logrus.Fields{
 			"beanID": beanID,
 			"scope":  scopes[beanID],
 		}).Trace("singleton instance created")
 	}
-	for beanID, beanFactory := range beanFactories {
-		if scopes[beanID] != Singleton {
+	for beanID := range beanFactories {
+		if !isEagerSingleton(beanID) {
 			continue
 		}
-		beanInstance, err := beanFactory(context.Background())
-		if err != nil {
-			return err
+		if _, created := singletonInstances[beanID]; created { // already created as a dependency of another factory
+			continue
 		}
-		if reflect.TypeOf(beanInstance).Kind() != reflect.Ptr {
-			return errors.New("bean factory must return pointer")
+		if err := createSingletonFactoryInstance(beanID, newDependencyChain()); err != nil {
+			return err
 		}
-		singletonInstances[beanID] = beanInstance
-		logrus.WithFields(logrus.Fields{
-			"beanID": beanID,
-			"scope":  scopes[beanID],
-		}).Trace("singleton instance created")
 	}
 	return nil
 }
 
-func createInstance(ctx context.Context, beanID string) (interface{}, error) {
-	createInstanceLock.Lock()
-	defer createInstanceLock.Unlock()
-	if beanFactory, ok := beanFactories[beanID]; ok {
-		beanInstance, err := beanFactory(ctx)
+func createSingletonFactoryInstance(beanID string, chain *dependencyChain) error {
+	if chain.contains(beanID) {
+		return chain.circularDependencyError(beanID)
+	}
+	chain.push(beanID)
+	defer chain.pop()
+	if err := instantiateDependencies(context.Background(), beanID, chain); err != nil {
+		return err
+	}
+	start := time.Now()
+	beanInstance, err := callBeanFactory(context.Background(), beanID, chain)
+	if err != nil {
+		return err
+	}
+	recordCreation(beanID, start)
+	if err := checkFactoryResult(beanID, beanInstance); err != nil {
+		return err
+	}
+	emitContainerEvent(ContainerEvent{Type: BeanCreated, BeanID: beanID, Bean: beanInstance})
+	singletonInstances[beanID] = beanInstance
+	sampleBeanType(beanID, beanInstance)
+	logrus.WithFields(logrus.Fields{
+		"beanID": beanID,
+		"scope":  scopes[beanID],
+	}).Trace("singleton instance created")
+	return nil
+}
+
+// createInstance function creates a new instance of the bean. It doesn't need to be synchronized: after the container
+// initialization bean definitions are read-only, so Prototype and Request beans can be created concurrently.
+func createInstance(ctx context.Context, beanID string, chain *dependencyChain) (interface{}, error) {
+	if _, ok := beanFactories[beanID]; ok {
+		beanInstance, err := callBeanFactory(ctx, beanID, chain)
 		if err != nil {
 			return nil, err
 		}
-		if reflect.TypeOf(beanInstance).Kind() != reflect.Ptr {
-			return nil, errors.New("bean factory must return pointer")
+		if err := checkFactoryResult(beanID, beanInstance); err != nil {
+			return nil, err
 		}
+		emitContainerEvent(ContainerEvent{Type: BeanCreated, BeanID: beanID, Bean: beanInstance})
 		return beanInstance, nil
 	}
-	logrus.WithField("beanID", beanID).Trace("creating instance")
-	return reflect.New(beans[beanID].Elem()).Interface(), nil
+	if isTracing() {
+		logrus.WithField("beanID", beanID).Trace("creating instance")
+	}
+	beanInstance := reflect.New(beans[beanID].Elem()).Interface()
+	emitContainerEvent(ContainerEvent{Type: BeanCreated, BeanID: beanID, Bean: beanInstance})
+	return beanInstance, nil
+}
+
+// checkFactoryResult function validates the instance returned by the bean factory: it must be a non-nil pointer (or a
+// function, or a channel), unless nil beans are allowed (see SetNilBeansAllowed).
+func checkFactoryResult(beanID string, beanInstance interface{}) error {
+	if isNilBean(beanInstance) {
+		if atomic.LoadInt32(&nilBeansAllowed) == 1 {
+			return nil
+		}
+		return errors.New("bean factory returned nil: " + beanID)
+	}
+	if kind := reflect.TypeOf(beanInstance).Kind(); kind != reflect.Ptr && kind != reflect.Func && kind != reflect.Chan {
+		return errors.New("bean factory must return pointer, function or channel")
+	}
+	return nil
+}
+
+func isNilBean(beanInstance interface{}) bool {
+	if beanInstance == nil {
+		return true
+	}
+	beanValue := reflect.ValueOf(beanInstance)
+	switch beanValue.Kind() {
+	case reflect.Ptr, reflect.Func, reflect.Chan:
+		return beanValue.IsNil()
+	}
+	return false
 }
 
 func initializeSingletonInstances() error {
-	for beanID, instance := range singletonInstances {
-		err := initializeInstance(beanID, instance)
+	instances := make(map[string]interface{}, len(singletonInstances))
+	replacements := make(map[interface{}]interface{})
+	beanIDs := make([]string, 0, len(singletonInstances))
+	for beanID := range singletonInstances {
+		beanIDs = append(beanIDs, beanID)
+	}
+	beanIDs, err := orderByDependencies(beanIDs)
+	if err != nil {
+		return err
+	}
+	for _, beanID := range beanIDs {
+		instance := singletonInstances[beanID]
+		instances[beanID] = instance
+		if isNilBean(instance) || isFunctionBean(instance) {
+			continue
+		}
+		if err := instantiateDependencies(context.Background(), beanID, newDependencyChain(beanID)); err != nil {
+			return err
+		}
+		start := time.Now()
+		postprocessedInstance, err := initializeInstance(beanID, instance)
 		if err != nil {
 			return err
 		}
@@ -453,43 +870,186 @@ func initializeSingletonInstances() error {
 		if err != nil {
 			return err
 		}
+		recordInitialization(beanID, start)
+		// singleton instances are pointers or channels here, so the comparison (and the key) is safe even if the
+		// postprocessor returned an unhashable value
+		if postprocessedInstance != instance {
+			singletonInstances[beanID] = postprocessedInstance
+			replacements[instance] = postprocessedInstance
+		}
+		emitContainerEvent(ContainerEvent{Type: BeanInitialized, BeanID: beanID, Bean: postprocessedInstance})
 	}
-	return nil
+	return replaceInjectedInstances(instances, replacements)
+}
+
+// callPostConstruct function calls PostConstruct of the bean, converting the panic to the error.
+func callPostConstruct(beanID string, bean InitializingBean) (err error) {
+	defer recoverBeanPanic(beanID, "PostConstruct", &err)
+	return bean.PostConstruct()
 }
 
-func initializeInstance(beanID string, instance interface{}) error {
+// recoverBeanPanic function recovers the panic in the given phase of the bean creation and sets it as the error, so
+// that it's reported along with the bean ID (instead of crashing the container initialization or the web request). It
+// must be deferred directly.
+func recoverBeanPanic(beanID string, phase string, err *error) {
+	if value := recover(); value != nil {
+		*err = &BeanPanicError{BeanID: beanID, Phase: phase, Value: value, Stack: string(debug.Stack())}
+	}
+}
+
+// initializeInstance function calls PostConstruct and postprocessors of the bean and validates it, returning the
+// instance to be used instead of the bean (postprocessors and decorators can replace it, and interceptors wrap it in
+// the proxy).
+func initializeInstance(beanID string, instance interface{}) (interface{}, error) {
+	setAwareness(beanID, instance)
 	if impl, ok := instance.(InitializingBean); ok {
-		logrus.WithField("beanID", beanID).Trace("initializing bean")
-		if err := impl.PostConstruct(); err != nil {
-			return err
+		if isTracing() {
+			logrus.WithField("beanID", beanID).Trace("initializing bean")
+		}
+		if err := callPostConstruct(beanID, impl); err != nil {
+			return nil, err
 		}
 	}
-	bean := reflect.TypeOf(instance)
-	if postprocessors, ok := beanPostprocessors[bean]; ok {
-		logrus.WithField("beanID", beanID).Trace("postprocessing bean")
+	if postprocessors := getBeanPostprocessors(reflect.TypeOf(instance)); len(postprocessors) > 0 {
+		if isTracing() {
+			logrus.WithField("beanID", beanID).Trace("postprocessing bean")
+		}
 		for _, postprocessor := range postprocessors {
-			if err := postprocessor(instance); err != nil {
-				return err
+			postprocessedInstance, err := postprocessor.postprocess(beanID, instance)
+			if err != nil {
+				return nil, err
+			}
+			if postprocessedInstance == nil {
+				return nil, errors.New("postprocessor returned nil instance of bean: " + beanID)
 			}
+			instance = postprocessedInstance
 		}
 	}
-	return nil
+	if err := validateBean(beanID, instance); err != nil {
+		return nil, err
+	}
+	instance, err := decorateBean(beanID, instance)
+	if err != nil {
+		return nil, err
+	}
+	return interceptBean(beanID, instance)
 }
 
 func setContext(ctx context.Context, beanID string, instance interface{}) error {
-	contextAwareBean := reflect.TypeOf((*ContextAwareBean)(nil)).Elem()
-	bean := reflect.TypeOf(instance)
-	if bean.Implements(contextAwareBean) {
-		setContextMethod, ok := bean.MethodByName(contextAwareBean.Method(0).Name)
-		if !ok {
-			return errors.New("unexpected behavior: can't find method SetContext() in bean " + bean.String())
+	if contextAwareBean, ok := instance.(ContextAwareBean); ok {
+		if isTracing() {
+			logrus.WithField("beanID", beanID).WithField("context", ctx).Trace("setting context to bean")
 		}
-		logrus.WithField("beanID", beanID).WithField("context", ctx).Trace("setting context to bean")
-		setContextMethod.Func.Call([]reflect.Value{reflect.ValueOf(instance), reflect.ValueOf(ctx)})
+		contextAwareBean.SetContext(ctx)
 	}
 	return nil
 }
 
+// isTracing function checks if the trace logging is enabled, so that hot paths don't build log entries in vain.
+func isTracing() bool {
+	return logrus.IsLevelEnabled(logrus.TraceLevel)
+}
+
+// dependencyChain tracks the chain of beans being created (to detect circular dependencies), along with the fields
+// their dependencies are being injected into, so that the whole cycle can be reported.
+type dependencyChain struct {
+	links []dependencyLink
+}
+
+type dependencyLink struct {
+	beanID string
+	field  string
+}
+
+func newDependencyChain(beanIDs ...string) *dependencyChain {
+	chain := &dependencyChain{}
+	for _, beanID := range beanIDs {
+		chain.push(beanID)
+	}
+	return chain
+}
+
+func (chain *dependencyChain) contains(beanID string) bool {
+	return chain.indexOf(beanID) >= 0
+}
+
+func (chain *dependencyChain) indexOf(beanID string) int {
+	for i := len(chain.links) - 1; i >= 0; i-- {
+		if chain.links[i].beanID == beanID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (chain *dependencyChain) push(beanID string) {
+	chain.links = append(chain.links, dependencyLink{beanID: beanID})
+}
+
+func (chain *dependencyChain) pop() {
+	chain.links = chain.links[:len(chain.links)-1]
+}
+
+// via method records the field of the bean in the chain its next dependency is being injected into. It's a no-op for
+// the beans that are not in the chain (e.g. singletons being injected upon the container initialization).
+func (chain *dependencyChain) via(beanID string, field string) {
+	if i := chain.indexOf(beanID); i >= 0 {
+		chain.links[i].field = field
+	}
+}
+
+func (chain *dependencyChain) circularDependencyError(beanID string) error {
+	return &CircularDependencyError{BeanID: beanID, Chain: chain.describe(chain.indexOf(beanID), beanID)}
+}
+
+func (chain *dependencyChain) resolutionDepthError(beanID string, maxDepth int) error {
+	return &ResolutionDepthError{BeanID: beanID, MaxDepth: maxDepth, Chain: chain.describe(0, beanID)}
+}
+
+// describe method formats the links of the chain starting from the given index, followed by the bean being resolved.
+func (chain *dependencyChain) describe(from int, beanID string) []string {
+	var description []string
+	for _, link := range chain.links[from:] {
+		if link.field == "" {
+			description = append(description, link.beanID)
+		} else {
+			description = append(description, link.beanID+"."+link.field)
+		}
+	}
+	return append(description, beanID)
+}
+
+// DefaultMaxResolutionDepth is the default maximum length of the chain of beans being created, see
+// SetMaxResolutionDepth.
+const DefaultMaxResolutionDepth = 1000
+
+var maxResolutionDepth int32 = DefaultMaxResolutionDepth
+
+// SetMaxResolutionDepth function limits the length of the chain of beans being created at once (e.g. a Prototype bean
+// depending on another Prototype bean and so on), so that pathological dependency graphs fail with
+// ResolutionDepthError, listing the chain, rather than exhaust the stack. Non-positive depth restores
+// DefaultMaxResolutionDepth.
+func SetMaxResolutionDepth(depth int) {
+	if depth <= 0 || depth > math.MaxInt32 {
+		depth = DefaultMaxResolutionDepth
+	}
+	atomic.StoreInt32(&maxResolutionDepth, int32(depth))
+}
+
+// chainPool reuses chains of beans being created: the chain is empty once the lookup is finished, so there's no need
+// to allocate a new one for each lookup.
+var chainPool = sync.Pool{New: func() interface{} { return &dependencyChain{} }}
+
+func acquireChain() *dependencyChain {
+	return chainPool.Get().(*dependencyChain)
+}
+
+func releaseChain(chain *dependencyChain) {
+	if len(chain.links) == 0 {
+		chainPool.Put(chain)
+	}
+}
+
 // GetInstance function returns bean instance by its ID. It may panic, so if receiving the error in return is preferred,
 // consider using `GetInstanceSafe`.
 func GetInstance(beanID string) interface{} {
@@ -503,26 +1063,127 @@ func GetInstance(beanID string) interface{} {
 // GetInstanceSafe function returns bean instance by its ID. It doesnt panic upon explicit error, but returns the error
 // instead.
 func GetInstanceSafe(beanID string) (interface{}, error) {
+	return GetInstanceSafeCtx(context.Background(), beanID)
+}
+
+// TryGetInstance function returns bean instance by its ID. The returned boolean is `false` if the bean can't be looked
+// up: there's no such bean registered, the container is not initialized yet, or the bean can't be created (the error is
+// logged), so that optional lookups never panic and don't have to inspect the error. Use `GetInstanceSafe` to receive
+// the error instead.
+func TryGetInstance(beanID string) (interface{}, bool) {
+	if singletons := initializedSingletons.Load(); singletons != nil {
+		if beanInstance, ok := singletons.lookup(beanID); ok {
+			return beanInstance, true
+		}
+	}
+	if !atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) || !isBeanRegistered(resolveAlias(beanID)) {
+		return nil, false
+	}
+	beanInstance, err := GetInstanceSafe(beanID)
+	if err != nil {
+		logrus.WithField("beanID", beanID).Error(err)
+		return nil, false
+	}
+	return beanInstance, true
+}
+
+// GetInstanceOrDefault function returns bean instance by its ID, or `fallback` if the bean can't be looked up (see
+// TryGetInstance).
+func GetInstanceOrDefault(beanID string, fallback interface{}) interface{} {
+	if beanInstance, ok := TryGetInstance(beanID); ok {
+		return beanInstance
+	}
+	return fallback
+}
+
+// GetInstanceCtx function returns bean instance by its ID. Unlike `GetInstance`, the passed context is propagated to
+// the bean factories and ContextAwareBean-s of newly created (i.e. non-Singleton) beans. It may panic, so if receiving
+// the error in return is preferred, consider using `GetInstanceSafeCtx`.
+func GetInstanceCtx(ctx context.Context, beanID string) interface{} {
+	beanInstance, err := GetInstanceSafeCtx(ctx, beanID)
+	if err != nil {
+		panic(err)
+	}
+	return beanInstance
+}
+
+// GetInstanceSafeCtx function returns bean instance by its ID. Unlike `GetInstanceSafe`, the passed context is
+// propagated to the bean factories and ContextAwareBean-s of newly created (i.e. non-Singleton) beans. It doesnt panic
+// upon explicit error, but returns the error instead.
+func GetInstanceSafeCtx(ctx context.Context, beanID string) (interface{}, error) {
+	if singletons := initializedSingletons.Load(); singletons != nil {
+		if beanInstance, ok := singletons.lookup(beanID); ok {
+			return beanInstance, nil
+		}
+	}
 	if atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
 		return nil, errors.New("container is not initialized: can't lookup instances of beans yet")
 	}
+	beanID = resolveAlias(beanID)
 	if scopes[beanID] == Request {
 		return nil, errors.New("request-scoped beans can't be retrieved directly from the container: they can only be retrieved from the web-context")
 	}
-	return getInstance(context.Background(), beanID, make(map[string]bool))
+	chain := acquireChain()
+	defer releaseChain(chain)
+	return getInstance(ctx, beanID, chain)
 }
 
 func getRequestBeanInstance(ctx context.Context, beanID string) interface{} {
 	if atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
 		panic("container is not initialized: can't lookup instances of beans yet")
 	}
-	beanInstance, err := getInstance(ctx, beanID, make(map[string]bool))
+	var beanInstance interface{}
+	var err error
+	chain := acquireChain()
+	defer releaseChain(chain)
+	if requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext); ok {
+		beanInstance, err = requestContext.scope.getInstance(beanID, chain)
+	} else {
+		beanInstance, err = getInstance(ctx, beanID, chain)
+	}
 	if err != nil {
 		panic(err)
 	}
 	return beanInstance
 }
 
+// singletonsView is an immutable copy of singletons (and aliases) of the initialized container. It's published
+// atomically once the container is initialized, so that singletons can be looked up from many goroutines without any
+// locking. The view is never modified: changes (e.g. OverrideBeanInstance) publish a new one.
+type singletonsView struct {
+	instances map[string]interface{}
+	aliases   map[string]string
+}
+
+func (view *singletonsView) lookup(beanID string) (interface{}, bool) {
+	if beanInstance, ok := view.instances[beanID]; ok {
+		return beanInstance, true
+	}
+	beanInstance, ok := view.instances[view.aliases[beanID]]
+	return beanInstance, ok
+}
+
+// publishSingletons function publishes the view of the current singletons, it should be called with the
+// initializeShutdownLock held.
+func publishSingletons() {
+	view := &singletonsView{
+		instances: make(map[string]interface{}, len(singletonInstances)),
+		aliases:   make(map[string]string, len(aliases)),
+	}
+	for beanID, beanInstance := range singletonInstances {
+		if scopes[beanID] == Singleton {
+			view.instances[beanID] = beanInstance
+		}
+	}
+	for beanID, beanInstance := range overriddenInstances {
+		view.instances[beanID] = beanInstance
+	}
+	for alias, beanID := range aliases {
+		view.aliases[alias] = beanID
+	}
+	initializedSingletons.Store(view)
+}
+
 func isBeanRegistered(beanID string) bool {
 	if _, ok := beans[beanID]; ok {
 		return true
@@ -533,28 +1194,67 @@ func isBeanRegistered(beanID string) bool {
 	return false
 }
 
-func getInstance(ctx context.Context, beanID string, chain map[string]bool) (interface{}, error) {
+func getInstance(ctx context.Context, beanID string, chain *dependencyChain) (interface{}, error) {
+	if singletons := initializedSingletons.Load(); singletons != nil {
+		if beanInstance, ok := singletons.instances[beanID]; ok {
+			return beanInstance, nil
+		}
+	}
 	if !isBeanRegistered(beanID) {
 		return nil, errors.New("bean is not registered: " + beanID)
 	}
 	if scopes[beanID] == Singleton {
-		return singletonInstances[beanID], nil
+		if beanInstance, ok := singletonInstances[beanID]; ok || !lazyBeans[beanID] {
+			return beanInstance, nil
+		}
+	}
+	if chain.contains(beanID) {
+		return nil, chain.circularDependencyError(beanID)
+	}
+	if maxDepth := int(atomic.LoadInt32(&maxResolutionDepth)); len(chain.links) >= maxDepth {
+		return nil, chain.resolutionDepthError(beanID, maxDepth)
+	}
+	chain.push(beanID)
+	defer chain.pop()
+	if scopes[beanID] == Singleton {
+		return lazySingletons.getInstance(beanID, func() (interface{}, error) {
+			return createBeanInstance(context.Background(), beanID, chain)
+		})
+	}
+	if scopes[beanID] == Refresh {
+		return refreshBeans.getInstance(beanID, func() (interface{}, error) {
+			return createBeanInstance(context.Background(), beanID, chain)
+		})
+	}
+	if scopes[beanID] == Pooled {
+		return getPooledInstance(ctx, beanID, chain)
 	}
-	if _, ok := chain[beanID]; ok {
-		return nil, errors.New("circular dependency detected for bean: " + beanID)
+	if scopes[beanID] == Tenant {
+		return tenantBeans.getInstance(ctx, beanID, func() (interface{}, error) {
+			return createBeanInstance(ctx, beanID, chain)
+		})
 	}
-	chain[beanID] = true
-	instance, err := createInstance(ctx, beanID)
+	return createBeanInstance(ctx, beanID, chain)
+}
+
+func createBeanInstance(ctx context.Context, beanID string, chain *dependencyChain) (interface{}, error) {
+	if err := instantiateDependencies(ctx, beanID, chain); err != nil {
+		return nil, err
+	}
+	instance, err := createInstance(ctx, beanID, chain)
 	if err != nil {
 		return nil, err
 	}
+	if isNilBean(instance) || isFunctionBean(instance) {
+		return instance, nil
+	}
 	if _, ok := beanFactories[beanID]; !ok {
-		err := injectDependencies(beanID, instance, chain)
+		err := injectDependencies(ctx, beanID, instance, chain)
 		if err != nil {
 			return nil, err
 		}
 	}
-	err = initializeInstance(beanID, instance)
+	postprocessedInstance, err := initializeInstance(beanID, instance)
 	if err != nil {
 		return nil, err
 	}
@@ -562,11 +1262,14 @@ func getInstance(ctx context.Context, beanID string, chain map[string]bool) (int
 	if err != nil {
 		return nil, err
 	}
-	return instance, nil
+	trackInstance(ctx, beanID, postprocessedInstance)
+	emitContainerEvent(ContainerEvent{Type: BeanInitialized, BeanID: beanID, Bean: postprocessedInstance})
+	return postprocessedInstance, nil
 }
 
 // GetBeanTypes returns a map (copy) of beans registered in the Container, omitting bean factories, because their real
-// return type is unknown.
+// return type is unknown (unless the factory is registered with RegisterTypedBeanFactory, or the Singleton bean is
+// created by the initialized container already).
 func GetBeanTypes() map[string]reflect.Type {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
@@ -574,6 +1277,12 @@ func GetBeanTypes() map[string]reflect.Type {
 	for k, v := range beans {
 		beanTypes[k] = v
 	}
+	for k, v := range factoryBeanTypes {
+		beanTypes[k] = v
+	}
+	for k, v := range sampledBeanTypes {
+		beanTypes[k] = v
+	}
 	return beanTypes
 }
 
@@ -591,9 +1300,17 @@ func GetBeanScopes() map[string]Scope {
 // Close destroys the IoC container - executes io.Closer for all beans which implements it.
 // This is responsibility of consumer to call Close method.
 // If io.Closer returns an error it will just log the error and continue to Close other beans.
+// Cleanup functions returned by bean factories (see RegisterBeanFactoryWithCleanup) are called afterwards.
+// Started Lifecycle beans and the scheduler are stopped beforehand.
 func Close() {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
+	atomic.StoreInt32(&containerClosing, 1)
+	if err := Stop(context.Background()); err != nil {
+		logrus.Error(err)
+	}
+	stopScheduler(true)
+	stopWorkers(true)
 
 	for key, value := range singletonInstances {
 		fnc, ok := value.(io.Closer)
@@ -602,24 +1319,91 @@ func Close() {
 			if err != nil {
 				logrus.WithField("beanID", key).Error(err)
 			}
+			emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: key, Bean: value, Err: err})
 		}
 	}
+	refreshBeans.refresh()
+	lazySingletons.refresh()
+	tenantBeans.close()
+	runContainerCleanups()
+	emitContainerEvent(ContainerEvent{Type: ContainerClosed})
 
 	resetContainerWithoutLock()
+	atomic.StoreInt32(&containerClosed, 1)
 }
 
-func resetContainer() {
+// Reset function resets the container to its initial, uninitialized state: all registered beans, postprocessors and
+// settings are dropped. Unlike Close, it doesn't close any beans. It's mostly meant to be used in tests.
+func Reset() {
 	initializeShutdownLock.Lock()
 	defer initializeShutdownLock.Unlock()
 	resetContainerWithoutLock()
 }
 
 func resetContainerWithoutLock() {
-	containerInitialized = 0
+	atomic.StoreInt32(&containerInitialized, 0)
+	atomic.StoreInt32(&maxResolutionDepth, DefaultMaxResolutionDepth)
+	atomic.StoreInt32(&containerClosing, 0)
+	atomic.StoreInt32(&containerClosed, 0)
+	initializedSingletons.Store(nil)
+	overriddenInstances = make(map[string]interface{})
 	beans = make(map[string]reflect.Type)
 	beanFactories = make(map[string]func(context.Context) (interface{}, error))
+	resolvingBeanFactories = make(map[string]func(context.Context, Resolver) (interface{}, error))
+	factoryBeanTypes = make(map[string]reflect.Type)
+	sampledBeanTypes = make(map[string]reflect.Type)
+	startupTimings = make(map[string]*BeanTiming)
+	startupDuration = 0
+	atomic.StoreInt32(&startupReportSize, 0)
+	atomic.StoreInt32(&instanceTracking, 0)
+	resetTrackedInstances()
+	SetLeakHandler(nil)
+	SetBeanValidator(nil)
+	interceptors = nil
+	proxyFactories = make(map[reflect.Type]proxyFactory)
 	scopes = make(map[string]Scope)
 	singletonInstances = make(map[string]interface{})
 	userCreatedInstances = make(map[string]bool)
-	beanPostprocessors = make(map[reflect.Type][]func(bean interface{}) error)
+	beanPostprocessors = make(map[reflect.Type][]beanPostprocessor)
+	defaultBeans = make(map[reflect.Type]string)
+	atomic.StoreInt32(&requestBeansClosePolicy, int32(CloseAfterRequest))
+	overwritePolicy = OverwriteWarn
+	ambiguityPolicy = AmbiguityError
+	optionalAmbiguityPolicy = FailOnOptionalAmbiguity
+	emptyCollectionPolicy = InjectEmptyCollection
+	tagAliases = make(map[tag][]string)
+	tagPrefixes = nil
+	tagValidation = StrictTagValidation
+	invokers = nil
+	lateBindings = nil
+	primaryBeans = make(map[string]bool)
+	lazyBeans = make(map[string]bool)
+	beanDependencies = make(map[string][]string)
+	beanQualifiers = make(map[string]string)
+	beanDecorators = make(map[string][]beanDecorator)
+	lazySingletons = &refreshScope{entries: make(map[string]*requestScopeEntry)}
+	atomic.StoreInt32(&unsafeInjection, 1)
+	atomic.StoreInt32(&nilBeansAllowed, 0)
+	activeProfiles = getDefaultActiveProfiles()
+	setPropertySources([]PropertySource{EnvPropertySource{}})
+	aliases = make(map[string]string)
+	registeredTypes = make(map[string]reflect.Type)
+	refreshBeans = &refreshScope{entries: make(map[string]*requestScopeEntry)}
+	beanModules = make(map[string]string)
+	beanGroups = make(map[string]map[string]bool)
+	beanDefinitionPostprocessors = nil
+	setContainerHooks(nil)
+	lifecycleLock.Lock()
+	startedBeans = nil
+	lifecycleLock.Unlock()
+	stopScheduler(false)
+	stopWorkers(false)
+	poolsLock.Lock()
+	pools = make(map[string]*sync.Pool)
+	poolsLock.Unlock()
+	tenantBeans = newTenantScope()
+	containerCleanupsLock.Lock()
+	containerCleanups = nil
+	containerCleanupsLock.Unlock()
+	invalidateInjectionPlans()
 }
@@ -17,9 +17,13 @@ package di
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -30,7 +34,7 @@ type TestSuite struct {
 }
 
 func (*TestSuite) TearDownTest() {
-	resetContainer()
+	Reset()
 	closedSingletons = nil
 	singletonBeansWithErrorOnClose = nil
 }
@@ -49,10 +53,44 @@ func (suite *TestSuite) TestInitializeContainerTwice() {
 	}
 }
 
+type slowlyInitializingBean struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (sib *slowlyInitializingBean) PostConstruct() error {
+	close(sib.started)
+	<-sib.release
+	return errors.New("initialization failed")
+}
+
+func (suite *TestSuite) TestConcurrentInitializeContainer() {
+	bean := &slowlyInitializingBean{started: make(chan struct{}), release: make(chan struct{})}
+	_, err := RegisterBeanInstance("slowlyInitializingBean", bean)
+	assert.NoError(suite.T(), err)
+	errs := make(chan error, 2)
+	go func() {
+		errs <- InitializeContainer()
+	}()
+	<-bean.started
+	currentInitializationLock.Lock()
+	initialization := currentInitialization
+	currentInitializationLock.Unlock()
+	go func() {
+		errs <- InitializeContainer()
+	}()
+	<-initialization.joined
+	close(bean.release)
+	assert.EqualError(suite.T(), <-errs, "initialization failed")
+	assert.EqualError(suite.T(), <-errs, "initialization failed")
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "container is already initialized: reinitialization is not supported")
+}
+
 func (suite *TestSuite) TestReinitializeContainerAfterReset() {
 	err := InitializeContainer()
 	assert.NoError(suite.T(), err)
-	resetContainer()
+	Reset()
 	err = InitializeContainer()
 	assert.NoError(suite.T(), err)
 }
@@ -131,7 +169,7 @@ func (suite *TestSuite) TestRegisterNonReferenceBean() {
 }
 
 func (suite *TestSuite) TestRegisterNonReferenceBeanInstance() {
-	expectedError := errors.New("bean instance must be a pointer")
+	expectedError := errors.New("bean instance must be a pointer, a function or a channel")
 	overwritten, err := RegisterBeanInstance("", "")
 	assert.False(suite.T(), overwritten)
 	if assert.Error(suite.T(), err) {
@@ -145,7 +183,7 @@ func (suite *TestSuite) TestRegisterNonReferenceSingletonBeanFactory() {
 	})
 	assert.False(suite.T(), overwritten)
 	assert.NoError(suite.T(), err)
-	expectedError := errors.New("bean factory must return pointer")
+	expectedError := errors.New("bean factory must return pointer, function or channel")
 	err = InitializeContainer()
 	if assert.Error(suite.T(), err) {
 		assert.Equal(suite.T(), expectedError, err)
@@ -158,7 +196,7 @@ func (suite *TestSuite) TestRegisterNonReferencePrototypeBeanFactory() {
 	})
 	assert.False(suite.T(), overwritten)
 	assert.NoError(suite.T(), err)
-	expectedError := errors.New("bean factory must return pointer")
+	expectedError := errors.New("bean factory must return pointer, function or channel")
 	err = InitializeContainer()
 	assert.NoError(suite.T(), err)
 	instance, err := GetInstanceSafe("")
@@ -182,7 +220,7 @@ func (suite *TestSuite) TestRegisterSingletonBeanUnsupportedScope() {
 
 func (suite *TestSuite) TestRegisterSingletonBeanNonReferenceDependency() {
 	type SingletonBean struct {
-		SomeOtherBean string `di.inject:"someOtherBean"`
+		SomeOtherBean [2]string `di.inject:"someOtherBean"`
 	}
 	expectedError := errors.New(unsupportedDependencyType)
 	overwritten, err := RegisterBean("", reflect.TypeOf((*SingletonBean)(nil)))
@@ -273,6 +311,29 @@ func (suite *TestSuite) TestRegisterBeanWithOverwriting() {
 	assert.NotNil(suite.T(), bean2)
 }
 
+func (suite *TestSuite) TestRegisterBeanWithScope() {
+	type UntaggedBean struct {
+		Value int
+	}
+	type SingletonBean struct {
+		Scope Scope `di.scope:"singleton"`
+	}
+	overwritten, err := RegisterBeanWithScope("untagged", reflect.TypeOf((*UntaggedBean)(nil)), Prototype)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanWithScope("tagged", reflect.TypeOf((*SingletonBean)(nil)), Prototype)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanWithScope("unsupported", reflect.TypeOf((*UntaggedBean)(nil)), "session")
+	assert.EqualError(suite.T(), err, "unsupported scope: session")
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Prototype, GetBeanScopes()["untagged"])
+	assert.NotSame(suite.T(), GetInstance("untagged"), GetInstance("untagged"))
+	assert.NotSame(suite.T(), GetInstance("tagged"), GetInstance("tagged"))
+	_, err = RegisterBeanWithScope("late", reflect.TypeOf((*UntaggedBean)(nil)), Singleton)
+	assert.EqualError(suite.T(), err, "container is already initialized: can't register new bean")
+}
+
 func (suite *TestSuite) TestRegisterBeanWithOverwritingFromSingletonToPrototypeScope() {
 	type SingletonBean struct {
 		Scope Scope `di.scope:"singleton"`
@@ -688,12 +749,80 @@ func (suite *TestSuite) TestDirectCircularDependency() {
 	assert.NoError(suite.T(), err)
 	err = InitializeContainer()
 	assert.NoError(suite.T(), err)
-	expectedError := errors.New("circular dependency detected for bean: circularBean")
 	instance, err := GetInstanceSafe("circularBean")
 	assert.Nil(suite.T(), instance)
-	if assert.Error(suite.T(), err) {
-		assert.Equal(suite.T(), expectedError, err)
+	assert.EqualError(suite.T(), err,
+		"circular dependency detected for bean: circularBean (circularBean.CircularBean -> circularBean)")
+}
+
+type CircularPrototypeA struct {
+	Scope Scope               `di.scope:"prototype"`
+	B     *CircularPrototypeB `di.inject:"b"`
+}
+
+type CircularPrototypeB struct {
+	Scope Scope               `di.scope:"prototype"`
+	C     *CircularPrototypeC `di.inject:"c"`
+}
+
+type CircularPrototypeC struct {
+	Scope Scope               `di.scope:"prototype"`
+	A     *CircularPrototypeA `di.inject:"a"`
+}
+
+func (suite *TestSuite) TestIndirectCircularDependency() {
+	type SingletonBean struct {
+		A *CircularPrototypeA `di.inject:"a"`
+	}
+	_, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("a", reflect.TypeOf((*CircularPrototypeA)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("b", reflect.TypeOf((*CircularPrototypeB)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("c", reflect.TypeOf((*CircularPrototypeC)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	var circularDependencyError *CircularDependencyError
+	if assert.ErrorAs(suite.T(), err, &circularDependencyError) {
+		assert.Equal(suite.T(), "a", circularDependencyError.BeanID)
+		assert.Equal(suite.T(), []string{"a.B", "b.C", "c.A", "a"}, circularDependencyError.Chain)
+	}
+	assert.EqualError(suite.T(), err, "circular dependency detected for bean: a (a.B -> b.C -> c.A -> a)")
+}
+
+func (suite *TestSuite) TestMaxResolutionDepth() {
+	for i := 0; i < 5; i++ {
+		dependencyID := "level" + strconv.Itoa(i+1)
+		_, err := RegisterResolvingBeanFactory("level"+strconv.Itoa(i), Prototype,
+			func(ctx context.Context, deps Resolver) (interface{}, error) {
+				if dependencyID == "level5" {
+					return &singletonBean{}, nil
+				}
+				return deps.Resolve(dependencyID)
+			})
+		assert.NoError(suite.T(), err)
+	}
+	SetMaxResolutionDepth(3)
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("level2")
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("level0")
+	var resolutionDepthError *ResolutionDepthError
+	if assert.ErrorAs(suite.T(), err, &resolutionDepthError) {
+		assert.Equal(suite.T(), "level3", resolutionDepthError.BeanID)
+		assert.Equal(suite.T(), 3, resolutionDepthError.MaxDepth)
+		assert.Equal(suite.T(), []string{"level0", "level1", "level2", "level3"}, resolutionDepthError.Chain)
 	}
+	assert.ErrorContains(suite.T(), err,
+		"maximum resolution depth of 3 exceeded for bean: level3 (level0 -> level1 -> level2 -> level3)")
+	SetMaxResolutionDepth(0)
+	_, err = GetInstanceSafe("level0")
+	assert.NoError(suite.T(), err)
+	SetMaxResolutionDepth(3)
+	Reset()
+	assert.Equal(suite.T(), int32(DefaultMaxResolutionDepth), maxResolutionDepth)
 }
 
 func (suite *TestSuite) TestInjectByTypeNoCandidatesMandatory() {
@@ -743,11 +872,16 @@ func (suite *TestSuite) TestInjectByTypeMoreThanOneCandidate() {
 	overwritten, err = RegisterBeanInstance("candidate2", &OtherBean{})
 	assert.False(suite.T(), overwritten)
 	assert.NoError(suite.T(), err)
-	expectedError := errors.New("more then one candidate found for the injection")
 	err = InitializeContainer()
-	if assert.Error(suite.T(), err) {
-		assert.Equal(suite.T(), expectedError, err)
+	var ambiguousDependencyError *AmbiguousDependencyError
+	if assert.ErrorAs(suite.T(), err, &ambiguousDependencyError) {
+		assert.Equal(suite.T(), "singletonBean", ambiguousDependencyError.BeanID)
+		assert.Equal(suite.T(), "RequestBean", ambiguousDependencyError.Field)
+		assert.Equal(suite.T(), reflect.TypeOf((*OtherBean)(nil)), ambiguousDependencyError.Type)
+		assert.Equal(suite.T(), []string{"candidate1", "candidate2"}, ambiguousDependencyError.Candidates)
 	}
+	assert.EqualError(suite.T(), err, "more than one candidate found for the injection into field RequestBean of bean "+
+		"singletonBean (*di.OtherBean): candidate1, candidate2")
 }
 
 func (suite *TestSuite) TestInjectByTypeWithType() {
@@ -1047,6 +1181,88 @@ func (suite *TestSuite) TestContextAwareBeanFactory() {
 	assert.Equal(suite.T(), context.Background(), outerCtx)
 }
 
+type contextInjectedBean struct {
+	Scope Scope           `di.scope:"request"`
+	ctx   context.Context `di.inject:""`
+}
+
+type contextInjectedSingleton struct {
+	ctx context.Context `di.inject:""`
+}
+
+type contextValueKey struct{}
+
+func (suite *TestSuite) TestContextInjection() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*contextInjectedBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("singletonBean", reflect.TypeOf((*contextInjectedSingleton)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), context.Background(), GetInstance("singletonBean").(*contextInjectedSingleton).ctx)
+	scopeCtx, done := BeginScope(context.WithValue(context.Background(), contextValueKey{}, "acme"))
+	defer done()
+	instance, ok := FromContext[*contextInjectedBean](scopeCtx, "requestBean")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "acme", instance.ctx.Value(contextValueKey{}))
+	_, ok = RequestScopeFromContext(instance.ctx)
+	assert.True(suite.T(), ok)
+}
+
+func (suite *TestSuite) TestTryGetInstance() {
+	instance, ok := TryGetInstance("singleton")
+	assert.False(suite.T(), ok)
+	assert.Nil(suite.T(), instance)
+	singleton := &singletonBean{}
+	overwritten, err := RegisterBeanInstance("singleton", singleton)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RegisterAlias("alias", "singleton"))
+	overwritten, err = RegisterBeanFactory("prototype", Prototype, func(context.Context) (interface{}, error) {
+		return &singletonBean{}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("failing", Prototype, func(context.Context) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	instance, ok = TryGetInstance("singleton")
+	assert.True(suite.T(), ok)
+	assert.Same(suite.T(), singleton, instance)
+	instance, ok = TryGetInstance("alias")
+	assert.True(suite.T(), ok)
+	assert.Same(suite.T(), singleton, instance)
+	instance, ok = TryGetInstance("prototype")
+	assert.True(suite.T(), ok)
+	assert.IsType(suite.T(), &singletonBean{}, instance)
+	instance, ok = TryGetInstance("missing")
+	assert.False(suite.T(), ok)
+	assert.Nil(suite.T(), instance)
+	instance, ok = TryGetInstance("failing")
+	assert.False(suite.T(), ok)
+	assert.Nil(suite.T(), instance)
+}
+
+func (suite *TestSuite) TestGetInstanceOrDefault() {
+	fallback := &singletonBean{}
+	assert.Same(suite.T(), fallback, GetInstanceOrDefault("singleton", fallback))
+	singleton := &singletonBean{}
+	overwritten, err := RegisterBeanInstance("singleton", singleton)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), singleton, GetInstanceOrDefault("singleton", fallback))
+	assert.Same(suite.T(), fallback, GetInstanceOrDefault("missing", fallback))
+	assert.Nil(suite.T(), GetInstanceOrDefault("missing", nil))
+}
+
 func (suite *TestSuite) TestGetBeanTypes() {
 	type SomeBean struct {
 		Scope Scope `di.scope:"prototype"`
@@ -1166,3 +1382,735 @@ func (suite *TestSuite) TestShutdownContinueOnError() {
 	assert.Equal(suite.T(), 5, len(closedSingletons))
 	assert.Equal(suite.T(), 5, len(singletonBeansWithErrorOnClose))
 }
+
+type noopService struct {
+}
+
+func (noopService) someMethod() {
+}
+
+func (suite *TestSuite) TestOnMissingNil() {
+	type SingletonBean struct {
+		SomeOtherBean *string `di.inject:"someOtherBean" di.onMissing:"nil"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("singletonBean").(*SingletonBean).SomeOtherBean)
+}
+
+func (suite *TestSuite) TestOnMissingError() {
+	type SingletonBean struct {
+		SomeOtherBean someInterface `di.inject:"" di.onMissing:"error"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedError := errors.New("no candidates found for the injection")
+	err = InitializeContainer()
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), expectedError, err)
+	}
+}
+
+func (suite *TestSuite) TestOnMissingDefault() {
+	type SingletonBean struct {
+		SomeOtherBean someInterface `di.inject:"" di.onMissing:"default"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("noopService", Singleton, func(context.Context) (interface{}, error) {
+		return &noopService{}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterDefaultBean(reflect.TypeOf((*someInterface)(nil)).Elem(), "noopService")
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &noopService{}, GetInstance("singletonBean").(*SingletonBean).SomeOtherBean)
+}
+
+func (suite *TestSuite) TestOnMissingDefaultNotRegistered() {
+	type SingletonBean struct {
+		SomeOtherBean *string `di.inject:"someOtherBean" di.onMissing:"default"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedError := errors.New("no default bean registered for type: *string")
+	err = InitializeContainer()
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), expectedError, err)
+	}
+}
+
+func (suite *TestSuite) TestOnMissingInvalidValue() {
+	type SingletonBean struct {
+		SomeOtherBean *string `di.inject:"someOtherBean" di.onMissing:"ignore"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedError := errors.New("invalid di.onMissing value: ignore")
+	err = InitializeContainer()
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), expectedError, err)
+	}
+}
+
+func (suite *TestSuite) TestOnMissingWithOptional() {
+	type SingletonBean struct {
+		SomeOtherBean *string `di.inject:"someOtherBean" di.optional:"true" di.onMissing:"nil"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedError := errors.New("di.optional and di.onMissing can't be used together")
+	err = InitializeContainer()
+	if assert.Error(suite.T(), err) {
+		assert.Equal(suite.T(), expectedError, err)
+	}
+}
+
+type contextKey struct{}
+
+type prototypeContextAwareBean struct {
+	Scope Scope `di.scope:"prototype"`
+	ctx   context.Context
+}
+
+func (pcab *prototypeContextAwareBean) SetContext(ctx context.Context) {
+	pcab.ctx = ctx
+}
+
+func (suite *TestSuite) TestGetInstanceCtx() {
+	var factoryCtx context.Context
+	overwritten, err := RegisterBeanFactory("prototypeBeanFactory", Prototype, func(ctx context.Context) (interface{}, error) {
+		factoryCtx = ctx
+		return new(string), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("prototypeBean", reflect.TypeOf((*prototypeContextAwareBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	instance := GetInstanceCtx(ctx, "prototypeBeanFactory")
+	assert.NotNil(suite.T(), instance)
+	assert.Equal(suite.T(), ctx, factoryCtx)
+	instance, err = GetInstanceSafeCtx(ctx, "prototypeBean")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ctx, instance.(*prototypeContextAwareBean).ctx)
+	assert.Panics(suite.T(), func() {
+		GetInstanceCtx(ctx, "unknownBean")
+	})
+}
+
+func (suite *TestSuite) TestOverwritePolicyError() {
+	SetOverwritePolicy(OverwriteError)
+	overwritten, err := RegisterBeanInstance("bean", new(string))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedError := errors.New("bean with such ID is already registered: bean")
+	overwritten, err = RegisterBeanInstance("bean", new(string))
+	assert.False(suite.T(), overwritten)
+	assert.Equal(suite.T(), expectedError, err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*SingletonBeanWithClose)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.Equal(suite.T(), expectedError, err)
+	overwritten, err = RegisterBeanFactory("bean", Singleton, func(context.Context) (interface{}, error) {
+		return new(int), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.Equal(suite.T(), expectedError, err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), new(string), GetInstance("bean"))
+}
+
+func (suite *TestSuite) TestOverwritePolicyIgnore() {
+	SetOverwritePolicy(OverwriteIgnore)
+	overwritten, err := RegisterBeanFactory("bean", Singleton, func(context.Context) (interface{}, error) {
+		return new(int), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanInstance("bean", new(string))
+	assert.True(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), new(string), GetInstance("bean"))
+}
+
+type concurrentlyRegisteredBean struct {
+	Scope Scope  `di.scope:"prototype"`
+	Value string `di.value:"${concurrent.value}"`
+}
+
+func (suite *TestSuite) TestConcurrentRegistrationAndRetrieval() {
+	var hookCalls int32
+	var registrations sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		registrations.Add(1)
+		go func(i int) {
+			defer registrations.Done()
+			index := strconv.Itoa(i)
+			_, err := RegisterBean("bean"+index, reflect.TypeOf((*concurrentlyRegisteredBean)(nil)))
+			assert.NoError(suite.T(), err)
+			_, err = RegisterBeanInstance("instance"+index, new(string))
+			assert.NoError(suite.T(), err)
+			_, err = RegisterBeanFactory("factory"+index, Prototype, func(context.Context) (interface{}, error) {
+				return new(int), nil
+			})
+			assert.NoError(suite.T(), err)
+			assert.NoError(suite.T(), RegisterAlias("alias"+index, "instance"+index))
+			assert.NoError(suite.T(), AddToGroup("group", "instance"+index))
+			assert.NoError(suite.T(), AddPropertySource(MapPropertySource{"concurrent.value": "value"}))
+			assert.NoError(suite.T(), RegisterContainerHook(func(ContainerEvent) {
+				atomic.AddInt32(&hookCalls, 1)
+			}))
+			_, err = GetInstanceSafe("instance" + index)
+			assert.Error(suite.T(), err)
+			_, _ = GetProperty("concurrent.value")
+		}(i)
+	}
+	registrations.Wait()
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var retrievals sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		retrievals.Add(1)
+		go func(i int) {
+			defer retrievals.Done()
+			index := strconv.Itoa(i)
+			assert.Equal(suite.T(), "value", GetInstance("bean"+index).(*concurrentlyRegisteredBean).Value)
+			assert.Same(suite.T(), GetInstance("instance"+index), GetInstance("alias"+index))
+			assert.IsType(suite.T(), new(int), GetInstance("factory"+index))
+			_, err := RegisterBean("late"+index, reflect.TypeOf((*concurrentlyRegisteredBean)(nil)))
+			assert.Error(suite.T(), err)
+		}(i)
+	}
+	retrievals.Wait()
+	assert.Len(suite.T(), GetBeanTypes(), 20)
+	assert.Positive(suite.T(), atomic.LoadInt32(&hookCalls))
+}
+
+type selfInjectingBean struct {
+	Dependency *string `di.inject:"tagged"`
+	injected   *string
+}
+
+func (sib *selfInjectingBean) InjectDependencies(resolve func(beanID string) (interface{}, error)) error {
+	dependency, err := resolve("dependency")
+	if err != nil {
+		return err
+	}
+	sib.injected = dependency.(*string)
+	return nil
+}
+
+func (suite *TestSuite) TestDependencyInjector() {
+	dependency := new(string)
+	overwritten, err := RegisterBeanInstance("dependency", dependency)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*selfInjectingBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	bean := GetInstance("bean").(*selfInjectingBean)
+	assert.Same(suite.T(), dependency, bean.injected)
+	assert.Nil(suite.T(), bean.Dependency)
+}
+
+func (suite *TestSuite) TestDependencyInjectorWithMissingDependency() {
+	overwritten, err := RegisterBean("bean", reflect.TypeOf((*selfInjectingBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no dependency found: dependency")
+}
+
+type nilDependency struct{}
+
+type beanWithNilDependency struct {
+	Dependency *nilDependency           `di.inject:"nilBean"`
+	Provider   Provider[*nilDependency] `di.inject:"nilBean"`
+}
+
+func (suite *TestSuite) TestNilBeansAreRejected() {
+	overwritten, err := RegisterBeanInstance("nilInstance", (*nilDependency)(nil))
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, "bean instance is a nil pointer: nilInstance")
+	overwritten, err = RegisterBeanInstance("untypedNilInstance", nil)
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, "bean instance can't be nil")
+	overwritten, err = RegisterBeanFactory("nilBean", Singleton, func(context.Context) (interface{}, error) {
+		return (*nilDependency)(nil), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean factory returned nil: nilBean")
+}
+
+func (suite *TestSuite) TestNilPrototypeIsRejected() {
+	overwritten, err := RegisterBeanFactory("nilBean", Prototype, func(context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("nilBean")
+	assert.EqualError(suite.T(), err, "bean factory returned nil: nilBean")
+}
+
+func (suite *TestSuite) TestNilBeansAllowed() {
+	SetNilBeansAllowed(true)
+	overwritten, err := RegisterBeanInstance("nilInstance", (*nilDependency)(nil))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("nilBean", Singleton, func(context.Context) (interface{}, error) {
+		return (*nilDependency)(nil), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*beanWithNilDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("nilBean"))
+	bean := GetInstance("bean").(*beanWithNilDependency)
+	assert.Nil(suite.T(), bean.Dependency)
+	assert.Nil(suite.T(), bean.Provider.Get())
+}
+
+type ValueConfig struct {
+	URL     string
+	Retries int
+}
+
+func (config ValueConfig) String() string {
+	return config.URL
+}
+
+type ValueConfigConsumer struct {
+	ByID     ValueConfig  `di.inject:"config"`
+	ByType   ValueConfig  `di.inject:""`
+	Stringer fmt.Stringer `di.inject:""`
+}
+
+func (suite *TestSuite) TestValueBeans() {
+	overwritten, err := RegisterBeanValue("config", ValueConfig{URL: "http://localhost", Retries: 3})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*ValueConfigConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*ValueConfigConsumer)
+	assert.Equal(suite.T(), ValueConfig{URL: "http://localhost", Retries: 3}, consumer.ByID)
+	assert.Equal(suite.T(), consumer.ByID, consumer.ByType)
+	assert.Equal(suite.T(), "http://localhost", consumer.Stringer.String())
+	consumer.ByID.Retries = 5
+	assert.Equal(suite.T(), 3, GetInstance("config").(ValueConfig).Retries)
+	assert.Equal(suite.T(), 3, consumer.ByType.Retries)
+}
+
+func (suite *TestSuite) TestValueBeansMustBeStructsOrPrimitives() {
+	_, err := RegisterBeanValue("pointer", &ValueConfig{})
+	assert.EqualError(suite.T(), err, "bean value must be a struct or a primitive: pointer")
+	_, err = RegisterBeanValue("slice", []string{"value"})
+	assert.EqualError(suite.T(), err, "bean value must be a struct or a primitive: slice")
+	_, err = RegisterBeanValue("nil", nil)
+	assert.EqualError(suite.T(), err, "bean value must be a struct or a primitive: nil")
+}
+
+func (suite *TestSuite) TestStructFieldsCanOnlyBeInjectedWithValueBeans() {
+	type PointerConfigConsumer struct {
+		Config ValueConfig `di.inject:"config"`
+	}
+	_, err := RegisterBeanInstance("config", &ValueConfig{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*PointerConfigConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean config can't be injected into field Config of type di.ValueConfig")
+}
+
+type PrimitiveValuesConsumer struct {
+	Port     int           `di.inject:"httpPort"`
+	Host     string        `di.inject:"httpHost"`
+	Timeout  time.Duration `di.inject:"timeout"`
+	Optional bool          `di.inject:"debug" di.optional:"true"`
+}
+
+func (suite *TestSuite) TestPrimitiveValueBeans() {
+	_, err := RegisterBeanValue("httpPort", 8080)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanValue("httpHost", "localhost")
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanValue("timeout", 5*time.Second)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*PrimitiveValuesConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*PrimitiveValuesConsumer)
+	assert.Equal(suite.T(), 8080, consumer.Port)
+	assert.Equal(suite.T(), "localhost", consumer.Host)
+	assert.Equal(suite.T(), 5*time.Second, consumer.Timeout)
+	assert.False(suite.T(), consumer.Optional)
+	assert.Equal(suite.T(), 8080, GetInstance("httpPort"))
+}
+
+func (suite *TestSuite) TestPrimitiveValueBeansAreOnlyInjectedByID() {
+	type ByTypeConsumer struct {
+		Port int `di.inject:""`
+	}
+	type StringerConsumer struct {
+		Stringer fmt.Stringer `di.inject:"" di.optional:"true"`
+	}
+	_, err := RegisterBeanValue("timeout", 5*time.Second)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("byType", reflect.TypeOf((*ByTypeConsumer)(nil)))
+	assert.EqualError(suite.T(), err, unsupportedDependencyType)
+	_, err = RegisterBean("stringer", reflect.TypeOf((*StringerConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("stringer").(*StringerConsumer).Stringer)
+}
+
+func (suite *TestSuite) TestPrimitiveValueBeansMustMatchFieldType() {
+	type Int64Consumer struct {
+		Port int64 `di.inject:"httpPort"`
+	}
+	_, err := RegisterBeanValue("httpPort", 8080)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*Int64Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean httpPort can't be injected into field Port of type int64")
+}
+
+type Metrics interface {
+	Count(name string)
+}
+
+type NoopMetrics struct{}
+
+func (*NoopMetrics) Count(string) {}
+
+type PrometheusMetrics struct{}
+
+func (*PrometheusMetrics) Count(string) {}
+
+type MetricsConsumer struct {
+	ByType Metrics `di.inject:"" di.default:"noopMetrics"`
+	ByID   Metrics `di.inject:"prometheusMetrics" di.default:"noopMetrics"`
+}
+
+func (suite *TestSuite) TestDefaultTagFallback() {
+	_, err := RegisterBean("consumer", reflect.TypeOf((*MetricsConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("noopMetrics", &NoopMetrics{})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*MetricsConsumer)
+	assert.Same(suite.T(), GetInstance("noopMetrics"), consumer.ByType)
+	assert.Same(suite.T(), GetInstance("noopMetrics"), consumer.ByID)
+}
+
+func (suite *TestSuite) TestDefaultTagIsIgnoredIfDependencyIsFound() {
+	type Consumer struct {
+		Metrics Metrics `di.inject:"prometheusMetrics" di.default:"noopMetrics"`
+	}
+	_, err := RegisterBean("consumer", reflect.TypeOf((*Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prometheusMetrics", reflect.TypeOf((*PrometheusMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("noopMetrics", &NoopMetrics{})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("prometheusMetrics"), GetInstance("consumer").(*Consumer).Metrics)
+}
+
+func (suite *TestSuite) TestDefaultTagWithMissingFallback() {
+	type OptionalConsumer struct {
+		Metrics Metrics `di.inject:"" di.default:"noopMetrics" di.optional:"true"`
+	}
+	type RequiredConsumer struct {
+		Metrics Metrics `di.inject:"" di.default:"noopMetrics"`
+	}
+	_, err := RegisterBean("optional", reflect.TypeOf((*OptionalConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("optional").(*OptionalConsumer).Metrics)
+	Reset()
+	_, err = RegisterBean("required", reflect.TypeOf((*RequiredConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no candidates found for the injection")
+}
+
+func (suite *TestSuite) TestDefaultTagCantBeUsedWithOnMissing() {
+	type Consumer struct {
+		Metrics Metrics `di.inject:"" di.default:"noopMetrics" di.onMissing:"nil"`
+	}
+	_, err := RegisterBean("consumer", reflect.TypeOf((*Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "di.default and di.onMissing can't be used together")
+}
+
+type CompositeMetrics struct {
+	Delegates []Metrics `di.inject:""`
+}
+
+func (*CompositeMetrics) Count(string) {}
+
+type LoggingMetrics struct {
+	Delegate Metrics `di.inject:""`
+}
+
+func (*LoggingMetrics) Count(string) {}
+
+func (suite *TestSuite) TestSelfIsExcludedFromCandidates() {
+	_, err := RegisterBean("compositeMetrics", reflect.TypeOf((*CompositeMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("loggingMetrics", reflect.TypeOf((*LoggingMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("noopMetrics", reflect.TypeOf((*NoopMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prometheusMetrics", reflect.TypeOf((*PrometheusMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	MarkPrimary("compositeMetrics")
+	SetAmbiguityPolicy(PreferPrimary)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	composite := GetInstance("compositeMetrics").(*CompositeMetrics)
+	assert.Len(suite.T(), composite.Delegates, 3)
+	for _, delegate := range composite.Delegates {
+		assert.NotSame(suite.T(), composite, delegate)
+	}
+	assert.Same(suite.T(), composite, GetInstance("loggingMetrics").(*LoggingMetrics).Delegate)
+}
+
+func (suite *TestSuite) TestSelfCanBeIncludedInCandidates() {
+	type SelfAwareMetrics struct {
+		Metrics
+		All []Metrics `di.inject:"" di.includeSelf:"true"`
+	}
+	_, err := RegisterBean("selfAwareMetrics", reflect.TypeOf((*SelfAwareMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("noopMetrics", reflect.TypeOf((*NoopMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	instance := GetInstance("selfAwareMetrics").(*SelfAwareMetrics)
+	assert.Len(suite.T(), instance.All, 2)
+	assert.Contains(suite.T(), instance.All, instance)
+}
+
+func (suite *TestSuite) TestInvalidIncludeSelfValue() {
+	type Consumer struct {
+		Metrics Metrics `di.inject:"" di.includeSelf:"maybe"`
+	}
+	_, err := RegisterBean("consumer", reflect.TypeOf((*Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "invalid di.includeSelf value: maybe")
+}
+
+type MetricsAggregator struct {
+	Metrics    []Metrics          `di.inject:"" di.exclude:"noopMetrics, legacyMetrics"`
+	MetricsMap map[string]Metrics `di.inject:"" di.exclude:"noop"`
+}
+
+func (suite *TestSuite) TestExcludeFromCollectionInjection() {
+	_, err := RegisterBean("aggregator", reflect.TypeOf((*MetricsAggregator)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("noopMetrics", reflect.TypeOf((*NoopMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prometheusMetrics", reflect.TypeOf((*PrometheusMetrics)(nil)))
+	assert.NoError(suite.T(), err)
+	err = RegisterAlias("noop", "noopMetrics")
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	aggregator := GetInstance("aggregator").(*MetricsAggregator)
+	assert.Equal(suite.T(), []Metrics{GetInstance("prometheusMetrics").(Metrics)}, aggregator.Metrics)
+	assert.Equal(suite.T(), map[string]Metrics{"prometheusMetrics": GetInstance("prometheusMetrics").(Metrics)},
+		aggregator.MetricsMap)
+}
+
+func (suite *TestSuite) TestExcludeIsOnlySupportedForCollections() {
+	type Consumer struct {
+		Metrics Metrics `di.inject:"" di.exclude:"noopMetrics"`
+	}
+	_, err := RegisterBean("consumer", reflect.TypeOf((*Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "di.exclude can only be used for slices and maps: Metrics")
+}
+
+type CircularSingletonA struct {
+	B *CircularSingletonB `di.inject:"b"`
+}
+
+type CircularSingletonB struct {
+	A    *CircularSingletonA `di.inject:""`
+	Pair *resolvedPair       `di.inject:"pair"`
+}
+
+func (suite *TestSuite) TestCircularSingletonDependencies() {
+	_, err := RegisterBean("a", reflect.TypeOf((*CircularSingletonA)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("b", reflect.TypeOf((*CircularSingletonB)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("pair", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		b, err := Resolve[*CircularSingletonB](deps, "b")
+		return &resolvedPair{singleton: b}, err
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	a := GetInstance("a").(*CircularSingletonA)
+	b := GetInstance("b").(*CircularSingletonB)
+	assert.Same(suite.T(), b, a.B)
+	assert.Same(suite.T(), a, b.A)
+	assert.Same(suite.T(), GetInstance("pair"), b.Pair)
+	assert.Same(suite.T(), b, b.Pair.singleton)
+}
+
+func (suite *TestSuite) TestPanicInBeanFactory() {
+	_, err := RegisterBeanFactory("panickingBean", Singleton, func(context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	var beanPanicError *BeanPanicError
+	if assert.ErrorAs(suite.T(), err, &beanPanicError) {
+		assert.Equal(suite.T(), "panickingBean", beanPanicError.BeanID)
+		assert.Equal(suite.T(), "factory", beanPanicError.Phase)
+		assert.Equal(suite.T(), "boom", beanPanicError.Value)
+		assert.Contains(suite.T(), beanPanicError.Stack, "TestPanicInBeanFactory")
+	}
+	assert.EqualError(suite.T(), err, "panic in factory of bean panickingBean: boom")
+}
+
+func (suite *TestSuite) TestPanicInResolvedBeanFactory() {
+	_, err := RegisterBeanFactory("panickingBean", Prototype, func(context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("consumer", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("panickingBean")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "panic in factory of bean panickingBean: boom")
+}
+
+type panickingInitializingBean struct{}
+
+var errPostConstruct = errors.New("can't initialize")
+
+func (*panickingInitializingBean) PostConstruct() error {
+	panic(errPostConstruct)
+}
+
+func (suite *TestSuite) TestPanicInPostConstruct() {
+	_, err := RegisterBean("panickingBean", reflect.TypeOf((*panickingInitializingBean)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	var beanPanicError *BeanPanicError
+	if assert.ErrorAs(suite.T(), err, &beanPanicError) {
+		assert.Equal(suite.T(), "panickingBean", beanPanicError.BeanID)
+		assert.Equal(suite.T(), "PostConstruct", beanPanicError.Phase)
+	}
+	assert.ErrorIs(suite.T(), err, errPostConstruct)
+	assert.EqualError(suite.T(), err, "panic in PostConstruct of bean panickingBean: can't initialize")
+}
+
+func (suite *TestSuite) TestPanicInRequestBeanFactory() {
+	_, err := RegisterBeanFactory("panickingBean", Request, func(context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	scope, ok := RequestScopeFromContext(ctx)
+	if assert.True(suite.T(), ok) {
+		_, err = scope.Get("panickingBean")
+		assert.EqualError(suite.T(), err, "panic in factory of bean panickingBean: boom")
+	}
+}
+
+type pipelineEvent struct {
+	name string
+}
+
+type pipelinePublisher struct {
+	events chan<- pipelineEvent `di.inject:""`
+}
+
+type pipelineWorker struct {
+	events <-chan pipelineEvent `di.inject:"events"`
+}
+
+func (suite *TestSuite) TestChannelBeans() {
+	overwritten, err := RegisterBeanInstance("events", make(chan pipelineEvent, 1))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("publisher", reflect.TypeOf((*pipelinePublisher)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("worker", reflect.TypeOf((*pipelineWorker)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	GetInstance("publisher").(*pipelinePublisher).events <- pipelineEvent{name: "created"}
+	assert.Equal(suite.T(), pipelineEvent{name: "created"}, <-GetInstance("worker").(*pipelineWorker).events)
+	assert.Equal(suite.T(), []string{"events"}, GetBeanIDsOf[<-chan pipelineEvent]())
+}
+
+func (suite *TestSuite) TestChannelBeanFactory() {
+	_, err := RegisterTypedBeanFactory("events", Singleton, func(context.Context) (chan pipelineEvent, error) {
+		return make(chan pipelineEvent), nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("publisher", reflect.TypeOf((*pipelinePublisher)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("worker", reflect.TypeOf((*pipelineWorker)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	go func() {
+		GetInstance("publisher").(*pipelinePublisher).events <- pipelineEvent{name: "created"}
+	}()
+	assert.Equal(suite.T(), "created", (<-GetInstance("worker").(*pipelineWorker).events).name)
+}
+
+func (suite *TestSuite) TestNilChannelBean() {
+	var events chan pipelineEvent
+	overwritten, err := RegisterBeanInstance("events", events)
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, "bean instance is a nil pointer: events")
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package dido provides the adapter that registers samber/do providers on the container, so that libraries shipping
+// samber/do providers can be consumed without re-wrapping every constructor.
+package dido
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/goioc/di"
+	"github.com/samber/do"
+)
+
+var servicesLock sync.Mutex
+
+// services maps names of samber/do services to the functions declaring them in the injectors passed to the providers:
+// the declared services are resolved from the container.
+var services = make(map[string]func(injector *do.Injector, deps di.Resolver))
+
+// Provide function registers the samber/do provider as a Singleton bean, with the ID derived from the type it provides
+// (see di.BeanIDOf). The injector passed to the provider resolves services from the container: the ones registered with
+// Provide and ProvideNamed, and the beans made available with Expose and ExposeNamed. So `do.Invoke[T]` and
+// `do.InvokeNamed[T]` work as usual, while instances are created and kept by the container. Note that Shutdown method
+// of `do.Shutdownable` services is not called by the container: they should implement io.Closer to be closed.
+func Provide[T any](provider do.Provider[T]) error {
+	beanID := di.BeanIDOfT[T]()
+	if beanID == "" {
+		return errors.New("can't derive bean ID from unnamed type: " + reflect.TypeOf((*T)(nil)).Elem().String())
+	}
+	return provide(serviceName[T](), beanID, provider)
+}
+
+// ProvideNamed function registers the samber/do provider of the named service (see `do.ProvideNamed`) as a Singleton
+// bean with the ID equal to the name of the service (see Provide).
+func ProvideNamed[T any](name string, provider do.Provider[T]) error {
+	return provide(name, name, provider)
+}
+
+// Expose function makes the bean of the container available to samber/do providers as the service of type `T`, i.e. via
+// `do.Invoke[T]`.
+func Expose[T any](beanID string) {
+	declare[T](serviceName[T](), beanID)
+}
+
+// ExposeNamed function makes the bean of the container available to samber/do providers as the named service, i.e. via
+// `do.InvokeNamed[T]`.
+func ExposeNamed[T any](name string, beanID string) {
+	declare[T](name, beanID)
+}
+
+func provide[T any](name string, beanID string, provider do.Provider[T]) error {
+	if provider == nil {
+		return errors.New("provider can't be nil")
+	}
+	_, err := di.RegisterResolvingBeanFactory(beanID, di.Singleton,
+		func(_ context.Context, deps di.Resolver) (interface{}, error) {
+			return provider(newInjector(deps))
+		})
+	if err != nil {
+		return err
+	}
+	declare[T](name, beanID)
+	return nil
+}
+
+func declare[T any](name string, beanID string) {
+	servicesLock.Lock()
+	defer servicesLock.Unlock()
+	services[name] = func(injector *do.Injector, deps di.Resolver) {
+		do.ProvideNamed(injector, name, func(*do.Injector) (T, error) {
+			instance, err := deps.Resolve(beanID)
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			typedInstance, ok := instance.(T)
+			if !ok && instance != nil {
+				return typedInstance, fmt.Errorf("bean %s is of type %T, not %s", beanID, instance,
+					reflect.TypeOf((*T)(nil)).Elem())
+			}
+			return typedInstance, nil
+		})
+	}
+}
+
+// newInjector function creates the injector passed to the provider, with all the known services declared in it.
+func newInjector(deps di.Resolver) *do.Injector {
+	injector := do.New()
+	servicesLock.Lock()
+	defer servicesLock.Unlock()
+	for _, declare := range services {
+		declare(injector, deps)
+	}
+	return injector
+}
+
+// serviceName function returns the name samber/do gives to the services of type `T`.
+func serviceName[T any]() string {
+	var zero T
+	if name := fmt.Sprintf("%T", zero); name != "<nil>" {
+		return name
+	}
+	return fmt.Sprintf("%T", new(T))
+}
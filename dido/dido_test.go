@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package dido
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/goioc/di"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+)
+
+type config struct {
+	url string
+}
+
+type database struct {
+	config *config
+}
+
+type repository interface {
+	URL() string
+}
+
+type sqlRepository struct {
+	database *database
+}
+
+func (sr *sqlRepository) URL() string {
+	return sr.database.config.url
+}
+
+type metrics struct{}
+
+type service struct {
+	repository repository
+	metrics    *metrics
+}
+
+type consumer struct {
+	repository repository `di.inject:""`
+}
+
+func TestProvide(t *testing.T) {
+	defer di.Close()
+	_, err := di.RegisterBeanInstance("config", &config{url: "postgres://do"})
+	assert.NoError(t, err)
+	Expose[*config]("config")
+	assert.NoError(t, Provide(func(i *do.Injector) (*database, error) {
+		return &database{config: do.MustInvoke[*config](i)}, nil
+	}))
+	assert.NoError(t, Provide(func(i *do.Injector) (repository, error) {
+		database, err := do.Invoke[*database](i)
+		return &sqlRepository{database: database}, err
+	}))
+	assert.NoError(t, ProvideNamed("metrics", func(*do.Injector) (*metrics, error) {
+		return &metrics{}, nil
+	}))
+	assert.NoError(t, Provide(func(i *do.Injector) (*service, error) {
+		return &service{
+			repository: do.MustInvoke[repository](i),
+			metrics:    do.MustInvokeNamed[*metrics](i, "metrics"),
+		}, nil
+	}))
+	_, err = di.RegisterBean("consumer", reflect.TypeOf((*consumer)(nil)))
+	assert.NoError(t, err)
+	err = di.InitializeContainer()
+	assert.NoError(t, err)
+	service := di.GetInstance(di.BeanIDOfT[*service]()).(*service)
+	assert.Equal(t, "postgres://do", service.repository.URL())
+	assert.Same(t, di.GetInstance(di.BeanIDOfT[repository]()), service.repository)
+	assert.Same(t, di.GetInstance("metrics"), service.metrics)
+	assert.Same(t, service.repository, di.GetInstance("consumer").(*consumer).repository)
+}
+
+func TestProvideErrors(t *testing.T) {
+	defer di.Close()
+	assert.Error(t, Provide[*service](nil))
+	assert.Error(t, Provide(func(*do.Injector) ([]string, error) { return nil, nil }))
+	assert.NoError(t, Provide(func(*do.Injector) (*database, error) {
+		return nil, errors.New("connection refused")
+	}))
+	assert.NoError(t, Provide(func(i *do.Injector) (*service, error) {
+		_, err := do.Invoke[*database](i)
+		return &service{}, err
+	}))
+	err := di.InitializeContainer()
+	assert.ErrorContains(t, err, "connection refused")
+}
@@ -0,0 +1,20 @@
+module github.com/goioc/di/dido
+
+go 1.22
+
+require (
+	github.com/goioc/di v1.7.1
+	github.com/samber/do v1.6.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/goioc/di => ../
@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package diecho provides the adapter that enables Request-scoped beans for the Echo web framework.
+package diecho
+
+import (
+	"github.com/goioc/di"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware returns Echo middleware that performs Request-scoped beans injection into the context of the request (same
+// as di.Middleware does for net/http handlers).
+func Middleware() echo.MiddlewareFunc {
+	return echo.WrapMiddleware(di.Middleware)
+}
+
+// Get returns the Request-scoped bean from the request context of Echo. The returned boolean is `false` if there's no
+// such bean in the context, or if it can't be converted to `T`.
+func Get[T any](c echo.Context, beanID string) (T, bool) {
+	return di.FromContext[T](c.Request().Context(), beanID)
+}
+
+// MustGet returns the Request-scoped bean from the request context of Echo. It panics if there's no such bean in the
+// context, or if it can't be converted to `T`.
+func MustGet[T any](c echo.Context, beanID string) T {
+	return di.MustFromContext[T](c.Request().Context(), beanID)
+}
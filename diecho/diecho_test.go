@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package diecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/goioc/di"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestBean struct {
+	Scope di.Scope `di.scope:"request"`
+}
+
+func TestMiddleware(t *testing.T) {
+	defer di.Close()
+	_, err := di.RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.NoError(t, err)
+	err = di.InitializeContainer()
+	assert.NoError(t, err)
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/", func(c echo.Context) error {
+		requestBeanInstance, ok := Get[*requestBean](c, "requestBean")
+		assert.True(t, ok)
+		assert.NotNil(t, requestBeanInstance)
+		_, ok = Get[*requestBean](c, "otherBean")
+		assert.False(t, ok)
+		assert.Equal(t, requestBeanInstance, MustGet[*requestBean](c, "requestBean"))
+		assert.Panics(t, func() {
+			MustGet[*requestBean](c, "otherBean")
+		})
+		return c.NoContent(http.StatusOK)
+	})
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
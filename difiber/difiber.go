@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package difiber provides the adapter that enables Request-scoped beans for the Fiber web framework.
+package difiber
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/goioc/di"
+)
+
+// Middleware returns Fiber handler that performs Request-scoped beans injection into the user context of the request
+// (see fiber.Ctx.UserContext). The scope ends once the rest of the handlers chain returns, so that io.Closer beans get
+// closed.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, done := di.BeginScope(c.UserContext())
+		defer done()
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// Get returns the Request-scoped bean from the user context of Fiber. The returned boolean is `false` if there's no
+// such bean in the context, or if it can't be converted to `T`.
+func Get[T any](c *fiber.Ctx, beanID string) (T, bool) {
+	return di.FromContext[T](c.UserContext(), beanID)
+}
+
+// MustGet returns the Request-scoped bean from the user context of Fiber. It panics if there's no such bean in the
+// context, or if it can't be converted to `T`.
+func MustGet[T any](c *fiber.Ctx, beanID string) T {
+	return di.MustFromContext[T](c.UserContext(), beanID)
+}
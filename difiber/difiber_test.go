@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package difiber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/goioc/di"
+	"github.com/stretchr/testify/assert"
+)
+
+var closed atomic.Bool
+
+type requestBean struct {
+	Scope di.Scope `di.scope:"request"`
+}
+
+func (*requestBean) Close() error {
+	closed.Store(true)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	defer di.Close()
+	_, err := di.RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.NoError(t, err)
+	err = di.InitializeContainer()
+	assert.NoError(t, err)
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		requestBeanInstance, ok := Get[*requestBean](c, "requestBean")
+		assert.True(t, ok)
+		assert.NotNil(t, requestBeanInstance)
+		_, ok = Get[*requestBean](c, "otherBean")
+		assert.False(t, ok)
+		assert.Equal(t, requestBeanInstance, MustGet[*requestBean](c, "requestBean"))
+		assert.Panics(t, func() {
+			MustGet[*requestBean](c, "otherBean")
+		})
+		return c.SendStatus(http.StatusOK)
+	})
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Eventually(t, func() bool { return closed.Load() }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package difx provides the adapter that translates uber/fx options into registrations on the container, so that
+// libraries shipping fx modules can be consumed without re-wrapping every constructor.
+package difx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/goioc/di"
+	"go.uber.org/fx"
+)
+
+var lifecycleType = reflect.TypeOf((*fx.Lifecycle)(nil)).Elem()
+var inType = reflect.TypeOf(fx.In{})
+var outType = reflect.TypeOf(fx.Out{})
+
+// LifecycleBeanID is the ID of the bean implementing fx.Lifecycle, it's registered once some of the translated
+// constructors or invoked functions depend on fx.Lifecycle.
+var LifecycleBeanID = di.BeanIDOf(lifecycleType)
+
+// Register function translates fx options into registrations on the container:
+//   - constructors of fx.Provide and values of fx.Supply are registered as Singleton beans with IDs derived from the
+//     types they provide (see di.RegisterProviders), fx.Annotated constructors with a name are registered under it;
+//   - functions of fx.Invoke are registered as invokers (see di.RegisterInvoker);
+//   - fx.Options and fx.Module are flattened (constructors private to a module are visible to the whole container);
+//   - hooks appended to fx.Lifecycle are run when the container starts and stops its Lifecycle beans (see di.Start,
+//     di.Stop and di.Run).
+//
+// Other options (e.g. fx.Decorate, fx.Replace or fx.WithLogger), value groups, fx.Annotate and parameter objects
+// (fx.In and fx.Out) are not supported: they are reported as errors before anything is registered.
+func Register(opts ...fx.Option) error {
+	t := &translation{}
+	for _, opt := range opts {
+		if err := t.add(opt); err != nil {
+			return err
+		}
+	}
+	return t.register()
+}
+
+type namedProvider struct {
+	name     string
+	provider interface{}
+}
+
+// translation collects the registrations the fx options are translated into.
+type translation struct {
+	providers      []interface{}
+	namedProviders []namedProvider
+	invokers       []interface{}
+	needsLifecycle bool
+}
+
+func (t *translation) add(opt fx.Option) error {
+	if opt == nil {
+		return errors.New("fx option can't be nil")
+	}
+	value := reflect.ValueOf(opt)
+	if value.Type().PkgPath() != "go.uber.org/fx" {
+		return errors.New("unsupported fx option: " + value.Type().String())
+	}
+	switch value.Type().Name() {
+	case "optionGroup":
+		for i := 0; i < value.Len(); i++ {
+			if err := t.add(value.Index(i).Interface().(fx.Option)); err != nil {
+				return err
+			}
+		}
+	case "moduleOption":
+		for _, moduleOpt := range moduleOptions(value) {
+			if err := t.add(moduleOpt); err != nil {
+				return err
+			}
+		}
+	case "provideOption", "supplyOption":
+		for _, target := range value.FieldByName("Targets").Interface().([]interface{}) {
+			if err := t.addProvider(target); err != nil {
+				return err
+			}
+		}
+	case "invokeOption":
+		for _, target := range value.FieldByName("Targets").Interface().([]interface{}) {
+			if err := t.checkFunction(target); err != nil {
+				return err
+			}
+			t.invokers = append(t.invokers, target)
+		}
+	default:
+		return errors.New("unsupported fx option: " + fmt.Sprint(opt))
+	}
+	return nil
+}
+
+// moduleOptions function returns the options of fx.Module: they are kept in the unexported field.
+func moduleOptions(module reflect.Value) []fx.Option {
+	addressable := reflect.New(module.Type()).Elem()
+	addressable.Set(module)
+	field := addressable.FieldByName("options")
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface().([]fx.Option)
+}
+
+func (t *translation) addProvider(target interface{}) error {
+	if target == interface{}(fx.Private) {
+		return nil
+	}
+	if annotated, ok := target.(fx.Annotated); ok {
+		if annotated.Group != "" {
+			return errors.New("value groups are not supported: " + annotated.String())
+		}
+		if err := t.checkFunction(annotated.Target); err != nil {
+			return err
+		}
+		if annotated.Name != "" {
+			t.namedProviders = append(t.namedProviders, namedProvider{name: annotated.Name, provider: annotated.Target})
+		} else {
+			t.providers = append(t.providers, annotated.Target)
+		}
+		return nil
+	}
+	if err := t.checkFunction(target); err != nil {
+		return err
+	}
+	t.providers = append(t.providers, target)
+	return nil
+}
+
+// checkFunction method checks that the constructor or the invoked function can be translated, and whether it depends
+// on fx.Lifecycle.
+func (t *translation) checkFunction(function interface{}) error {
+	functionType := reflect.TypeOf(function)
+	if functionType == nil || functionType.Kind() != reflect.Func {
+		return fmt.Errorf("unsupported fx constructor or function: %v (fx.Annotate is not supported)", function)
+	}
+	for i := 0; i < functionType.NumIn(); i++ {
+		if isParameterObject(functionType.In(i), inType) {
+			return errors.New("parameter objects (fx.In) are not supported: " + functionType.String())
+		}
+		if functionType.In(i) == lifecycleType {
+			t.needsLifecycle = true
+		}
+	}
+	for i := 0; i < functionType.NumOut(); i++ {
+		if isParameterObject(functionType.Out(i), outType) {
+			return errors.New("result objects (fx.Out) are not supported: " + functionType.String())
+		}
+	}
+	return nil
+}
+
+func isParameterObject(paramType reflect.Type, embeddedType reflect.Type) bool {
+	if paramType.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < paramType.NumField(); i++ {
+		if field := paramType.Field(i); field.Anonymous && field.Type == embeddedType {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *translation) register() error {
+	if _, registered := di.GetBeanScopes()[LifecycleBeanID]; t.needsLifecycle && !registered {
+		if _, err := di.RegisterProvider(LifecycleBeanID, di.Singleton, newLifecycle); err != nil {
+			return err
+		}
+	}
+	if err := di.RegisterProviders(di.Singleton, t.providers...); err != nil {
+		return err
+	}
+	for _, namedProvider := range t.namedProviders {
+		if _, err := di.RegisterProvider(namedProvider.name, di.Singleton, namedProvider.provider); err != nil {
+			return err
+		}
+	}
+	for _, invoker := range t.invokers {
+		if err := di.RegisterInvoker(invoker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lifecycle implements fx.Lifecycle on top of di.Lifecycle: hooks appended by the constructors are run when the
+// container starts and stops its Lifecycle beans.
+type lifecycle struct {
+	lock    sync.Mutex
+	hooks   []fx.Hook
+	started int
+}
+
+func newLifecycle() fx.Lifecycle {
+	return &lifecycle{}
+}
+
+// Append method adds the hook, hooks are started in order of appending and stopped in reverse order.
+func (l *lifecycle) Append(hook fx.Hook) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// Start method runs OnStart hooks. If some hook fails, hooks that have been started already are stopped.
+func (l *lifecycle) Start(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for i, hook := range l.hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				return errors.Join(err, l.stop(ctx))
+			}
+		}
+		l.started = i + 1
+	}
+	return nil
+}
+
+// Stop method runs OnStop hooks of the started hooks in reverse order. All the hooks are stopped even if some of them
+// fail, returned error joins errors of all failed hooks.
+func (l *lifecycle) Stop(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.stop(ctx)
+}
+
+func (l *lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		if hook := l.hooks[l.started-1]; hook.OnStop != nil {
+			if err := hook.OnStop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package difx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goioc/di"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type config struct {
+	url string
+}
+
+type database struct {
+	config *config
+}
+
+type repository struct {
+	database *database
+}
+
+type cache struct {
+	name string
+}
+
+type eventLog struct {
+	events []string
+}
+
+func newDatabase(config *config, lifecycle fx.Lifecycle, log *eventLog) *database {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.events = append(log.events, "connect "+config.url)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log.events = append(log.events, "disconnect "+config.url)
+			return nil
+		},
+	})
+	return &database{config: config}
+}
+
+func newRepository(database *database) (*repository, error) {
+	return &repository{database: database}, nil
+}
+
+func TestRegister(t *testing.T) {
+	defer di.Close()
+	log := &eventLog{}
+	var invoked *repository
+	err := Register(
+		fx.Supply(&config{url: "postgres://fx"}, log),
+		fx.Module("storage",
+			fx.Provide(newDatabase, fx.Private),
+			fx.Provide(newRepository),
+		),
+		fx.Provide(fx.Annotated{Name: "sessions", Target: func() *cache { return &cache{name: "sessions"} }}),
+		fx.Options(fx.Invoke(func(repository *repository) {
+			invoked = repository
+		})),
+	)
+	assert.NoError(t, err)
+	err = di.InitializeContainer()
+	assert.NoError(t, err)
+	repository := di.GetInstance(di.BeanIDOfT[*repository]()).(*repository)
+	assert.Same(t, repository, invoked)
+	assert.Equal(t, "postgres://fx", repository.database.config.url)
+	assert.Equal(t, "sessions", di.GetInstance("sessions").(*cache).name)
+	assert.NoError(t, di.Start(context.Background()))
+	assert.NoError(t, di.Stop(context.Background()))
+	assert.Equal(t, []string{"connect postgres://fx", "disconnect postgres://fx"}, log.events)
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	var log []string
+	l := newLifecycle().(*lifecycle)
+	l.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log = append(log, "start first")
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log = append(log, "stop first")
+			return nil
+		},
+	})
+	l.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return errors.New("port is busy")
+		},
+	})
+	err := l.Start(context.Background())
+	assert.ErrorContains(t, err, "port is busy")
+	assert.Equal(t, []string{"start first", "stop first"}, log)
+	assert.NoError(t, l.Stop(context.Background()))
+	assert.Equal(t, []string{"start first", "stop first"}, log)
+}
+
+type parameters struct {
+	fx.In
+	Database *database
+}
+
+func TestRegisterUnsupportedOptions(t *testing.T) {
+	defer di.Close()
+	for _, opt := range []fx.Option{
+		fx.Decorate(func(database *database) *database { return database }),
+		fx.Provide(fx.Annotated{Group: "caches", Target: func() *cache { return &cache{} }}),
+		fx.Provide(fx.Annotate(newRepository, fx.ResultTags(`name:"repository"`))),
+		fx.Invoke(func(parameters) {}),
+		fx.NopLogger,
+	} {
+		assert.Error(t, Register(fx.Provide(newRepository), opt), opt.String())
+	}
+	assert.Empty(t, di.GetBeanScopes())
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package digrpc provides gRPC server interceptors that enable Request-scoped beans for RPC calls.
+package digrpc
+
+import (
+	"context"
+
+	"github.com/goioc/di"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns a unary server interceptor that creates Request-scoped beans for each RPC and puts
+// them into the call context (see di.RequestBean). Beans implementing io.Closer are closed once the RPC finishes.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		withRequestBeans(ctx, func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that creates Request-scoped beans for each RPC and puts
+// them into the stream context (see di.RequestBean). Beans implementing io.Closer are closed once the RPC finishes.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		withRequestBeans(ss.Context(), func(ctx context.Context) {
+			err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// serverStream overrides the context of the wrapped grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// withRequestBeans begins the scope of Request-scoped beans for the call (see di.BeginScope) and ends it after the call
+// returns, so that io.Closer beans get closed.
+func withRequestBeans(ctx context.Context, call func(ctx context.Context)) {
+	ctx, done := di.BeginScope(ctx)
+	defer done()
+	call(ctx)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package digrpc
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/goioc/di"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+var closed atomic.Int32
+
+type requestBean struct {
+	Scope di.Scope `di.scope:"request"`
+}
+
+func (*requestBean) Close() error {
+	closed.Add(1)
+	return nil
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+}
+
+func (*testServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestInterceptors(t *testing.T) {
+	defer di.Close()
+	_, err := di.RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.NoError(t, err)
+	err = di.InitializeContainer()
+	assert.NoError(t, err)
+	resp, err := UnaryServerInterceptor()(context.Background(), "request", &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestBeanInstance, ok := ctx.Value(di.BeanKey("requestBean")).(*requestBean)
+			assert.True(t, ok)
+			assert.NotNil(t, requestBeanInstance)
+			return req, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "request", resp)
+	err = StreamServerInterceptor()(nil, &testServerStream{}, &grpc.StreamServerInfo{},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			requestBeanInstance, ok := stream.Context().Value(di.BeanKey("requestBean")).(*requestBean)
+			assert.True(t, ok)
+			assert.NotNil(t, requestBeanInstance)
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), closed.Load())
+}
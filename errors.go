@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// AmbiguousDependencyError is returned when more than one bean can be injected by type into the field of the bean.
+// It can be retrieved from the returned error with errors.As.
+type AmbiguousDependencyError struct {
+	// BeanID is the ID of the bean the dependency is injected into.
+	BeanID string
+	// Field is the name of the field the dependency is injected into.
+	Field string
+	// Type is the type of the dependency.
+	Type reflect.Type
+	// Candidates are IDs of the beans assignable to the type of the dependency (sorted).
+	Candidates []string
+}
+
+func (e *AmbiguousDependencyError) Error() string {
+	return "more than one candidate found for the injection into field " + e.Field + " of bean " + e.BeanID +
+		" (" + e.Type.String() + "): " + strings.Join(e.Candidates, ", ")
+}
+
+// CircularDependencyError is returned when the bean (directly or indirectly) depends on itself. It can be retrieved
+// from the returned error with errors.As.
+type CircularDependencyError struct {
+	// BeanID is the ID of the bean that closes the cycle.
+	BeanID string
+	// Chain is the cycle of beans, starting and ending with BeanID. Each bean is followed by the name of the field its
+	// dependency is injected into (e.g. "a.B"), unless the dependency is resolved by the bean factory.
+	Chain []string
+}
+
+func (e *CircularDependencyError) Error() string {
+	return "circular dependency detected for bean: " + e.BeanID + " (" + strings.Join(e.Chain, " -> ") + ")"
+}
+
+// ResolutionDepthError is returned when the chain of beans being created gets longer than the maximum resolution depth
+// (see SetMaxResolutionDepth). It can be retrieved from the returned error with errors.As.
+type ResolutionDepthError struct {
+	// BeanID is the ID of the bean that exceeds the depth.
+	BeanID string
+	// MaxDepth is the maximum resolution depth.
+	MaxDepth int
+	// Chain is the chain of beans being created, ending with BeanID, in the same format as CircularDependencyError.Chain.
+	Chain []string
+}
+
+func (e *ResolutionDepthError) Error() string {
+	return "maximum resolution depth of " + strconv.Itoa(e.MaxDepth) + " exceeded for bean: " + e.BeanID + " (" +
+		strings.Join(e.Chain, " -> ") + ")"
+}
+
+// BeanPanicError is returned when the bean factory or PostConstruct method of the bean panics. It can be retrieved from
+// the returned error with errors.As.
+type BeanPanicError struct {
+	// BeanID is the ID of the bean.
+	BeanID string
+	// Phase is the phase of the bean creation the panic happened in: "factory" or "PostConstruct".
+	Phase string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine at the moment of the panic.
+	Stack string
+}
+
+func (e *BeanPanicError) Error() string {
+	return fmt.Sprintf("panic in %s of bean %s: %v", e.Phase, e.BeanID, e.Value)
+}
+
+// Unwrap method returns the value passed to panic, if it's an error.
+func (e *BeanPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// BeanValidationError is returned when the bean fails the validation (see SetBeanValidator). It can be retrieved from
+// the returned error with errors.As, as well as the error of the validator (e.g. `validator.ValidationErrors` listing
+// the invalid fields).
+type BeanValidationError struct {
+	// BeanID is the ID of the bean.
+	BeanID string
+	// Err is the error returned by the validator.
+	Err error
+}
+
+func (e *BeanValidationError) Error() string {
+	return "validation of bean " + e.BeanID + " failed: " + e.Err.Error()
+}
+
+// Unwrap method returns the error returned by the validator.
+func (e *BeanValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventPublisherBeanID is an ID of the built-in EventPublisher bean.
+const EventPublisherBeanID = "eventPublisher"
+
+// EventListener is an interface marking singleton beans that listen to all application events. Beans can also listen to
+// the events of specific types only, by declaring a typed method instead, e.g. `OnEvent(event *UserCreated) error`.
+type EventListener interface {
+	// OnEvent method is called upon every event published with EventPublisher.
+	OnEvent(event interface{}) error
+}
+
+// EventPublisher is a built-in bean that publishes application events to the singleton beans listening to them (see
+// EventListener). Listeners are discovered automatically among the singletons of the initialized container (lazy
+// singletons aren't discovered) and are called in order of their IDs. It can be injected by type: `di.inject:""`.
+type EventPublisher struct {
+	listeners atomic.Pointer[eventListeners]
+}
+
+// eventListeners are listeners found among the published singletons: they are found again once the singletons are
+// published anew (e.g. when the container is reset and initialized again).
+type eventListeners struct {
+	singletons *singletonsView
+	listeners  []eventListener
+}
+
+type eventListener struct {
+	beanID    string
+	eventType reflect.Type
+	onEvent   reflect.Value
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// RegisterEventPublisher function registers the EventPublisher bean in the container under the EventPublisherBeanID.
+func RegisterEventPublisher() (overwritten bool, err error) {
+	return RegisterBeanInstance(EventPublisherBeanID, &EventPublisher{})
+}
+
+// Publish method synchronously dispatches the event to the listeners. Dispatching stops upon the first error returned
+// by a listener. Events can't be published until the container is initialized (e.g. from PostConstruct methods).
+func (ep *EventPublisher) Publish(event interface{}) error {
+	if event == nil {
+		return errors.New("event can't be nil")
+	}
+	listeners, err := ep.getListeners(reflect.TypeOf(event))
+	if err != nil {
+		return err
+	}
+	arguments := []reflect.Value{reflect.ValueOf(event)}
+	for _, listener := range listeners {
+		if isTracing() {
+			logrus.WithField("beanID", listener.beanID).Trace("dispatching event")
+		}
+		result := listener.onEvent.Call(arguments)[0]
+		if !result.IsNil() {
+			return result.Interface().(error)
+		}
+	}
+	return nil
+}
+
+// PublishAsync method dispatches the event to the listeners in a separate goroutine. Errors returned by listeners are
+// logged.
+func (ep *EventPublisher) PublishAsync(event interface{}) {
+	go func() {
+		if err := ep.Publish(event); err != nil {
+			logrus.WithField("event", event).Error(err)
+		}
+	}()
+}
+
+func (ep *EventPublisher) getListeners(eventType reflect.Type) ([]eventListener, error) {
+	singletons := initializedSingletons.Load()
+	if singletons == nil {
+		return nil, errors.New("container is not initialized: can't publish events yet")
+	}
+	cached := ep.listeners.Load()
+	if cached == nil || cached.singletons != singletons {
+		cached = &eventListeners{singletons: singletons, listeners: findEventListeners(singletons)}
+		ep.listeners.Store(cached)
+	}
+	var listeners []eventListener
+	for _, listener := range cached.listeners {
+		if eventType.AssignableTo(listener.eventType) {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners, nil
+}
+
+// findEventListeners function finds singletons having `OnEvent(event E) error` method.
+func findEventListeners(singletons *singletonsView) []eventListener {
+	var listeners []eventListener
+	for beanID, instance := range singletons.instances {
+		onEvent := reflect.ValueOf(instance).MethodByName("OnEvent")
+		if !onEvent.IsValid() {
+			continue
+		}
+		onEventType := onEvent.Type()
+		if onEventType.NumIn() != 1 || onEventType.NumOut() != 1 || onEventType.Out(0) != errorType {
+			continue
+		}
+		listeners = append(listeners, eventListener{beanID: beanID, eventType: onEventType.In(0), onEvent: onEvent})
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].beanID < listeners[j].beanID
+	})
+	return listeners
+}
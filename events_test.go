@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userCreatedEvent struct {
+	name string
+}
+
+type auditListener struct {
+	lock   sync.Mutex
+	events []interface{}
+}
+
+func (al *auditListener) OnEvent(event interface{}) error {
+	al.lock.Lock()
+	defer al.lock.Unlock()
+	al.events = append(al.events, event)
+	return nil
+}
+
+type welcomeListener struct {
+	names []string
+}
+
+func (wl *welcomeListener) OnEvent(event *userCreatedEvent) error {
+	if event.name == "" {
+		return errors.New("name is empty")
+	}
+	wl.names = append(wl.names, event.name)
+	return nil
+}
+
+type publishingBean struct {
+	publisher *EventPublisher `di.inject:""`
+}
+
+func (suite *TestSuite) TestEventPublisher() {
+	overwritten, err := RegisterEventPublisher()
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	audit := &auditListener{}
+	overwritten, err = RegisterBeanInstance("audit", audit)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	welcome := &welcomeListener{}
+	overwritten, err = RegisterBeanInstance("welcome", welcome)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("publisher", reflect.TypeOf((*publishingBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	publisher := GetInstance("publisher").(*publishingBean).publisher
+	assert.NoError(suite.T(), publisher.Publish(&userCreatedEvent{name: "john"}))
+	assert.NoError(suite.T(), publisher.Publish("started"))
+	assert.Error(suite.T(), publisher.Publish(nil))
+	assert.Equal(suite.T(), []string{"john"}, welcome.names)
+	assert.Equal(suite.T(), []interface{}{&userCreatedEvent{name: "john"}, "started"}, audit.events)
+	assert.EqualError(suite.T(), publisher.Publish(&userCreatedEvent{}), "name is empty")
+	audit.lock.Lock()
+	assert.Len(suite.T(), audit.events, 3)
+	audit.lock.Unlock()
+	publisher.PublishAsync("async")
+	assert.Eventually(suite.T(), func() bool {
+		audit.lock.Lock()
+		defer audit.lock.Unlock()
+		return len(audit.events) == 4 && audit.events[3] == "async"
+	}, time.Second, time.Millisecond)
+}
+
+func (suite *TestSuite) TestEventPublisherListenersFollowContainer() {
+	publisher := &EventPublisher{}
+	assert.EqualError(suite.T(), publisher.Publish("early"), "container is not initialized: can't publish events yet")
+	overwritten, err := RegisterBeanInstance(EventPublisherBeanID, publisher)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	first := &auditListener{}
+	overwritten, err = RegisterBeanInstance("audit", first)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), publisher.Publish("first"))
+	second := &auditListener{}
+	restore := OverrideBeanInstance("audit", second)
+	assert.NoError(suite.T(), publisher.Publish("overridden"))
+	restore()
+	Reset()
+	third := &auditListener{}
+	overwritten, err = RegisterBeanInstance("audit", third)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), publisher.Publish("reinitialized"))
+	assert.Equal(suite.T(), []interface{}{"first"}, first.events)
+	assert.Equal(suite.T(), []interface{}{"overridden"}, second.events)
+	assert.Equal(suite.T(), []interface{}{"reinitialized"}, third.events)
+}
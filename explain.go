@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BeanExplanation is a report on how the dependencies of the bean are resolved, see Explain.
+type BeanExplanation struct {
+	// BeanID is the ID of the bean.
+	BeanID string
+	// Scope of the bean.
+	Scope Scope
+	// Type of the bean, nil if it's unknown (see GetBeanTypes).
+	Type reflect.Type
+	// Injector is set if the bean injects its dependencies itself (see DependencyInjector).
+	Injector bool
+	// Dependencies are explanations of the injected fields, in the order of declaration. Dependencies of the beans
+	// registered as pre-created instances or factories are not injected by the container, so there are none.
+	Dependencies []DependencyExplanation
+}
+
+// DependencyExplanation is a report on how the dependency injected into the field is resolved.
+type DependencyExplanation struct {
+	// Field is the name of the field.
+	Field string
+	// Type is the type of the field.
+	Type reflect.Type
+	// ByType is set if the dependency is resolved by type, rather than by ID.
+	ByType bool
+	// Candidates are IDs of the beans considered for the injection by type or as members of the group (sorted).
+	Candidates []string
+	// Injected are IDs of the beans chosen for the injection (sorted).
+	Injected []string
+	// Reason explains the choice, it's empty if the dependency can't be resolved.
+	Reason string
+	// Err is the error of the resolution, it's returned upon the injection.
+	Err error
+}
+
+// Explain function returns the report on how each dependency of the bean is (or would be) resolved: by ID or by type,
+// which candidates are considered and which are chosen. Types of the Singleton beans produced by factories are only
+// known once the container is initialized, so the report may differ before the initialization.
+func Explain(beanID string) (*BeanExplanation, error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	beanID = resolveAlias(beanID)
+	if !isBeanRegistered(beanID) {
+		return nil, errors.New("bean is not registered: " + beanID)
+	}
+	explanation := &BeanExplanation{BeanID: beanID, Scope: scopes[beanID]}
+	explanation.Type, _ = getBeanType(beanID)
+	beanType, ok := beans[beanID]
+	if !ok || userCreatedInstances[beanID] {
+		return explanation, nil
+	}
+	if beanType.Implements(reflect.TypeOf((*DependencyInjector)(nil)).Elem()) {
+		explanation.Injector = true
+		return explanation, nil
+	}
+	for _, step := range buildInjectionPlan(beanID).steps {
+		explanation.Dependencies = append(explanation.Dependencies, explainInjectionStep(step))
+	}
+	return explanation, nil
+}
+
+func explainInjectionStep(step injectionStep) DependencyExplanation {
+	dependency := DependencyExplanation{
+		Field:      step.field.Name,
+		Type:       step.field.Type,
+		Candidates: append([]string(nil), step.candidates...),
+		Injected:   append([]string(nil), step.beanIDs...),
+		Reason:     step.reason,
+		Err:        step.err,
+	}
+	sort.Strings(dependency.Candidates)
+	sort.Strings(dependency.Injected)
+	switch step.kind {
+	case injectBeanKind, injectProxyKind, injectProviderKind, injectSliceKind, injectMapKind, injectNothingKind:
+		dependency.ByType = inject.get(step.field) == ""
+	}
+	if step.err != nil {
+		dependency.Reason = ""
+	}
+	return dependency
+}
+
+// String method formats the report, one line per dependency.
+func (be *BeanExplanation) String() string {
+	var report strings.Builder
+	report.WriteString("bean " + be.BeanID + " (" + string(be.Scope) + ")")
+	if be.Injector {
+		report.WriteString(": dependencies are injected by the bean itself")
+	}
+	for _, dependency := range be.Dependencies {
+		report.WriteString("\n  " + dependency.Field + " " + dependency.Type.String() + ": ")
+		switch {
+		case dependency.Err != nil:
+			report.WriteString("error: " + dependency.Err.Error())
+		case len(dependency.Injected) > 0:
+			report.WriteString(strings.Join(dependency.Injected, ", ") + " (" + dependency.Reason + ")")
+		default:
+			report.WriteString(dependency.Reason)
+		}
+		if dependency.ByType && dependency.Err == nil {
+			report.WriteString(", candidates: [" + strings.Join(dependency.Candidates, ", ") + "]")
+		}
+	}
+	return report.String()
+}
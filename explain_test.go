@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type explainedBean struct {
+	Scope     Scope              `di.scope:"prototype"`
+	Greeter   greeter            `di.inject:""`
+	English   *englishGreeter    `di.inject:"english"`
+	Greeters  []greeter          `di.inject:""`
+	Optional  *resolvedService   `di.inject:"" di.optional:"true"`
+	Grouped   []greeter          `di.inject.group:"greeters"`
+	Name      string             `di.value:"${name}"`
+	Ambiguous *resolvedPrototype `di.inject:""`
+	Untagged  *englishGreeter
+}
+
+func (suite *TestSuite) TestExplain() {
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("explained", reflect.TypeOf((*explainedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prototype1", reflect.TypeOf((*resolvedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prototype2", reflect.TypeOf((*resolvedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	err = AddToGroup("greeters", "english")
+	assert.NoError(suite.T(), err)
+	explanation, err := Explain("explained")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "explained", explanation.BeanID)
+	assert.Equal(suite.T(), Prototype, explanation.Scope)
+	assert.Equal(suite.T(), reflect.TypeOf((*explainedBean)(nil)), explanation.Type)
+	assert.False(suite.T(), explanation.Injector)
+	assert.Len(suite.T(), explanation.Dependencies, 7)
+	assert.Equal(suite.T(), DependencyExplanation{
+		Field:      "Greeter",
+		Type:       reflect.TypeOf((*greeter)(nil)).Elem(),
+		ByType:     true,
+		Candidates: []string{"english"},
+		Injected:   []string{"english"},
+		Reason:     "the only candidate of the type is injected",
+	}, explanation.Dependencies[0])
+	assert.Equal(suite.T(), DependencyExplanation{
+		Field:    "English",
+		Type:     reflect.TypeOf((*englishGreeter)(nil)),
+		Injected: []string{"english"},
+		Reason:   "the bean is injected by ID",
+	}, explanation.Dependencies[1])
+	assert.True(suite.T(), explanation.Dependencies[2].ByType)
+	assert.Equal(suite.T(), []string{"english"}, explanation.Dependencies[2].Injected)
+	assert.Equal(suite.T(), "no dependency found, the optional field is left uninitialized",
+		explanation.Dependencies[3].Reason)
+	assert.Empty(suite.T(), explanation.Dependencies[3].Injected)
+	assert.False(suite.T(), explanation.Dependencies[4].ByType)
+	assert.Equal(suite.T(), []string{"english"}, explanation.Dependencies[4].Injected)
+	assert.Equal(suite.T(), "all beans of the group are injected", explanation.Dependencies[4].Reason)
+	assert.Equal(suite.T(), "value is resolved from the property sources", explanation.Dependencies[5].Reason)
+	ambiguous := explanation.Dependencies[6]
+	assert.Equal(suite.T(), []string{"prototype1", "prototype2"}, ambiguous.Candidates)
+	assert.Empty(suite.T(), ambiguous.Reason)
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, ambiguous.Err)
+	assert.Contains(suite.T(), explanation.String(), "\n  Greeter di.greeter: english (the only candidate of the "+
+		"type is injected), candidates: [english]")
+	assert.Contains(suite.T(), explanation.String(), "\n  Ambiguous *di.resolvedPrototype: error: more than one "+
+		"candidate found")
+}
+
+func (suite *TestSuite) TestExplainBeansNotInjectedByContainer() {
+	_, err := RegisterBeanInstance("instance", &englishGreeter{})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("factory", Singleton, func(context.Context) (interface{}, error) {
+		return &englishGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterAlias("alias", "instance")
+	assert.NoError(suite.T(), err)
+	explanation, err := Explain("alias")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &BeanExplanation{BeanID: "instance", Scope: Singleton,
+		Type: reflect.TypeOf((*englishGreeter)(nil))}, explanation)
+	explanation, err = Explain("factory")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &BeanExplanation{BeanID: "factory", Scope: Singleton}, explanation)
+	_, err = Explain("missing")
+	assert.EqualError(suite.T(), err, "bean is not registered: missing")
+}
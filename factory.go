@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Factory is a factory of Prototype beans: it can be injected into the beans of any scope (mostly singletons) and
+// creates a new instance of the bean upon each call, running the injection, PostConstruct and postprocessors:
+//
+//	type service struct {
+//		sessions *di.Factory[*Session] `di.inject:""`
+//	}
+type Factory[T any] struct {
+	beanID string
+}
+
+// Get method creates a new instance of the bean, propagating the context to the bean factory and ContextAwareBean-s.
+// It may panic, so if receiving the error in return is preferred, consider using `GetSafe`.
+func (f *Factory[T]) Get(ctx context.Context) T {
+	beanInstance, err := f.GetSafe(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return beanInstance
+}
+
+// GetSafe method creates a new instance of the bean, propagating the context to the bean factory and
+// ContextAwareBean-s. It doesnt panic upon explicit error, but returns the error instead.
+func (f *Factory[T]) GetSafe(ctx context.Context) (T, error) {
+	var zero T
+	beanInstance, err := GetInstanceSafeCtx(ctx, f.beanID)
+	if err != nil {
+		return zero, err
+	}
+	typedBeanInstance, ok := beanInstance.(T)
+	if !ok {
+		return zero, errors.New("bean " + f.beanID + " can't be created by " + reflect.TypeOf(f).String())
+	}
+	return typedBeanInstance, nil
+}
+
+// BeanID method returns ID of the bean created by the factory.
+func (f *Factory[T]) BeanID() string {
+	return f.beanID
+}
+
+func (f *Factory[T]) setBeanID(beanID string) {
+	f.beanID = beanID
+}
+
+func (f *Factory[T]) beanType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (f *Factory[T]) checkScope(beanScope Scope) error {
+	if beanScope != Prototype {
+		return errors.New("only prototype beans can be created by di.Factory")
+	}
+	return nil
+}
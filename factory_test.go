@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sessionBean struct {
+	Scope   Scope        `di.scope:"prototype"`
+	gateway *stubGateway `di.inject:""`
+	ctx     context.Context
+	ready   bool
+}
+
+func (sb *sessionBean) PostConstruct() error {
+	sb.ready = true
+	return nil
+}
+
+func (sb *sessionBean) SetContext(ctx context.Context) {
+	sb.ctx = ctx
+}
+
+type sessionFactoryConsumer struct {
+	sessions *Factory[*sessionBean] `di.inject:""`
+}
+
+type singletonFactoryConsumer struct {
+	gateways *Factory[*stubGateway] `di.inject:""`
+}
+
+func (suite *TestSuite) TestFactoryInjection() {
+	overwritten, err := RegisterBeanInstance("gateway", &stubGateway{name: "stub"})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("session", reflect.TypeOf((*sessionBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*sessionFactoryConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*sessionFactoryConsumer)
+	assert.Equal(suite.T(), "session", consumer.sessions.BeanID())
+	ctx := context.WithValue(context.Background(), BeanKey("key"), "value")
+	session := consumer.sessions.Get(ctx)
+	assert.True(suite.T(), session.ready)
+	assert.Same(suite.T(), GetInstance("gateway"), session.gateway)
+	assert.Equal(suite.T(), "value", session.ctx.Value(BeanKey("key")))
+	assert.NotSame(suite.T(), session, consumer.sessions.Get(ctx))
+}
+
+func (suite *TestSuite) TestFactoryOfSingleton() {
+	overwritten, err := RegisterBean("gateway", reflect.TypeOf((*stubGateway)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*singletonFactoryConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "only prototype beans can be created by di.Factory")
+}
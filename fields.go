@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"unsafe"
+)
+
+var unsafeInjection int32 = 1
+
+// SetUnsafeInjection function enables or disables injection into unexported fields. Exported fields are always set
+// using plain reflection, but unexported ones can only be written through `unsafe.Pointer`, which may be undesirable
+// (e.g. with `-d=checkptr` or in environments forbidding `unsafe`). When disabled, injection into unexported fields
+// fails with an error. It's enabled by default.
+func SetUnsafeInjection(enabled bool) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if enabled {
+		atomic.StoreInt32(&unsafeInjection, 1)
+	} else {
+		atomic.StoreInt32(&unsafeInjection, 0)
+	}
+}
+
+// settableField function returns the settable value of the bean's field, resorting to `unsafe` for unexported fields.
+// Nil embedded pointers on the path to the nested field are instantiated.
+func settableField(beanID string, structValue reflect.Value, field reflect.StructField) (reflect.Value, error) {
+	if !field.IsExported() && atomic.LoadInt32(&unsafeInjection) == 0 {
+		return reflect.Value{}, errors.New("can't inject into unexported field " + field.Name + " of bean " + beanID +
+			": unsafe injection is disabled")
+	}
+	fieldValue := structValue
+	for _, i := range field.Index {
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		fieldValue = fieldValue.Field(i)
+		if !fieldValue.CanSet() {
+			fieldValue = reflect.NewAt(fieldValue.Type(), unsafe.Pointer(fieldValue.UnsafeAddr())).Elem()
+		}
+	}
+	return fieldValue, nil
+}
+
+// injectableFields function returns fields of the bean struct that can be tagged for the injection. Nested struct
+// fields tagged with `di.inject.struct:"true"` and embedded structs (or pointers to structs, instantiated upon the
+// injection) are not returned themselves: the container descends into them instead, so their fields are returned (with
+// the full index and the dotted name, e.g. `Repositories.Users`). Such nested fields are treated as unexported if any
+// struct on their path is unexported. Embedded structs can be skipped with `di.inject.struct:"false"`.
+func injectableFields(beanElement reflect.Type) ([]reflect.StructField, error) {
+	return collectInjectableFields(beanElement, map[reflect.Type]bool{beanElement: true})
+}
+
+func collectInjectableFields(structType reflect.Type, path map[reflect.Type]bool) ([]reflect.StructField, error) {
+	var fields []reflect.StructField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		nested, err := isNestedStruct(field)
+		if err != nil {
+			return nil, err
+		}
+		if !nested {
+			fields = append(fields, field)
+			continue
+		}
+		nestedType := field.Type
+		if nestedType.Kind() == reflect.Ptr {
+			nestedType = nestedType.Elem()
+		}
+		if path[nestedType] { // recursive embedding, e.g. `type Node struct { *Node }`
+			continue
+		}
+		path[nestedType] = true
+		nestedFields, err := collectInjectableFields(nestedType, path)
+		delete(path, nestedType)
+		if err != nil {
+			return nil, err
+		}
+		for _, nestedField := range nestedFields {
+			nestedField.Name = field.Name + "." + nestedField.Name
+			nestedField.Index = append(append([]int(nil), field.Index...), nestedField.Index...)
+			if !field.IsExported() && nestedField.IsExported() {
+				nestedField.PkgPath = field.PkgPath
+			}
+			fields = append(fields, nestedField)
+		}
+	}
+	return fields, nil
+}
+
+// isNestedStruct function checks if the container should descend into the struct field: either it's tagged with
+// `di.inject.struct:"true"`, or it's an embedded struct (or a pointer to struct) that is neither tagged with
+// `di.inject.struct:"false"` nor injected itself (i.e. it has no `di.inject`, `di.value` or `di.inject.group` tag).
+func isNestedStruct(field reflect.StructField) (bool, error) {
+	structKind := field.Type.Kind() == reflect.Struct ||
+		(field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct)
+	injectStructTag, ok := injectStruct.lookup(field)
+	if !ok {
+		if !field.Anonymous || !structKind {
+			return false, nil
+		}
+		for _, injectionTag := range []tag{inject, value, injectGroup} {
+			if _, ok := injectionTag.lookup(field); ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	nested, err := strconv.ParseBool(injectStructTag)
+	if err != nil {
+		return false, errors.New("invalid di.inject.struct value: " + injectStructTag)
+	}
+	if nested && !structKind {
+		return false, errors.New("di.inject.struct can only be used for structs and pointers to structs: " +
+			field.Name)
+	}
+	return nested, nil
+}
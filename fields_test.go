@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exportedFieldsBean struct {
+	Dependency *singletonDependency `di.inject:""`
+	Value      string               `di.value:"exported"`
+}
+
+type unexportedFieldsBean struct {
+	dependency *singletonDependency `di.inject:""`
+}
+
+type singletonDependency struct{}
+
+func (suite *TestSuite) TestExportedFieldsAreInjectedWithoutUnsafe() {
+	SetUnsafeInjection(false)
+	overwritten, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*exportedFieldsBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	bean := GetInstance("bean").(*exportedFieldsBean)
+	assert.Same(suite.T(), GetInstance("dependency"), bean.Dependency)
+	assert.Equal(suite.T(), "exported", bean.Value)
+}
+
+func (suite *TestSuite) TestUnexportedFieldsCantBeInjectedWithoutUnsafe() {
+	SetUnsafeInjection(false)
+	overwritten, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*unexportedFieldsBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err,
+		"can't inject into unexported field dependency of bean bean: unsafe injection is disabled")
+}
+
+func (suite *TestSuite) TestUnexportedFieldsAreInjectedWithUnsafe() {
+	overwritten, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("bean", reflect.TypeOf((*unexportedFieldsBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("dependency"), GetInstance("bean").(*unexportedFieldsBean).dependency)
+}
+
+type nestedDependencies struct {
+	Dependency *singletonDependency `di.inject:""`
+	Value      string               `di.value:"nested"`
+}
+
+type nestedFieldsBean struct {
+	Exported   nestedDependencies `di.inject.struct:"true"`
+	unexported nestedDependencies `di.inject.struct:"true"`
+	Skipped    nestedDependencies
+}
+
+func (suite *TestSuite) TestNestedStructFieldsAreInjected() {
+	_, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("bean", reflect.TypeOf((*nestedFieldsBean)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	bean := GetInstance("bean").(*nestedFieldsBean)
+	assert.Same(suite.T(), GetInstance("dependency"), bean.Exported.Dependency)
+	assert.Equal(suite.T(), "nested", bean.Exported.Value)
+	assert.Same(suite.T(), GetInstance("dependency"), bean.unexported.Dependency)
+	assert.Equal(suite.T(), "nested", bean.unexported.Value)
+	assert.Nil(suite.T(), bean.Skipped.Dependency)
+	assert.Empty(suite.T(), bean.Skipped.Value)
+}
+
+func (suite *TestSuite) TestNestedUnexportedStructFieldsCantBeInjectedWithoutUnsafe() {
+	SetUnsafeInjection(false)
+	_, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("bean", reflect.TypeOf((*nestedFieldsBean)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err,
+		"can't inject into unexported field unexported.Dependency of bean bean: unsafe injection is disabled")
+}
+
+func (suite *TestSuite) TestNestedStructFieldsMustBeStructs() {
+	type SliceBean struct {
+		Nested []nestedDependencies `di.inject.struct:"true"`
+	}
+	type InvalidBean struct {
+		Nested nestedDependencies `di.inject.struct:"yes"`
+	}
+	_, err := RegisterBean("bean", reflect.TypeOf((*SliceBean)(nil)))
+	assert.EqualError(suite.T(), err, "di.inject.struct can only be used for structs and pointers to structs: Nested")
+	_, err = RegisterBean("bean", reflect.TypeOf((*InvalidBean)(nil)))
+	assert.EqualError(suite.T(), err, "invalid di.inject.struct value: yes")
+}
+
+type handlerBase struct {
+	Dependency *singletonDependency `di.inject:""`
+}
+
+type EmbeddedBase struct {
+	Value string `di.value:"embedded"`
+}
+
+type embeddingHandler struct {
+	*handlerBase
+	*EmbeddedBase
+	nestedDependencies
+	Pointer *nestedDependencies `di.inject.struct:"true"`
+}
+
+type skippingHandler struct {
+	*handlerBase `di.inject.struct:"false"`
+}
+
+type recursiveHandler struct {
+	*recursiveHandler
+	Dependency *singletonDependency `di.inject:""`
+}
+
+func (suite *TestSuite) TestEmbeddedStructFieldsAreInjected() {
+	_, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("handler", reflect.TypeOf((*embeddingHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("skipping", reflect.TypeOf((*skippingHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("recursive", reflect.TypeOf((*recursiveHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	handler := GetInstance("handler").(*embeddingHandler)
+	assert.Same(suite.T(), GetInstance("dependency"), handler.handlerBase.Dependency)
+	assert.Equal(suite.T(), "embedded", handler.EmbeddedBase.Value)
+	assert.Same(suite.T(), GetInstance("dependency"), handler.nestedDependencies.Dependency)
+	assert.Equal(suite.T(), "nested", handler.nestedDependencies.Value)
+	assert.Same(suite.T(), GetInstance("dependency"), handler.Pointer.Dependency)
+	assert.Nil(suite.T(), GetInstance("skipping").(*skippingHandler).handlerBase)
+	recursive := GetInstance("recursive").(*recursiveHandler)
+	assert.Nil(suite.T(), recursive.recursiveHandler)
+	assert.Same(suite.T(), GetInstance("dependency"), recursive.Dependency)
+}
+
+type injectedBaseHandler struct {
+	*handlerBase `di.inject:"base"`
+}
+
+func (suite *TestSuite) TestEmbeddedPointerTaggedWithInjectIsInjected() {
+	_, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("base", reflect.TypeOf((*handlerBase)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("handler", reflect.TypeOf((*injectedBaseHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	handler := GetInstance("handler").(*injectedBaseHandler)
+	assert.Same(suite.T(), GetInstance("base"), handler.handlerBase)
+	assert.Same(suite.T(), GetInstance("dependency"), handler.Dependency)
+}
+
+func (suite *TestSuite) TestEmbeddedExportedPointerIsInjectedWithoutUnsafe() {
+	type Handler struct {
+		*EmbeddedBase
+	}
+	SetUnsafeInjection(false)
+	_, err := RegisterBean("handler", reflect.TypeOf((*Handler)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "embedded", GetInstance("handler").(*Handler).Value)
+}
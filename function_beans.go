@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import "reflect"
+
+// isFunctionBean function checks whether the bean instance is a function. Function beans (e.g.
+// `func(ctx context.Context) (*User, error)`) are injected into fields of the same function type, by ID or by type, so
+// the function type plays the role of an interface. They aren't initialized (PostConstruct, postprocessors, etc.):
+// functions aren't comparable, so the instances replaced by the postprocessors couldn't be re-injected.
+func isFunctionBean(beanInstance interface{}) bool {
+	beanType := reflect.TypeOf(beanInstance)
+	return beanType != nil && beanType.Kind() == reflect.Func
+}
+
+// injectsFunctionBean function checks whether the field of a function type is injected with the function bean rather
+// than with the provider (see Provider) of the bean returned by the function. It's the case if the bean to inject is a
+// function bean or, when injecting by type (`beanToInject` is empty), if there are function beans of the field type.
+func injectsFunctionBean(field reflect.StructField, beanToInject string) bool {
+	if beanToInject == "" {
+		return len(findInjectionCandidates(field.Type)) > 0
+	}
+	beanType, ok := getBeanType(beanToInject)
+	return ok && beanType.Kind() == reflect.Func
+}
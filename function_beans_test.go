@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type functionBeanUser struct {
+	name string
+}
+
+type userLoader func(ctx context.Context, name string) (*functionBeanUser, error)
+
+type functionBeanConsumer struct {
+	loadByType func(ctx context.Context) (*functionBeanUser, error) `di.inject:""`
+	loadByID   func(ctx context.Context) (*functionBeanUser, error) `di.inject:"loadUser"`
+	namedByID  userLoader                                           `di.inject:"namedLoader"`
+	guest      func() *functionBeanUser                             `di.inject:""`
+}
+
+func (suite *TestSuite) TestFunctionBeans() {
+	var loadUser func(ctx context.Context) (*functionBeanUser, error) = func(context.Context) (*functionBeanUser, error) {
+		return &functionBeanUser{name: "admin"}, nil
+	}
+	overwritten, err := RegisterBeanInstance("loadUser", loadUser)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("namedLoader", userLoader(func(_ context.Context, name string) (*functionBeanUser, error) {
+		return nil, errors.New("user not found: " + name)
+	}))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterTypedBeanFactory("guest", Prototype, func(context.Context) (func() *functionBeanUser, error) {
+		return func() *functionBeanUser {
+			return &functionBeanUser{name: "guest"}
+		}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*functionBeanConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*functionBeanConsumer)
+	user, err := consumer.loadByType(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "admin", user.name)
+	user, err = consumer.loadByID(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "admin", user.name)
+	_, err = consumer.namedByID(context.Background(), "guest")
+	assert.EqualError(suite.T(), err, "user not found: guest")
+	assert.Equal(suite.T(), "guest", consumer.guest().name)
+	user, err = GetInstance("loadUser").(func(ctx context.Context) (*functionBeanUser, error))(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "admin", user.name)
+	assert.Equal(suite.T(), []string{"loadUser"}, GetBeanIDsOf[func(ctx context.Context) (*functionBeanUser, error)]())
+}
+
+type functionProviderConsumer struct {
+	user func() *functionBeanUser `di.inject:""`
+}
+
+func (suite *TestSuite) TestProviderWithoutFunctionBeans() {
+	overwritten, err := RegisterBeanInstance("user", &functionBeanUser{name: "admin"})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*functionProviderConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*functionProviderConsumer)
+	assert.Same(suite.T(), GetInstance("user"), consumer.user())
+}
+
+func (suite *TestSuite) TestAmbiguousFunctionBeans() {
+	for _, beanID := range []string{"first", "second"} {
+		_, err := RegisterBeanInstance(beanID, func() *functionBeanUser {
+			return &functionBeanUser{}
+		})
+		assert.NoError(suite.T(), err)
+	}
+	_, err := RegisterBean("consumer", reflect.TypeOf((*functionProviderConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.IsType(suite.T(), &AmbiguousDependencyError{}, err)
+}
+
+func (suite *TestSuite) TestNilFunctionBean() {
+	var loadUser userLoader
+	overwritten, err := RegisterBeanInstance("loadUser", loadUser)
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, "bean instance is a nil pointer: loadUser")
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+var beanGroups = make(map[string]map[string]bool)
+
+// AddToGroup function adds registered beans to the group, so that they are injected into collections tagged with
+// `di.inject.group`. Beans registered by type can also declare their groups with a tag `di.group:"handlers,admin"`.
+func AddToGroup(group string, beanIDs ...string) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't add beans to group")
+	}
+	for _, beanID := range beanIDs {
+		if !isBeanRegistered(beanID) {
+			return errors.New("bean is not registered: " + beanID)
+		}
+		addToGroup(group, beanID)
+	}
+	return nil
+}
+
+func addToGroup(group string, beanID string) {
+	if _, ok := beanGroups[beanID]; !ok {
+		beanGroups[beanID] = make(map[string]bool)
+	}
+	beanGroups[beanID][group] = true
+}
+
+// getGroups function returns groups declared for the bean type with `di.group` tags.
+func getGroups(bean reflect.Type) []string {
+	beanElement := bean.Elem()
+	if beanElement.Kind() != reflect.Struct {
+		return nil
+	}
+	var groups []string
+	for i := 0; i < beanElement.NumField(); i++ {
+		beanGroupsTag, ok := group.lookup(beanElement.Field(i))
+		if !ok {
+			continue
+		}
+		for _, beanGroup := range strings.Split(beanGroupsTag, ",") {
+			if beanGroup = strings.TrimSpace(beanGroup); beanGroup != "" {
+				groups = append(groups, beanGroup)
+			}
+		}
+	}
+	return groups
+}
+
+// findGroupCandidates function returns IDs of the beans in the group (sorted, for the sake of determinism).
+func findGroupCandidates(group string) []string {
+	var candidates []string
+	for beanID, groups := range beanGroups {
+		if groups[group] {
+			candidates = append(candidates, beanID)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// injectGroupDependencies function injects beans of the group into the collection field (slice or map) of the bean.
+func injectGroupDependencies(ctx context.Context, beanID string, fieldToInject reflect.Value, step injectionStep, chain *dependencyChain) error {
+	if len(step.beanIDs) < 1 && !step.emptyCollection {
+		return nil
+	}
+	elementType := fieldToInject.Type().Elem()
+	setEmptyCollection(fieldToInject)
+	for _, beanToInject := range step.beanIDs {
+		logInjection(beanID, beans[beanID].Elem(), beanToInject)
+		instanceToInject, err := getDependencyInstance(ctx, beanID, beanToInject, chain)
+		if err != nil {
+			return err
+		}
+		if isNilBean(instanceToInject) {
+			continue
+		}
+		if !reflect.TypeOf(instanceToInject).AssignableTo(elementType) {
+			return errors.New("bean " + beanToInject + " of group " + step.group + " can't be injected into " +
+				fieldToInject.Type().String())
+		}
+		if fieldToInject.Kind() == reflect.Slice {
+			fieldToInject.Set(reflect.Append(fieldToInject, reflect.ValueOf(instanceToInject)))
+		} else if err := injectMapEntry(beanID, step.field, fieldToInject, beanToInject, instanceToInject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
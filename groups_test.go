@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type handler interface {
+	Handle() string
+}
+
+type userHandler struct {
+	Group struct{} `di.group:"handlers"`
+}
+
+func (uh *userHandler) Handle() string {
+	return "user"
+}
+
+type adminHandler struct {
+	Group struct{} `di.group:"handlers, admin"`
+}
+
+func (ah *adminHandler) Handle() string {
+	return "admin"
+}
+
+type debugHandler struct{}
+
+func (dh *debugHandler) Handle() string {
+	return "debug"
+}
+
+type handlersRouter struct {
+	handlers      []handler          `di.inject.group:"handlers"`
+	adminHandlers map[string]handler `di.inject.group:"admin"`
+	missing       []handler          `di.inject.group:"missing" di.optional:"true"`
+	empty         []handler          `di.inject.group:"missing"`
+}
+
+func (suite *TestSuite) TestGroupInjection() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("adminHandler", reflect.TypeOf((*adminHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("debugHandler", reflect.TypeOf((*debugHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("factoryHandler", Singleton, func(context.Context) (interface{}, error) {
+		return &debugHandler{}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), AddToGroup("handlers", "factoryHandler"))
+	assert.Error(suite.T(), AddToGroup("handlers", "unknownHandler"))
+	overwritten, err = RegisterBean("router", reflect.TypeOf((*handlersRouter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	router := GetInstance("router").(*handlersRouter)
+	assert.Len(suite.T(), router.handlers, 3)
+	assert.Same(suite.T(), GetInstance("adminHandler"), router.handlers[0])
+	assert.Same(suite.T(), GetInstance("factoryHandler"), router.handlers[1])
+	assert.Same(suite.T(), GetInstance("userHandler"), router.handlers[2])
+	assert.Equal(suite.T(), map[string]handler{"adminHandler": GetInstance("adminHandler").(handler)}, router.adminHandlers)
+	assert.Nil(suite.T(), router.missing)
+	assert.NotNil(suite.T(), router.empty)
+	assert.Empty(suite.T(), router.empty)
+	assert.Error(suite.T(), AddToGroup("handlers", "debugHandler"))
+}
+
+type wrongGroupRouter struct {
+	handlers []*userHandler `di.inject.group:"handlers"`
+}
+
+func (suite *TestSuite) TestGroupInjectionTypeMismatch() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("adminHandler", reflect.TypeOf((*adminHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("router", reflect.TypeOf((*wrongGroupRouter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+}
+
+type unsupportedGroupRouter struct {
+	handler handler `di.inject.group:"handlers"`
+}
+
+func (suite *TestSuite) TestGroupInjectionUnsupportedField() {
+	overwritten, err := RegisterBean("router", reflect.TypeOf((*unsupportedGroupRouter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, unsupportedDependencyType)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// HealthStatus is an enum for health statuses.
+type HealthStatus string
+
+const (
+	// HealthUp status means that the component (or the whole application) is healthy.
+	HealthUp HealthStatus = "UP"
+	// HealthDown status means that the component (or the whole application) is unhealthy.
+	HealthDown HealthStatus = "DOWN"
+)
+
+// HealthIndicator is an interface marking singleton beans that report their health (liveness).
+type HealthIndicator interface {
+	// CheckHealth method returns an error if the bean is unhealthy.
+	CheckHealth(ctx context.Context) error
+}
+
+// ReadinessIndicator is an interface marking singleton beans that report their readiness to serve traffic.
+type ReadinessIndicator interface {
+	// CheckReadiness method returns an error if the bean is not ready.
+	CheckReadiness(ctx context.Context) error
+}
+
+// HealthReport is an aggregated health of the beans.
+type HealthReport struct {
+	// Status is HealthUp only if all the components are up.
+	Status HealthStatus `json:"status"`
+	// Components contains health of every checked bean, by bean ID.
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth is a health of a single bean.
+type ComponentHealth struct {
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// Health function checks the health of all singleton beans implementing HealthIndicator.
+func Health(ctx context.Context) HealthReport {
+	return checkBeans(func(instance interface{}) (func() error, bool) {
+		indicator, ok := instance.(HealthIndicator)
+		if !ok {
+			return nil, false
+		}
+		return func() error { return indicator.CheckHealth(ctx) }, true
+	})
+}
+
+// Readiness function checks the readiness of all singleton beans implementing ReadinessIndicator. The application is
+// not ready until the container is initialized.
+func Readiness(ctx context.Context) HealthReport {
+	return checkBeans(func(instance interface{}) (func() error, bool) {
+		indicator, ok := instance.(ReadinessIndicator)
+		if !ok {
+			return nil, false
+		}
+		return func() error { return indicator.CheckReadiness(ctx) }, true
+	})
+}
+
+func checkBeans(getCheck func(instance interface{}) (func() error, bool)) HealthReport {
+	singletons := initializedSingletons.Load()
+	if singletons == nil {
+		return HealthReport{Status: HealthDown, Components: map[string]ComponentHealth{
+			"container": {Status: HealthDown, Error: "container is not initialized"},
+		}}
+	}
+	report := HealthReport{Status: HealthUp, Components: make(map[string]ComponentHealth)}
+	for beanID, instance := range singletons.instances {
+		check, ok := getCheck(instance)
+		if !ok {
+			continue
+		}
+		if err := check(); err != nil {
+			report.Status = HealthDown
+			report.Components[beanID] = ComponentHealth{Status: HealthDown, Error: err.Error()}
+			continue
+		}
+		report.Components[beanID] = ComponentHealth{Status: HealthUp}
+	}
+	return report
+}
+
+// HealthHandler function returns http.Handler serving the aggregated health of the beans as JSON: requests to paths
+// ending with `/readyz` are served with Readiness, all other requests (e.g. to `/healthz`) - with Health. Response
+// status is 200 if the application is up, 503 otherwise.
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report HealthReport
+		if strings.HasSuffix(r.URL.Path, "/readyz") {
+			report = Readiness(r.Context())
+		} else {
+			report = Health(r.Context())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != HealthUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type databaseHealthIndicator struct {
+	err error
+}
+
+func (dhi *databaseHealthIndicator) CheckHealth(context.Context) error {
+	return dhi.err
+}
+
+func (dhi *databaseHealthIndicator) CheckReadiness(context.Context) error {
+	return nil
+}
+
+type cacheReadinessIndicator struct {
+	err error
+}
+
+func (cri *cacheReadinessIndicator) CheckReadiness(context.Context) error {
+	return cri.err
+}
+
+func (suite *TestSuite) TestHealth() {
+	assert.Equal(suite.T(), HealthDown, Readiness(context.Background()).Status)
+	database := &databaseHealthIndicator{}
+	overwritten, err := RegisterBeanInstance("database", database)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	cache := &cacheReadinessIndicator{err: errors.New("cache is warming up")}
+	overwritten, err = RegisterBeanInstance("cache", cache)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), HealthReport{Status: HealthUp, Components: map[string]ComponentHealth{
+		"database": {Status: HealthUp},
+	}}, Health(context.Background()))
+	assert.Equal(suite.T(), HealthReport{Status: HealthDown, Components: map[string]ComponentHealth{
+		"database": {Status: HealthUp},
+		"cache":    {Status: HealthDown, Error: "cache is warming up"},
+	}}, Readiness(context.Background()))
+	database.err = errors.New("connection refused")
+	assert.Equal(suite.T(), HealthDown, Health(context.Background()).Status)
+}
+
+func (suite *TestSuite) TestHealthHandler() {
+	cache := &cacheReadinessIndicator{err: errors.New("cache is warming up")}
+	overwritten, err := RegisterBeanInstance("cache", cache)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	recorder := httptest.NewRecorder()
+	HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.JSONEq(suite.T(), `{"status": "UP"}`, recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, recorder.Code)
+	var report HealthReport
+	assert.NoError(suite.T(), json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(suite.T(), HealthDown, report.Components["cache"].Status)
+	assert.Equal(suite.T(), "cache is warming up", report.Components["cache"].Error)
+}
+
+func (suite *TestSuite) TestHealthDuringClose() {
+	overwritten, err := RegisterBeanInstance("database", &databaseHealthIndicator{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var wg, started sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for j := 0; j < 100; j++ {
+				report := Health(context.Background())
+				if _, ok := report.Components["container"]; !ok {
+					assert.Equal(suite.T(), HealthUp, report.Status)
+				}
+			}
+		}()
+	}
+	started.Wait()
+	Close()
+	wg.Wait()
+	assert.Equal(suite.T(), HealthDown, Health(context.Background()).Status)
+}
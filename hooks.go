@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ContainerEventType is an enum for types of the container lifecycle events.
+type ContainerEventType string
+
+const (
+	// BeanRegistered event is emitted upon bean registration (by type, instance or factory).
+	BeanRegistered ContainerEventType = "beanRegistered"
+	// BeanCreated event is emitted upon creation of the bean instance, before its dependencies are injected.
+	BeanCreated ContainerEventType = "beanCreated"
+	// BeanInitialized event is emitted after the bean instance is initialized (with PostConstruct and postprocessors).
+	BeanInitialized ContainerEventType = "beanInitialized"
+	// ContainerInitialized event is emitted after the container is initialized.
+	ContainerInitialized ContainerEventType = "containerInitialized"
+	// BeanClosed event is emitted after the bean implementing io.Closer is closed, or after the cleanup function returned
+	// by its factory is called.
+	BeanClosed ContainerEventType = "beanClosed"
+	// ContainerClosed event is emitted after the container is closed.
+	ContainerClosed ContainerEventType = "containerClosed"
+)
+
+// ContainerEvent is a container lifecycle event passed to the hooks.
+type ContainerEvent struct {
+	// Type of the event.
+	Type ContainerEventType
+	// BeanID is set for bean events.
+	BeanID string
+	// Bean is the bean instance, set for BeanCreated, BeanInitialized and BeanClosed events.
+	Bean interface{}
+	// Err is the error returned by io.Closer, set for BeanClosed events.
+	Err error
+}
+
+var containerHooks []func(event ContainerEvent)
+
+// containerHooksLock guards the hooks: they're replaced under the initializeShutdownLock, but events are emitted
+// without it (e.g. upon the creation of Prototype beans).
+var containerHooksLock sync.RWMutex
+
+func setContainerHooks(hooks []func(event ContainerEvent)) {
+	containerHooksLock.Lock()
+	defer containerHooksLock.Unlock()
+	containerHooks = hooks
+}
+
+// RegisterContainerHook function registers a hook receiving the container lifecycle events (e.g. for observability or
+// plugin systems). Hooks are called synchronously (from the goroutine emitting the event), in registration order,
+// while the container may hold its internal locks: they must not register beans or initialize/close the container.
+func RegisterContainerHook(hook func(event ContainerEvent)) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register container hook")
+	}
+	setContainerHooks(append(containerHooks[:len(containerHooks):len(containerHooks)], hook))
+	return nil
+}
+
+func emitContainerEvent(event ContainerEvent) {
+	containerHooksLock.RLock()
+	hooks := containerHooks
+	containerHooksLock.RUnlock()
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestSuite) TestContainerHooks() {
+	var events []ContainerEvent
+	err := RegisterContainerHook(func(event ContainerEvent) {
+		events = append(events, ContainerEvent{Type: event.Type, BeanID: event.BeanID})
+	})
+	assert.NoError(suite.T(), err)
+	overwritten, err := RegisterBean("counter", reflect.TypeOf((*prototypeCounter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	var closed int32
+	overwritten, err = RegisterBeanInstance("closeable", &countingCloseableBean{counter: &closed})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	GetInstance("counter")
+	Close()
+	assert.Equal(suite.T(), []ContainerEvent{
+		{Type: BeanRegistered, BeanID: "counter"},
+		{Type: BeanRegistered, BeanID: "closeable"},
+		{Type: BeanInitialized, BeanID: "closeable"},
+		{Type: ContainerInitialized},
+		{Type: BeanCreated, BeanID: "counter"},
+		{Type: BeanInitialized, BeanID: "counter"},
+		{Type: BeanClosed, BeanID: "closeable"},
+		{Type: ContainerClosed},
+	}, events)
+	assert.Equal(suite.T(), int32(1), closed)
+	assert.NoError(suite.T(), RegisterContainerHook(func(ContainerEvent) {}))
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPServerPhase is a default phase of HTTP servers registered by RegisterHTTPServer: they are started after all other
+// Lifecycle beans and stopped before them, so that requests are not served by partially started (or stopped) beans.
+const HTTPServerPhase = math.MaxInt32
+
+// HTTPServer is a Lifecycle bean managing *http.Server (see RegisterHTTPServer): it's started by Start function and
+// gracefully shut down by Stop function (and, therefore, by Close, before other beans are closed).
+type HTTPServer struct {
+	beanID          string
+	server          *http.Server
+	phase           int
+	shutdownTimeout time.Duration
+	mutex           sync.Mutex
+	listener        net.Listener
+	served          chan struct{}
+}
+
+// HTTPServerOption is an option of RegisterHTTPServer function.
+type HTTPServerOption func(server *HTTPServer)
+
+// WithHTTPServerPhase option sets the phase of the server (HTTPServerPhase by default).
+func WithHTTPServerPhase(phase int) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.phase = phase
+	}
+}
+
+// WithHTTPShutdownTimeout option limits the duration of the graceful shutdown of the server: when it's exceeded (or the
+// context passed to Stop is done), remaining connections are closed forcibly. By default, only the context is used.
+func WithHTTPShutdownTimeout(timeout time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.shutdownTimeout = timeout
+	}
+}
+
+// RegisterHTTPServer function registers a singleton bean managing the server: its handler (http.DefaultServeMux if
+// it's nil) is wrapped with Middleware, it starts listening on its address when Start is called and it's gracefully
+// shut down when Stop is called. The bean is of type *HTTPServer, the server itself is available via its Server method.
+func RegisterHTTPServer(beanID string, server *http.Server, opts ...HTTPServerOption) (overwritten bool, err error) {
+	if server == nil {
+		return false, errors.New("server can't be nil: " + beanID)
+	}
+	httpServer := &HTTPServer{beanID: beanID, server: server, phase: HTTPServerPhase}
+	for _, opt := range opts {
+		opt(httpServer)
+	}
+	handler := server.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	overwritten, err = RegisterBeanInstance(beanID, httpServer)
+	if err == nil {
+		server.Handler = Middleware(handler)
+	}
+	return overwritten, err
+}
+
+// Server method returns the managed server.
+func (s *HTTPServer) Server() *http.Server {
+	return s.server
+}
+
+// Addr method returns the address the server listens on, or nil if it's not started. It's handy when the server is
+// configured to listen on a random port (e.g. ":0").
+func (s *HTTPServer) Addr() net.Addr {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Phase method returns the phase of the server.
+func (s *HTTPServer) Phase() int {
+	return s.phase
+}
+
+// Start method starts listening on the server address (so that errors like "address already in use" are returned right
+// away) and serves connections in the background.
+func (s *HTTPServer) Start(context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	addr := s.server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.served = make(chan struct{})
+	go func(served chan struct{}) {
+		defer close(served)
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithField("beanID", s.beanID).Error(err)
+		}
+	}(s.served)
+	logrus.WithField("beanID", s.beanID).WithField("addr", listener.Addr().String()).Debug("HTTP server started")
+	return nil
+}
+
+// Stop method gracefully shuts the server down: it stops accepting connections and waits for the active ones to become
+// idle. If the context is done (or the shutdown timeout is exceeded) first, remaining connections are closed forcibly
+// and the context error is returned.
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	if s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		if closeErr := s.server.Close(); closeErr != nil {
+			logrus.WithField("beanID", s.beanID).Error(closeErr)
+		}
+	}
+	<-s.served
+	s.listener = nil
+	return err
+}
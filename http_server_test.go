@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greetingRequestBean struct {
+	Scope Scope        `di.scope:"request"`
+	Info  *RequestInfo `di.inject:""`
+}
+
+func (suite *TestSuite) TestHTTPServer() {
+	var log []string
+	suite.registerLifecycleBeans(&lifecycleBean{name: "consumer", phase: 10, log: &log})
+	overwritten, err := RegisterBean("greeting", reflect.TypeOf((*greetingRequestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bean := MustFromContext[*greetingRequestBean](r.Context(), "greeting")
+		log = append(log, "serve "+bean.Info.ID)
+		_, _ = io.WriteString(w, "hello")
+	})}
+	overwritten, err = RegisterHTTPServer("httpServer", server)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	httpServer := GetInstance("httpServer").(*HTTPServer)
+	assert.Same(suite.T(), server, httpServer.Server())
+	assert.Nil(suite.T(), httpServer.Addr())
+	assert.NoError(suite.T(), Start(context.Background()))
+	addr := httpServer.Addr().String()
+	request, err := http.NewRequest(http.MethodGet, "http://"+addr, nil)
+	assert.NoError(suite.T(), err)
+	request.Header.Set(RequestIDHeader, "request-1")
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(suite.T(), err)
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), response.Body.Close())
+	assert.Equal(suite.T(), "hello", string(body))
+	assert.Equal(suite.T(), "request-1", response.Header.Get(RequestIDHeader))
+	Close()
+	assert.Equal(suite.T(), []string{"start consumer", "serve request-1", "stop consumer"}, log)
+	assert.Nil(suite.T(), httpServer.Addr())
+	_, err = net.Dial("tcp", addr)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestHTTPServerStartFailure() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(suite.T(), err)
+	defer func() {
+		assert.NoError(suite.T(), listener.Close())
+	}()
+	var log []string
+	suite.registerLifecycleBeans(&lifecycleBean{name: "consumer", log: &log})
+	overwritten, err := RegisterHTTPServer("httpServer", &http.Server{Addr: listener.Addr().String()})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	err = Start(context.Background())
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "can't start bean httpServer: ")
+	assert.Equal(suite.T(), []string{"start consumer", "stop consumer"}, log)
+}
+
+func (suite *TestSuite) TestHTTPServerShutdownTimeout() {
+	handling := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(handling)
+		<-release
+	})}
+	overwritten, err := RegisterHTTPServer("httpServer", server, WithHTTPShutdownTimeout(10*time.Millisecond),
+		WithHTTPServerPhase(0))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), InitializeContainer())
+	httpServer := GetInstance("httpServer").(*HTTPServer)
+	assert.Equal(suite.T(), 0, httpServer.Phase())
+	assert.NoError(suite.T(), Start(context.Background()))
+	go func() {
+		_, _ = http.Get("http://" + httpServer.Addr().String())
+	}()
+	<-handling
+	assert.EqualError(suite.T(), Stop(context.Background()), "can't stop bean httpServer: context deadline exceeded")
+}
+
+func (suite *TestSuite) TestRegisterNilHTTPServer() {
+	overwritten, err := RegisterHTTPServer("httpServer", nil)
+	assert.False(suite.T(), overwritten)
+	assert.EqualError(suite.T(), err, "server can't be nil: httpServer")
+}
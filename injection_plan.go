@@ -0,0 +1,376 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// injectionKind is an enum for the ways a field can be injected.
+type injectionKind int
+
+const (
+	injectValueKind injectionKind = iota
+	injectGroupKind
+	injectBeanKind
+	injectProxyKind
+	injectProviderKind
+	injectSliceKind
+	injectMapKind
+	injectHTTPRequestKind
+	injectResponseWriterKind
+	injectRequestInfoKind
+	injectCommandInfoKind
+	injectContextKind
+	// injectNothingKind is a kind of optional fields left uninitialized, since their dependencies are missing.
+	injectNothingKind
+)
+
+// injectionStep describes the injection of a single field. Dependencies are resolved upon the plan creation.
+type injectionStep struct {
+	kind  injectionKind
+	field reflect.StructField
+	// expression is a `di.value` expression, placeholders are resolved upon every injection.
+	expression string
+	// group is a name of the injected group.
+	group string
+	// beanIDs are IDs of the resolved dependencies.
+	beanIDs []string
+	// candidates are IDs of the beans considered for the injection by type, see Explain.
+	candidates []string
+	// reason explains the resolution, see Explain.
+	reason string
+	// emptyCollection is set if an empty collection should be injected when no dependencies are found.
+	emptyCollection bool
+	// optional is set if the field should be left uninitialized when the dependency is not available.
+	optional bool
+	// err is an error of the dependency resolution, returned upon the injection.
+	err error
+}
+
+// injectionPlan is a precompiled list of injection steps of the bean, so that struct fields, tags and candidates are
+// not re-reflected upon every creation of Prototype and Request beans.
+type injectionPlan struct {
+	steps []injectionStep
+}
+
+var injectionPlans sync.Map
+
+// getInjectionPlan function returns the injection plan of the bean, building it upon the first call.
+func getInjectionPlan(beanID string) *injectionPlan {
+	if plan, ok := injectionPlans.Load(beanID); ok {
+		return plan.(*injectionPlan)
+	}
+	plan, _ := injectionPlans.LoadOrStore(beanID, buildInjectionPlan(beanID))
+	return plan.(*injectionPlan)
+}
+
+// invalidateInjectionPlans function drops all built injection plans, it should be called upon any change of bean
+// registrations.
+func invalidateInjectionPlans() {
+	injectionPlans.Range(func(key, _ interface{}) bool {
+		injectionPlans.Delete(key)
+		return true
+	})
+}
+
+func buildInjectionPlan(beanID string) *injectionPlan {
+	plan := &injectionPlan{}
+	fields, err := injectableFields(beans[beanID].Elem())
+	if err != nil {
+		plan.steps = append(plan.steps, injectionStep{err: err})
+		return plan
+	}
+	for _, field := range fields {
+		if valueExpression, ok := value.lookup(field); ok {
+			plan.steps = append(plan.steps, injectionStep{kind: injectValueKind, field: field, expression: valueExpression,
+				reason: "value is resolved from the property sources"})
+			continue
+		}
+		if groupToInject, ok := injectGroup.lookup(field); ok {
+			plan.steps = append(plan.steps, buildGroupInjectionStep(beanID, field, groupToInject))
+			continue
+		}
+		beanToInject, ok := inject.lookup(field)
+		if !ok {
+			continue
+		}
+		if field.Type == contextType && beanToInject == "" {
+			plan.steps = append(plan.steps, injectionStep{kind: injectContextKind, field: field,
+				reason: "the context the bean is created in is injected"})
+			continue
+		}
+		if kind, ok := scopeDependencyKind(field.Type); ok && beanToInject == "" {
+			plan.steps = append(plan.steps, buildScopeDependencyStep(beanID, field, kind))
+			continue
+		}
+		plan.steps = append(plan.steps, buildBeanInjectionStep(beanID, field, resolveAlias(beanToInject)))
+	}
+	return plan
+}
+
+func buildGroupInjectionStep(beanID string, field reflect.StructField, group string) injectionStep {
+	step := injectionStep{kind: injectGroupKind, field: field, group: group}
+	onMissingDependency, err := getOnMissingPolicy(field)
+	if err != nil {
+		step.err = err
+		return step
+	}
+	if field.Type.Kind() != reflect.Slice && field.Type.Kind() != reflect.Map {
+		step.err = errors.New(unsupportedDependencyType)
+		return step
+	}
+	step.beanIDs = findGroupCandidates(group)
+	checkCollectionSize(beanID, field, onMissingDependency, &step)
+	step.candidates = step.beanIDs
+	step.reason = "all beans of the group are injected"
+	return step
+}
+
+// buildBeanInjectionStep function resolves the dependency of the field tagged with `di.inject`. Fields that should be
+// left uninitialized get the injectNothingKind step.
+func buildBeanInjectionStep(beanID string, field reflect.StructField, beanToInject string) injectionStep {
+	step := injectionStep{field: field}
+	onMissingDependency, err := getOnMissingPolicy(field)
+	if err != nil {
+		step.err = err
+		return step
+	}
+	fallbackBeanID, hasFallback := defaultBean.lookup(field)
+	if _, ok := onMissing.lookup(field); ok && hasFallback {
+		step.err = errors.New("di.default and di.onMissing can't be used together")
+		return step
+	}
+	switch kind := field.Type.Kind(); {
+	case kind == reflect.Ptr || kind == reflect.Interface || kind == reflect.Func || kind == reflect.Chan ||
+		kind == reflect.Struct || (isValueKindSupported(field.Type) && beanToInject != ""):
+		if _, ok := exclude.lookup(field); ok {
+			step.err = errors.New("di.exclude can only be used for slices and maps: " + field.Name)
+			return step
+		}
+		if _, ok := minSize.lookup(field); ok {
+			step.err = errors.New("di.min can only be used for slices and maps: " + field.Name)
+			return step
+		}
+		var beanFound bool
+		candidateType := field.Type
+		switch {
+		case isBeanProxy(field.Type):
+			step.kind = injectProxyKind
+			candidateType = reflect.New(candidateType.Elem()).Interface().(beanProxy).beanType()
+		case isProviderType(field.Type) && !injectsFunctionBean(field, beanToInject):
+			step.kind = injectProviderKind
+			candidateType = candidateType.Out(0)
+		default:
+			step.kind = injectBeanKind
+		}
+		if beanToInject == "" { // injecting by type, gotta find the candidate first
+			candidates, err := findInjectionCandidatesFor(beanID, field, candidateType)
+			if err != nil {
+				step.err = err
+				return step
+			}
+			sort.Strings(candidates)
+			step.candidates = candidates
+			if len(candidates) > 1 {
+				beanToInject, step.reason = breakTie(beanID, field, candidates)
+				if beanToInject == "" && onMissingDependency == onMissingNil &&
+					optionalAmbiguityPolicy != FailOnOptionalAmbiguity {
+					beanToInject, step.reason = breakOptionalTie(beanID, field, candidates)
+					if beanToInject == "" {
+						step.kind = injectNothingKind
+						return step
+					}
+				}
+				if beanToInject == "" {
+					step.err = &AmbiguousDependencyError{BeanID: beanID, Field: field.Name, Type: candidateType,
+						Candidates: candidates}
+					return step
+				}
+				beanFound = true
+			}
+			if len(candidates) == 1 {
+				beanToInject = candidates[0]
+				beanFound = true
+				step.reason = "the only candidate of the type is injected"
+			}
+		} else {
+			_, beanFound = scopes[beanToInject]
+			step.reason = "the bean is injected by ID"
+		}
+		if !beanFound && hasFallback {
+			fallbackBeanID = resolveAlias(fallbackBeanID)
+			if _, beanFound = scopes[fallbackBeanID]; beanFound {
+				logrus.WithField("defaultBean", fallbackBeanID).Trace("no dependency found, injecting fallback bean")
+				beanToInject = fallbackBeanID
+				step.reason = "no dependency found, the bean from the di.default tag is injected"
+			}
+		}
+		if !beanFound {
+			switch onMissingDependency {
+			case onMissingNil:
+				logrus.Trace("no dependency found, injecting nil since the dependency marked as optional")
+				step.kind = injectNothingKind
+				step.reason = "no dependency found, the optional field is left uninitialized"
+				return step
+			case onMissingDefault:
+				defaultBeanID, ok := defaultBeans[candidateType]
+				if !ok {
+					step.err = errors.New("no default bean registered for type: " + candidateType.String())
+					return step
+				}
+				logrus.WithField("defaultBean", defaultBeanID).Trace("no dependency found, injecting default bean")
+				beanToInject = defaultBeanID
+				step.reason = "no dependency found, the default bean of the type is injected"
+			default:
+				if beanToInject == "" {
+					step.err = errors.New("no candidates found for the injection")
+				} else {
+					step.err = errors.New("no dependency found")
+				}
+				return step
+			}
+		}
+		if _, beanFound := scopes[beanToInject]; !beanFound {
+			step.err = errors.New("no dependency found: " + beanToInject)
+			return step
+		}
+		if kind != reflect.Ptr && kind != reflect.Interface && step.kind != injectProviderKind { // values and functions
+			if beanType, ok := getBeanType(beanToInject); !ok || !beanType.AssignableTo(field.Type) {
+				step.err = errors.New("bean " + beanToInject + " can't be injected into field " + field.Name +
+					" of type " + field.Type.String())
+				return step
+			}
+		}
+		step.beanIDs = []string{beanToInject}
+	case kind == reflect.Slice || kind == reflect.Map:
+		if field.Type.Elem().Kind() != reflect.Ptr && field.Type.Elem().Kind() != reflect.Interface {
+			step.err = errors.New(unsupportedDependencyType)
+			return step
+		}
+		step.kind = injectSliceKind
+		if field.Type.Kind() == reflect.Map {
+			step.kind = injectMapKind
+		}
+		if step.beanIDs, err = findInjectionCandidatesFor(beanID, field, field.Type.Elem()); err != nil {
+			step.err = err
+			return step
+		}
+		step.beanIDs = excludeCandidates(field, step.beanIDs)
+		sort.Strings(step.beanIDs)
+		checkCollectionSize(beanID, field, onMissingDependency, &step)
+		step.candidates = step.beanIDs
+		step.reason = "all candidates of the element type are injected"
+	default:
+		step.err = errors.New(unsupportedDependencyType)
+	}
+	return step
+}
+
+// execute method injects dependencies of the bean according to the plan.
+func (plan *injectionPlan) execute(ctx context.Context, beanID string, instance interface{}, chain *dependencyChain) error {
+	instanceElement := beans[beanID].Elem()
+	structValue := reflect.ValueOf(instance).Elem()
+	for _, step := range plan.steps {
+		if step.err != nil {
+			return step.err
+		}
+		if step.kind == injectNothingKind {
+			continue
+		}
+		fieldToInject, err := settableField(beanID, structValue, step.field)
+		if err != nil {
+			return err
+		}
+		chain.via(beanID, step.field.Name)
+		switch step.kind {
+		case injectValueKind:
+			if err := injectValue(beanID, fieldToInject, step.field, step.expression); err != nil {
+				return err
+			}
+		case injectGroupKind:
+			if err := injectGroupDependencies(ctx, beanID, fieldToInject, step, chain); err != nil {
+				return err
+			}
+		case injectBeanKind, injectProxyKind, injectProviderKind:
+			beanToInject := step.beanIDs[0]
+			logInjection(beanID, instanceElement, beanToInject)
+			var instanceToInject reflect.Value
+			var err error
+			switch step.kind {
+			case injectProxyKind:
+				instanceToInject, err = newBeanProxy(step.field.Type, beanToInject)
+			case injectProviderKind:
+				instanceToInject, err = newProvider(ctx, beanID, step.field.Type, beanToInject)
+			default:
+				var dependency interface{}
+				dependency, err = getDependencyInstance(ctx, beanID, beanToInject, chain)
+				if err == nil && isNilBean(dependency) {
+					continue
+				}
+				instanceToInject = reflect.ValueOf(dependency)
+			}
+			if err != nil {
+				return err
+			}
+			fieldToInject.Set(instanceToInject)
+		case injectContextKind:
+			fieldToInject.Set(reflect.ValueOf(ctx))
+		case injectHTTPRequestKind, injectResponseWriterKind, injectRequestInfoKind, injectCommandInfoKind:
+			if err := injectScopeDependency(ctx, beanID, fieldToInject, step); err != nil {
+				return err
+			}
+		case injectSliceKind, injectMapKind:
+			if len(step.beanIDs) < 1 {
+				if step.emptyCollection {
+					setEmptyCollection(fieldToInject)
+				}
+				continue
+			}
+			setEmptyCollection(fieldToInject)
+			for _, beanToInject := range step.beanIDs {
+				logInjection(beanID, instanceElement, beanToInject)
+				instanceToInject, err := getDependencyInstance(ctx, beanID, beanToInject, chain)
+				if err != nil {
+					return err
+				}
+				if isNilBean(instanceToInject) {
+					continue
+				}
+				if step.kind == injectSliceKind {
+					fieldToInject.Set(reflect.Append(fieldToInject, reflect.ValueOf(instanceToInject)))
+				} else if err := injectMapEntry(beanID, step.field, fieldToInject, beanToInject,
+					instanceToInject); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func setEmptyCollection(fieldToInject reflect.Value) {
+	if fieldToInject.Kind() == reflect.Slice {
+		fieldToInject.Set(reflect.MakeSlice(fieldToInject.Type(), 0, 0))
+		return
+	}
+	fieldToInject.Set(reflect.MakeMap(fieldToInject.Type()))
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type plannedDependency struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+type plannedBean struct {
+	Scope      Scope                        `di.scope:"prototype"`
+	dependency *plannedDependency           `di.inject:""`
+	provider   Provider[*plannedDependency] `di.inject:""`
+	slice      []*plannedDependency         `di.inject:""`
+	optional   *pooledBuffer                `di.inject:"" di.optional:"true"`
+	value      string                       `di.value:"${planned.value}"`
+}
+
+func (suite *TestSuite) TestInjectionPlanIsReused() {
+	overwritten, err := RegisterBean("dependency", reflect.TypeOf((*plannedDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("planned", reflect.TypeOf((*plannedBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	properties := MapPropertySource{"planned.value": "first"}
+	AddPropertySource(properties)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	first := GetInstance("planned").(*plannedBean)
+	plan := getInjectionPlan("planned")
+	properties["planned.value"] = "second"
+	second := GetInstance("planned").(*plannedBean)
+	assert.Same(suite.T(), plan, getInjectionPlan("planned"))
+	assert.Len(suite.T(), plan.steps, 5)
+	assert.Equal(suite.T(), injectNothingKind, plan.steps[3].kind)
+	assert.NotSame(suite.T(), first, second)
+	assert.NotSame(suite.T(), first.dependency, second.dependency)
+	assert.NotNil(suite.T(), second.provider.Get())
+	assert.Len(suite.T(), second.slice, 1)
+	assert.Nil(suite.T(), second.optional)
+	assert.Equal(suite.T(), "first", first.value)
+	assert.Equal(suite.T(), "second", second.value)
+}
+
+type ambiguousPlannedBean struct {
+	Scope      Scope              `di.scope:"prototype"`
+	dependency *plannedDependency `di.inject:""`
+}
+
+func (suite *TestSuite) TestInjectionPlanReportsResolutionErrorUponCreation() {
+	overwritten, err := RegisterBean("dependency1", reflect.TypeOf((*plannedDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("dependency2", reflect.TypeOf((*plannedDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("ambiguous", reflect.TypeOf((*ambiguousPlannedBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	for i := 0; i < 2; i++ {
+		_, err = GetInstanceSafe("ambiguous")
+		assert.EqualError(suite.T(), err, "more than one candidate found for the injection into field dependency of "+
+			"bean ambiguous (*di.plannedDependency): dependency1, dependency2")
+	}
+}
+
+func (suite *TestSuite) TestInjectionPlansAreInvalidatedUponReset() {
+	overwritten, err := RegisterBean("dependency", reflect.TypeOf((*plannedDependency)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("ambiguous", reflect.TypeOf((*ambiguousPlannedBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), GetInstance("ambiguous").(*ambiguousPlannedBean).dependency)
+	Reset()
+	_, ok := injectionPlans.Load("ambiguous")
+	assert.False(suite.T(), ok)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxStackDepth is the maximum number of frames captured for each tracked instance.
+const maxStackDepth = 32
+
+// LiveInstance describes the instance of the bean that is not released yet, see GetInstanceStats.
+type LiveInstance struct {
+	// Created is the time the instance was created at.
+	Created time.Time
+	// Stack is the stack trace of the goroutine that created the instance.
+	Stack string
+}
+
+// InstanceStats holds the number of created and released instances of the Prototype or Request-scoped bean, see
+// GetInstanceStats.
+type InstanceStats struct {
+	// BeanID is the ID of the bean.
+	BeanID string
+	// Scope is the scope of the bean.
+	Scope Scope
+	// Created is the number of instances created since the tracking was enabled.
+	Created int
+	// Released is the number of instances that went out of scope.
+	Released int
+	// Live are the instances that are not released yet, the oldest first.
+	Live []LiveInstance
+}
+
+type trackedInstances struct {
+	created  int
+	released int
+	live     map[uint64]LiveInstance
+}
+
+// unscopedInstance is the tracked instance of the Prototype bean created outside of a scope, see ReleaseInstance.
+type unscopedInstance struct {
+	beanID  string
+	release func()
+}
+
+var instanceTracking int32
+var trackedInstancesLock sync.Mutex
+var trackedBeans = make(map[string]*trackedInstances)
+var unscopedInstances = make(map[interface{}]unscopedInstance)
+var lastTrackedInstance uint64
+
+// SetInstanceTracking function enables (or disables) the debug mode, in which the container counts instances of
+// Prototype and Request-scoped beans and captures the stack trace of each creation, see GetInstanceStats. Instances are
+// released when the scope they're created in ends (see Middleware and BeginScope), so that the growing number of live
+// instances points at the beans that are created per request but never go out of scope. Prototype beans created outside
+// of a scope are only released by ReleaseInstance, since the container doesn't own them. The tracking is disabled by
+// default, as capturing stack traces is expensive.
+func SetInstanceTracking(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&instanceTracking, 1)
+	} else {
+		atomic.StoreInt32(&instanceTracking, 0)
+	}
+}
+
+// GetInstanceStats function returns the statistics of the tracked instances (see SetInstanceTracking), the beans with
+// the most live instances first.
+func GetInstanceStats() []InstanceStats {
+	trackedInstancesLock.Lock()
+	defer trackedInstancesLock.Unlock()
+	stats := make([]InstanceStats, 0, len(trackedBeans))
+	for beanID, tracked := range trackedBeans {
+		beanStats := InstanceStats{
+			BeanID:   beanID,
+			Created:  tracked.created,
+			Released: tracked.released,
+			Live:     make([]LiveInstance, 0, len(tracked.live)),
+		}
+		ids := make([]uint64, 0, len(tracked.live))
+		for id := range tracked.live {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for _, id := range ids {
+			beanStats.Live = append(beanStats.Live, tracked.live[id])
+		}
+		stats = append(stats, beanStats)
+	}
+	initializeShutdownLock.Lock()
+	for i := range stats {
+		stats[i].Scope = scopes[stats[i].BeanID]
+	}
+	initializeShutdownLock.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if len(stats[i].Live) != len(stats[j].Live) {
+			return len(stats[i].Live) > len(stats[j].Live)
+		}
+		return stats[i].BeanID < stats[j].BeanID
+	})
+	return stats
+}
+
+// trackInstance function records the creation of the Prototype or Request-scoped bean instance, if the tracking is
+// enabled. The instance is released along with the scope it's created in or, if it's created outside of a scope, by
+// ReleaseInstance.
+func trackInstance(ctx context.Context, beanID string, beanInstance interface{}) {
+	if atomic.LoadInt32(&instanceTracking) == 0 {
+		return
+	}
+	if beanScope := scopes[beanID]; beanScope != Prototype && beanScope != Request {
+		return
+	}
+	instance := LiveInstance{Created: time.Now(), Stack: captureStack()}
+	trackedInstancesLock.Lock()
+	tracked, ok := trackedBeans[beanID]
+	if !ok {
+		tracked = &trackedInstances{live: make(map[uint64]LiveInstance)}
+		trackedBeans[beanID] = tracked
+	}
+	lastTrackedInstance++
+	id := lastTrackedInstance
+	tracked.created++
+	tracked.live[id] = instance
+	trackedInstancesLock.Unlock()
+	release := func() {
+		trackedInstancesLock.Lock()
+		defer trackedInstancesLock.Unlock()
+		if _, ok := tracked.live[id]; ok {
+			delete(tracked.live, id)
+			tracked.released++
+		}
+	}
+	if requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext); ok {
+		requestContext.scope.addRelease(release)
+	} else if reflect.ValueOf(beanInstance).Kind() == reflect.Ptr {
+		trackedInstancesLock.Lock()
+		unscopedInstances[beanInstance] = unscopedInstance{beanID: beanID, release: release}
+		trackedInstancesLock.Unlock()
+	}
+}
+
+// takeUnscopedInstance function removes the tracked instance created outside of a scope from the tracking.
+func takeUnscopedInstance(beanInstance interface{}) (unscopedInstance, bool) {
+	trackedInstancesLock.Lock()
+	defer trackedInstancesLock.Unlock()
+	instance, ok := unscopedInstances[beanInstance]
+	delete(unscopedInstances, beanInstance)
+	return instance, ok
+}
+
+// captureStack function formats the stack trace of the caller of the container, omitting the frames of the runtime.
+func captureStack() string {
+	pcs := make([]uintptr, maxStackDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var stack strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+		}
+		if !more {
+			break
+		}
+	}
+	return stack.String()
+}
+
+func resetTrackedInstances() {
+	trackedInstancesLock.Lock()
+	defer trackedInstancesLock.Unlock()
+	trackedBeans = make(map[string]*trackedInstances)
+	unscopedInstances = make(map[interface{}]unscopedInstance)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type trackedPrototype struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+type trackedRequestBean struct {
+	Scope     Scope             `di.scope:"request"`
+	Prototype *trackedPrototype `di.inject:"trackedPrototype"`
+}
+
+func (suite *TestSuite) TestInstanceTracking() {
+	_, err := RegisterBean("trackedPrototype", reflect.TypeOf((*trackedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("trackedRequestBean", reflect.TypeOf((*trackedRequestBean)(nil)))
+	assert.NoError(suite.T(), err)
+	SetInstanceTracking(true)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("trackedPrototype")
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	_, ok := FromContext[*trackedRequestBean](ctx, "trackedRequestBean")
+	assert.True(suite.T(), ok)
+	stats := GetInstanceStats()
+	if assert.Len(suite.T(), stats, 2) {
+		assert.Equal(suite.T(), "trackedPrototype", stats[0].BeanID)
+		assert.Equal(suite.T(), Prototype, stats[0].Scope)
+		assert.Equal(suite.T(), 2, stats[0].Created)
+		assert.Len(suite.T(), stats[0].Live, 2)
+		assert.Equal(suite.T(), "trackedRequestBean", stats[1].BeanID)
+		assert.Equal(suite.T(), Request, stats[1].Scope)
+		if assert.Len(suite.T(), stats[1].Live, 1) {
+			assert.Contains(suite.T(), stats[1].Live[0].Stack, "TestInstanceTracking")
+			assert.False(suite.T(), stats[1].Live[0].Created.IsZero())
+		}
+	}
+	done()
+	stats = GetInstanceStats()
+	if assert.Len(suite.T(), stats, 2) {
+		assert.Equal(suite.T(), "trackedPrototype", stats[0].BeanID)
+		assert.Equal(suite.T(), 2, stats[0].Created)
+		assert.Equal(suite.T(), 1, stats[0].Released)
+		assert.Len(suite.T(), stats[0].Live, 1)
+		assert.Equal(suite.T(), "trackedRequestBean", stats[1].BeanID)
+		assert.Equal(suite.T(), 1, stats[1].Created)
+		assert.Equal(suite.T(), 1, stats[1].Released)
+		assert.Empty(suite.T(), stats[1].Live)
+	}
+	Reset()
+	assert.Empty(suite.T(), GetInstanceStats())
+	assert.Zero(suite.T(), instanceTracking)
+}
+
+func (suite *TestSuite) TestInstanceTrackingIsDisabledByDefault() {
+	_, err := RegisterBean("trackedPrototype", reflect.TypeOf((*trackedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("trackedPrototype")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), GetInstanceStats())
+}
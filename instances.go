@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// GetBeanIDs function returns IDs of the beans assignable to the given type (sorted), i.e. the ones that would be
+// considered as candidates for the injection by type. Like GetBeanTypes, it omits bean factories of unknown type.
+func GetBeanIDs(beanType reflect.Type) []string {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	beanIDs := findInjectionCandidates(beanType)
+	sort.Strings(beanIDs)
+	return beanIDs
+}
+
+// GetBeanIDsOf function is a generic version of GetBeanIDs, returning IDs of the beans assignable to `T`.
+func GetBeanIDsOf[T any]() []string {
+	return GetBeanIDs(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// GetInstancesOf function returns instances of all beans assignable to `T` (e.g. implementations of an interface),
+// sorted by bean IDs. Request-scoped and Tenant-scoped beans are skipped, since they only exist within their scopes;
+// new instances of Prototype beans are created. Non-Singleton beans produced by factories are only taken into account
+// if their type is declared (see RegisterTypedBeanFactory).
+func GetInstancesOf[T any]() ([]T, error) {
+	beanIDs, err := findInstanceCandidates[T]()
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]T, 0, len(beanIDs))
+	for _, beanID := range beanIDs {
+		beanInstance, ok, err := getInstanceOf[T](beanID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			instances = append(instances, beanInstance)
+		}
+	}
+	return instances, nil
+}
+
+// GetInstanceMapOf function is similar to GetInstancesOf, but returns instances of the beans keyed by their IDs.
+func GetInstanceMapOf[T any]() (map[string]T, error) {
+	beanIDs, err := findInstanceCandidates[T]()
+	if err != nil {
+		return nil, err
+	}
+	instances := make(map[string]T, len(beanIDs))
+	for _, beanID := range beanIDs {
+		beanInstance, ok, err := getInstanceOf[T](beanID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			instances[beanID] = beanInstance
+		}
+	}
+	return instances, nil
+}
+
+// findInstanceCandidates function returns IDs of the beans assignable to `T` (sorted, for the sake of determinism).
+func findInstanceCandidates[T any]() ([]string, error) {
+	if atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+		return nil, errors.New("container is not initialized: can't lookup instances of beans yet")
+	}
+	var beanIDs []string
+	for _, beanID := range findInjectionCandidates(reflect.TypeOf((*T)(nil)).Elem()) {
+		if beanScope := scopes[beanID]; beanScope != Request && beanScope != Tenant {
+			beanIDs = append(beanIDs, beanID)
+		}
+	}
+	sort.Strings(beanIDs)
+	return beanIDs, nil
+}
+
+// getInstanceOf function returns the instance of the bean converted to `T`. The returned boolean is `false` for nil
+// beans.
+func getInstanceOf[T any](beanID string) (T, bool, error) {
+	var zero T
+	beanInstance, err := GetInstanceSafe(beanID)
+	if err != nil {
+		return zero, false, err
+	}
+	if isNilBean(beanInstance) {
+		return zero, false, nil
+	}
+	typedBeanInstance, ok := beanInstance.(T)
+	if !ok {
+		return zero, false, fmt.Errorf("bean %s is of type %T, not %s", beanID, beanInstance,
+			reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typedBeanInstance, true, nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type englishGreeter struct{}
+
+func (*englishGreeter) Greet() string {
+	return "hello"
+}
+
+type frenchGreeter struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+func (*frenchGreeter) Greet() string {
+	return "bonjour"
+}
+
+type requestGreeter struct {
+	Scope Scope `di.scope:"request"`
+}
+
+func (*requestGreeter) Greet() string {
+	return "hey"
+}
+
+func (suite *TestSuite) TestGetInstancesOf() {
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("french", reflect.TypeOf((*frenchGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("request", reflect.TypeOf((*requestGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("german", Singleton, func(context.Context) (interface{}, error) {
+		return &typedGreeter{greeting: "hallo"}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanInstance("notGreeter", &resolvedRepository{})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	greeters, err := GetInstancesOf[greeter]()
+	assert.NoError(suite.T(), err)
+	var greetings []string
+	for _, g := range greeters {
+		greetings = append(greetings, g.Greet())
+	}
+	assert.Equal(suite.T(), []string{"hello", "bonjour", "hallo"}, greetings)
+	greeterMap, err := GetInstanceMapOf[greeter]()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), greeterMap, 3)
+	assert.Same(suite.T(), GetInstance("english"), greeterMap["english"])
+	assert.Same(suite.T(), GetInstance("german"), greeterMap["german"])
+	assert.IsType(suite.T(), &frenchGreeter{}, greeterMap["french"])
+}
+
+func (suite *TestSuite) TestGetInstancesOfWithoutCandidates() {
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	greeters, err := GetInstancesOf[greeter]()
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), greeters)
+	greeterMap, err := GetInstanceMapOf[greeter]()
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), greeterMap)
+}
+
+func (suite *TestSuite) TestGetInstancesOfBeforeInitialization() {
+	_, err := GetInstancesOf[greeter]()
+	assert.EqualError(suite.T(), err, "container is not initialized: can't lookup instances of beans yet")
+	_, err = GetInstanceMapOf[greeter]()
+	assert.EqualError(suite.T(), err, "container is not initialized: can't lookup instances of beans yet")
+}
+
+func (suite *TestSuite) TestGetBeanIDs() {
+	_, err := RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("request", reflect.TypeOf((*requestGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterTypedBeanFactory("typed", Prototype, func(context.Context) (*typedGreeter, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("german", Singleton, func(context.Context) (interface{}, error) {
+		return &typedGreeter{greeting: "hallo"}, nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"english", "request", "typed"}, GetBeanIDsOf[greeter]())
+	assert.Equal(suite.T(), []string{"english"}, GetBeanIDs(reflect.TypeOf((*englishGreeter)(nil))))
+	assert.Empty(suite.T(), GetBeanIDsOf[*frenchGreeter]())
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"english", "german", "request", "typed"}, GetBeanIDsOf[greeter]())
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Invocation describes the call of a method of the intercepted bean.
+type Invocation struct {
+	// BeanID is the ID of the intercepted bean.
+	BeanID string
+	// Method is the name of the called method.
+	Method string
+	// Args are the arguments of the call (variadic arguments are passed as a slice).
+	Args    []interface{}
+	proceed func() (interface{}, error)
+}
+
+// Proceed method calls the next interceptor or, if it's the last one, the method of the bean. The returned value is
+// the result of the method: nil for methods returning nothing (or just an error), the returned value otherwise.
+func (inv Invocation) Proceed() (interface{}, error) {
+	return inv.proceed()
+}
+
+// InterceptorMatcher selects the beans to intercept, given their IDs and types.
+type InterceptorMatcher func(beanID string, beanType reflect.Type) bool
+
+// InterceptFunc is a function the proxy calls upon every call of the method of the interface, passing the name of the
+// method, its arguments and the function calling the method of the bean.
+type InterceptFunc func(method string, args []interface{}, proceed func() (interface{}, error)) (interface{}, error)
+
+// interceptor is an around advice applied to beans selected by the matcher.
+type interceptor struct {
+	matcher InterceptorMatcher
+	around  func(inv Invocation) (interface{}, error)
+}
+
+var interceptors []interceptor
+
+// proxyFactory is a constructor of proxies wrapping the target bean.
+type proxyFactory func(target interface{}, intercept InterceptFunc) interface{}
+
+// proxyFactories keep constructors of proxies by the types of the interfaces they implement.
+var proxyFactories = make(map[reflect.Type]proxyFactory)
+
+// RegisterInterceptor function registers the interceptor wrapping the calls of the beans selected by the matcher, e.g.
+// to apply logging, retries, timing or authorization to all of them declaratively. Matched beans are replaced with
+// proxies (see RegisterProxy) implementing the interfaces of the beans, so they should be injected by interfaces.
+// Beans implementing none of the interfaces with registered proxies are left as is. When multiple interceptors match
+// the bean, the first registered one is the outermost. The interceptor calls the bean (or the next interceptor) with
+// Invocation.Proceed. If it returns an error for the method without the error result, the proxy panics.
+func RegisterInterceptor(matcher InterceptorMatcher, around func(inv Invocation) (interface{}, error)) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register interceptor")
+	}
+	if matcher == nil || around == nil {
+		return errors.New("interceptor and its matcher can't be nil")
+	}
+	interceptors = append(interceptors, interceptor{matcher: matcher, around: around})
+	return nil
+}
+
+// RegisterProxy function registers the constructor of proxies implementing the interface `I`, that call the intercept
+// function upon every method call. Proxies are usually generated with `dicodegen -proxies`, but can be written by hand.
+func RegisterProxy[I any](newProxy func(target I, intercept InterceptFunc) I) error {
+	proxyType := reflect.TypeOf((*I)(nil)).Elem()
+	if proxyType.Kind() != reflect.Interface {
+		return errors.New("proxies can only implement interfaces: " + proxyType.String())
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register proxy")
+	}
+	proxyFactories[proxyType] = func(target interface{}, intercept InterceptFunc) interface{} {
+		return newProxy(target.(I), intercept)
+	}
+	return nil
+}
+
+// MatchBeans function returns the matcher selecting beans with the given IDs.
+func MatchBeans(beanIDs ...string) InterceptorMatcher {
+	ids := make(map[string]bool, len(beanIDs))
+	for _, beanID := range beanIDs {
+		ids[beanID] = true
+	}
+	return func(beanID string, _ reflect.Type) bool {
+		return ids[beanID]
+	}
+}
+
+// MatchImplementations function returns the matcher selecting beans implementing the given interface.
+func MatchImplementations(interfaceType reflect.Type) InterceptorMatcher {
+	return func(_ string, beanType reflect.Type) bool {
+		return beanType.Implements(interfaceType)
+	}
+}
+
+// interceptBean function wraps the bean in the proxy, if any interceptors match it.
+func interceptBean(beanID string, instance interface{}) (interface{}, error) {
+	if len(interceptors) == 0 {
+		return instance, nil
+	}
+	beanType := reflect.TypeOf(instance)
+	var matched []interceptor
+	for _, candidate := range interceptors {
+		if candidate.matcher(beanID, beanType) {
+			matched = append(matched, candidate)
+		}
+	}
+	if len(matched) == 0 {
+		return instance, nil
+	}
+	var proxyTypes []string
+	var newProxy proxyFactory
+	for interfaceType, factory := range proxyFactories {
+		if beanType.Implements(interfaceType) {
+			proxyTypes = append(proxyTypes, interfaceType.String())
+			newProxy = factory
+		}
+	}
+	if len(proxyTypes) == 0 {
+		logrus.WithField("beanID", beanID).Debug("no proxy is registered for the interfaces of the intercepted bean")
+		return instance, nil
+	}
+	if len(proxyTypes) > 1 {
+		sort.Strings(proxyTypes)
+		return nil, errors.New("bean " + beanID + " implements multiple interfaces with proxies: " +
+			strings.Join(proxyTypes, ", "))
+	}
+	return newProxy(instance, func(method string, args []interface{}, proceed func() (interface{}, error)) (interface{}, error) {
+		call := proceed
+		for i := len(matched) - 1; i >= 0; i-- {
+			around, invocation := matched[i].around, Invocation{BeanID: beanID, Method: method, Args: args, proceed: call}
+			call = func() (interface{}, error) {
+				return around(invocation)
+			}
+		}
+		return call()
+	}), nil
+}
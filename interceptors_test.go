@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greetingService interface {
+	Greet(name string) (string, error)
+	Reset()
+}
+
+type englishGreetingService struct {
+	resets int
+}
+
+func (egs *englishGreetingService) Greet(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("name is empty")
+	}
+	return "Hello, " + name + "!", nil
+}
+
+func (egs *englishGreetingService) Reset() {
+	egs.resets++
+}
+
+// greetingServiceProxy is a proxy of greetingService, the way `dicodegen -proxies` generates it.
+type greetingServiceProxy struct {
+	target    greetingService
+	intercept InterceptFunc
+}
+
+func newGreetingServiceProxy(target greetingService, intercept InterceptFunc) greetingService {
+	return &greetingServiceProxy{target: target, intercept: intercept}
+}
+
+func (proxy *greetingServiceProxy) Greet(name string) (string, error) {
+	result, err := proxy.intercept("Greet", []interface{}{name}, func() (interface{}, error) {
+		return proxy.target.Greet(name)
+	})
+	value, _ := result.(string)
+	return value, err
+}
+
+func (proxy *greetingServiceProxy) Reset() {
+	_, err := proxy.intercept("Reset", []interface{}{}, func() (interface{}, error) {
+		proxy.target.Reset()
+		return nil, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type greetingConsumer struct {
+	service greetingService `di.inject:""`
+}
+
+func (suite *TestSuite) TestInterceptor() {
+	overwritten, err := RegisterBean("greetingService", reflect.TypeOf((*englishGreetingService)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*greetingConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RegisterProxy(newGreetingServiceProxy))
+	var calls []string
+	err = RegisterInterceptor(MatchBeans("greetingService"), func(inv Invocation) (interface{}, error) {
+		calls = append(calls, inv.BeanID+"."+inv.Method)
+		return inv.Proceed()
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterInterceptor(MatchImplementations(reflect.TypeOf((*greetingService)(nil)).Elem()),
+		func(inv Invocation) (interface{}, error) {
+			result, err := inv.Proceed()
+			if greeting, ok := result.(string); ok {
+				return strings.ToUpper(greeting), err
+			}
+			return result, err
+		})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	service := GetInstance("consumer").(*greetingConsumer).service
+	assert.Same(suite.T(), GetInstance("greetingService"), service)
+	greeting, err := service.Greet("world")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "HELLO, WORLD!", greeting)
+	_, err = service.Greet("")
+	assert.EqualError(suite.T(), err, "name is empty")
+	service.Reset()
+	assert.Equal(suite.T(), 1, service.(*greetingServiceProxy).target.(*englishGreetingService).resets)
+	assert.Equal(suite.T(), []string{"greetingService.Greet", "greetingService.Greet", "greetingService.Reset"}, calls)
+}
+
+func (suite *TestSuite) TestInterceptorShortCircuit() {
+	overwritten, err := RegisterBean("greetingService", reflect.TypeOf((*englishGreetingService)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RegisterProxy(newGreetingServiceProxy))
+	err = RegisterInterceptor(MatchBeans("greetingService"), func(inv Invocation) (interface{}, error) {
+		if inv.Method == "Reset" || inv.Args[0] == "stranger" {
+			return nil, errors.New("access denied")
+		}
+		return inv.Proceed()
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	service := GetInstance("greetingService").(greetingService)
+	_, err = service.Greet("stranger")
+	assert.EqualError(suite.T(), err, "access denied")
+	greeting, err := service.Greet("friend")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Hello, friend!", greeting)
+	assert.PanicsWithError(suite.T(), "access denied", service.Reset)
+}
+
+func (suite *TestSuite) TestInterceptorWithoutProxy() {
+	overwritten, err := RegisterBean("greetingService", reflect.TypeOf((*englishGreetingService)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterInterceptor(MatchBeans("greetingService"), func(inv Invocation) (interface{}, error) {
+		return inv.Proceed()
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &englishGreetingService{}, GetInstance("greetingService"))
+}
+
+func (suite *TestSuite) TestInterceptorErrors() {
+	assert.EqualError(suite.T(), RegisterInterceptor(nil, nil), "interceptor and its matcher can't be nil")
+	err := RegisterProxy(func(target *englishGreetingService, intercept InterceptFunc) *englishGreetingService {
+		return target
+	})
+	assert.EqualError(suite.T(), err, "proxies can only implement interfaces: *di.englishGreetingService")
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Error(suite.T(), RegisterProxy(newGreetingServiceProxy))
+	assert.Error(suite.T(), RegisterInterceptor(MatchBeans("greetingService"), func(inv Invocation) (interface{}, error) {
+		return inv.Proceed()
+	}))
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+)
+
+// invokers are functions called upon the container initialization, in registration order.
+var invokers []reflect.Value
+
+// RegisterInvoker function registers the function that is called upon the container initialization, once singletons
+// are initialized (like `fx.Invoke` of uber/fx, so that modules shipping fx options can be translated into
+// registrations: constructors of `fx.Provide` are registered with RegisterProviders, functions of `fx.Invoke` with
+// RegisterInvoker). Its parameters are resolved by type (the parameter of `context.Context` type receives the
+// background context). It may return the error, which fails the container initialization.
+func RegisterInvoker(invoker interface{}) error {
+	invokerValue := reflect.ValueOf(invoker)
+	if invokerValue.Kind() != reflect.Func || invokerValue.IsNil() {
+		return errors.New("invoker must be a function")
+	}
+	invokerType := invokerValue.Type()
+	if invokerType.IsVariadic() || invokerType.NumOut() > 1 ||
+		(invokerType.NumOut() == 1 && invokerType.Out(0) != errorType) {
+		return errors.New("unsupported invoker signature: " + invokerType.String())
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register new invoker")
+	}
+	invokers = append(invokers, invokerValue)
+	return nil
+}
+
+// runInvokers function calls the registered invokers, resolving their parameters by type.
+func runInvokers() error {
+	for i, invoker := range invokers {
+		function := "invoker #" + strconv.Itoa(i+1)
+		invokerType := invoker.Type()
+		args := make([]reflect.Value, invokerType.NumIn())
+		for j := range args {
+			paramType := invokerType.In(j)
+			if paramType == contextType {
+				args[j] = reflect.ValueOf(context.Background())
+				continue
+			}
+			dependencyID, err := resolveParameter(function, "", j, paramType)
+			if err != nil {
+				return err
+			}
+			dependency, err := GetInstanceSafe(dependencyID)
+			if err != nil {
+				return err
+			}
+			if isNilBean(dependency) {
+				args[j] = reflect.Zero(paramType)
+				continue
+			}
+			args[j] = reflect.ValueOf(dependency)
+		}
+		results := invoker.Call(args)
+		if len(results) == 1 && !results[0].IsNil() {
+			return results[0].Interface().(error)
+		}
+	}
+	return nil
+}
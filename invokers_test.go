@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestSuite) TestInvokers() {
+	var invoked []string
+	err := RegisterProviders(Singleton, newWireConfig)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	err = RegisterInvoker(func(ctx context.Context, config *wireConfig, greeter greeter) {
+		assert.NotNil(suite.T(), ctx)
+		assert.Same(suite.T(), GetInstance(BeanIDOfT[*wireConfig]()), config)
+		invoked = append(invoked, greeter.Greet())
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterInvoker(func() error {
+		invoked = append(invoked, "second")
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{GetInstance("english").(greeter).Greet(), "second"}, invoked)
+}
+
+func (suite *TestSuite) TestInvokerErrors() {
+	err := RegisterInvoker(func() error {
+		return errors.New("invoker failed")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "invoker failed")
+	Reset()
+	err = RegisterInvoker(func(*wireConfig) {})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no candidates found for parameter #1 of invoker #1: *di.wireConfig")
+}
+
+func (suite *TestSuite) TestUnsupportedInvokers() {
+	assert.EqualError(suite.T(), RegisterInvoker(42), "invoker must be a function")
+	assert.EqualError(suite.T(), RegisterInvoker(func() int { return 0 }), "unsupported invoker signature: func() int")
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.EqualError(suite.T(), RegisterInvoker(func() {}),
+		"container is already initialized: can't register new invoker")
+}
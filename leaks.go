@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// closeableBeans keeps IDs of io.Closer beans created in the scope. It's kept apart from the scope, because it must not
+// reference the scope's context (which may be referenced by the beans themselves): this way it's garbage collected
+// along with the scope, and if it still holds any IDs by then, the scope has never been closed.
+type closeableBeans struct {
+	beanIDs []string
+}
+
+var leakHandler func(beanID string)
+
+// leakHandlerLock guards the leak handler: it's called by the finalizer, in its own goroutine.
+var leakHandlerLock sync.RWMutex
+
+// SetLeakHandler function sets the handler called for every io.Closer Request-scoped bean, whose scope is garbage
+// collected without being closed (e.g. the context returned by BeginScope is dropped without calling `done`, or the
+// handler has hijacked the connection). The leak is only detected once the garbage collector finalizes the scope, so
+// the handler is called with a delay, in a separate goroutine. Nil handler, which is the default, logs such beans with
+// the warning level.
+func SetLeakHandler(handler func(beanID string)) {
+	leakHandlerLock.Lock()
+	defer leakHandlerLock.Unlock()
+	leakHandler = handler
+}
+
+func getLeakHandler() func(beanID string) {
+	leakHandlerLock.RLock()
+	defer leakHandlerLock.RUnlock()
+	return leakHandler
+}
+
+// reportLeakedBeans function is the finalizer of closeableBeans: it reports the beans that are not closed.
+func reportLeakedBeans(closeables *closeableBeans) {
+	if len(closeables.beanIDs) == 0 {
+		return
+	}
+	handler := getLeakHandler()
+	for _, beanID := range closeables.beanIDs {
+		if handler != nil {
+			handler(beanID)
+		} else {
+			logrus.WithField("beanID", beanID).Warn("request-scoped bean is garbage collected without being closed")
+		}
+	}
+}
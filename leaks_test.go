@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestSuite) registerLeakingBeans() {
+	var closedBeans []string
+	for _, beanID := range []string{"leakingBean", "notCloseableBean"} {
+		beanID := beanID
+		_, err := RegisterBeanFactory(beanID, Request, func(context.Context) (interface{}, error) {
+			if beanID == "notCloseableBean" {
+				return &resolvedRepository{}, nil
+			}
+			return &orderedCloseableBean{id: beanID, closed: &closedBeans}, nil
+		})
+		assert.NoError(suite.T(), err)
+	}
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+}
+
+// lookupRequestBeans function looks up Request-scoped beans in a new scope, which is closed or dropped afterwards.
+func lookupRequestBeans(closeScope bool, beanIDs ...string) {
+	ctx, done := BeginScope(context.Background())
+	for _, beanID := range beanIDs {
+		ctx.Value(BeanKey(beanID))
+	}
+	if closeScope {
+		done()
+	}
+}
+
+func (suite *TestSuite) TestLeakHandler() {
+	var lock sync.Mutex
+	var leakedBeans []string
+	SetLeakHandler(func(beanID string) {
+		lock.Lock()
+		defer lock.Unlock()
+		if beanID == "leakingBean" || beanID == "notCloseableBean" {
+			leakedBeans = append(leakedBeans, beanID)
+		}
+	})
+	suite.registerLeakingBeans()
+	lookupRequestBeans(false, "leakingBean", "notCloseableBean")
+	assert.Eventually(suite.T(), func() bool {
+		runtime.GC()
+		lock.Lock()
+		defer lock.Unlock()
+		return len(leakedBeans) > 0
+	}, time.Second, 10*time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(suite.T(), []string{"leakingBean"}, leakedBeans)
+}
+
+func (suite *TestSuite) TestClosedScopeIsNotReportedAsLeak() {
+	leaks := make(chan string, 1)
+	SetLeakHandler(func(beanID string) {
+		if beanID != "leakingBean" {
+			return
+		}
+		select {
+		case leaks <- beanID:
+		default:
+		}
+	})
+	suite.registerLeakingBeans()
+	lookupRequestBeans(true, "leakingBean")
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+	}
+	assert.Never(suite.T(), func() bool {
+		return len(leaks) > 0
+	}, 50*time.Millisecond, 10*time.Millisecond)
+}
+
+func (suite *TestSuite) TestLeakHandlerIsReset() {
+	SetLeakHandler(func(string) {})
+	assert.NotNil(suite.T(), getLeakHandler())
+	Reset()
+	assert.Nil(suite.T(), getLeakHandler())
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lifecycle is an interface marking singleton beans that should be started after the container is fully wired and
+// stopped before it's closed (e.g. servers, consumers, schedulers).
+type Lifecycle interface {
+	// Start method is called by Start function.
+	Start(ctx context.Context) error
+	// Stop method is called by Stop function.
+	Stop(ctx context.Context) error
+}
+
+// Phased is an interface that Lifecycle beans can implement to control the order of starting: beans with lower phases
+// are started first and stopped last. The phase of Lifecycle beans not implementing this interface is 0.
+type Phased interface {
+	// Phase method returns the phase of the bean.
+	Phase() int
+}
+
+var lifecycleLock sync.Mutex
+var startedBeans []startedBean
+
+type startedBean struct {
+	beanID string
+	bean   Lifecycle
+}
+
+// Start function starts all singleton beans implementing Lifecycle in ascending phase order (beans with equal phases
+// are started in order of their IDs). If some bean fails to start, already started beans are stopped.
+func Start(ctx context.Context) error {
+	if atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+		return errors.New("container is not initialized: can't start beans")
+	}
+	lifecycleLock.Lock()
+	defer lifecycleLock.Unlock()
+	if startedBeans != nil {
+		return errors.New("beans are already started")
+	}
+	beanIDs := make([]string, 0)
+	for beanID, instance := range singletonInstances {
+		if _, ok := instance.(Lifecycle); ok {
+			beanIDs = append(beanIDs, beanID)
+		}
+	}
+	sort.Slice(beanIDs, func(i, j int) bool {
+		iPhase, jPhase := getPhase(singletonInstances[beanIDs[i]]), getPhase(singletonInstances[beanIDs[j]])
+		if iPhase != jPhase {
+			return iPhase < jPhase
+		}
+		return beanIDs[i] < beanIDs[j]
+	})
+	started := make([]startedBean, 0, len(beanIDs))
+	for _, beanID := range beanIDs {
+		logrus.WithField("beanID", beanID).Trace("starting bean")
+		bean := singletonInstances[beanID].(Lifecycle)
+		if err := bean.Start(ctx); err != nil {
+			if stopErr := stopBeans(ctx, started); stopErr != nil {
+				logrus.Error(stopErr)
+			}
+			return errors.New("can't start bean " + beanID + ": " + err.Error())
+		}
+		started = append(started, startedBean{beanID: beanID, bean: bean})
+	}
+	startedBeans = started
+	return nil
+}
+
+// Stop function stops started Lifecycle beans in reverse order. All the beans are stopped even if some of them fail,
+// returned error joins errors of all failed beans. The beans are taken off the started list before being stopped, so
+// a bean that doesn't return from Stop doesn't block subsequent Stop calls (e.g. the one made by Close).
+func Stop(ctx context.Context) error {
+	lifecycleLock.Lock()
+	beans := startedBeans
+	startedBeans = nil
+	lifecycleLock.Unlock()
+	return stopBeans(ctx, beans)
+}
+
+func stopBeans(ctx context.Context, beans []startedBean) error {
+	var errs []error
+	for i := len(beans) - 1; i >= 0; i-- {
+		logrus.WithField("beanID", beans[i].beanID).Trace("stopping bean")
+		if err := beans[i].bean.Stop(ctx); err != nil {
+			errs = append(errs, errors.New("can't stop bean "+beans[i].beanID+": "+err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func getPhase(instance interface{}) int {
+	if phased, ok := instance.(Phased); ok {
+		return phased.Phase()
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lifecycleBean struct {
+	name     string
+	phase    int
+	startErr error
+	stopErr  error
+	log      *[]string
+}
+
+func (lb *lifecycleBean) Start(context.Context) error {
+	if lb.startErr != nil {
+		return lb.startErr
+	}
+	*lb.log = append(*lb.log, "start "+lb.name)
+	return nil
+}
+
+func (lb *lifecycleBean) Stop(context.Context) error {
+	*lb.log = append(*lb.log, "stop "+lb.name)
+	return lb.stopErr
+}
+
+func (lb *lifecycleBean) Phase() int {
+	return lb.phase
+}
+
+type unphasedLifecycleBean struct {
+	log *[]string
+}
+
+func (ulb *unphasedLifecycleBean) Start(context.Context) error {
+	*ulb.log = append(*ulb.log, "start unphased")
+	return nil
+}
+
+func (ulb *unphasedLifecycleBean) Stop(context.Context) error {
+	*ulb.log = append(*ulb.log, "stop unphased")
+	return nil
+}
+
+func (suite *TestSuite) registerLifecycleBeans(beans ...*lifecycleBean) {
+	for _, bean := range beans {
+		overwritten, err := RegisterBeanInstance(bean.name, bean)
+		assert.False(suite.T(), overwritten)
+		assert.NoError(suite.T(), err)
+	}
+}
+
+func (suite *TestSuite) TestLifecycle() {
+	var log []string
+	assert.Error(suite.T(), Start(context.Background()))
+	suite.registerLifecycleBeans(
+		&lifecycleBean{name: "server", phase: 10, log: &log},
+		&lifecycleBean{name: "consumer", phase: 0, log: &log},
+		&lifecycleBean{name: "database", phase: -10, log: &log, stopErr: errors.New("timeout")},
+		&lifecycleBean{name: "cache", phase: 0, log: &log},
+	)
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Start(context.Background()))
+	assert.Error(suite.T(), Start(context.Background()))
+	assert.Equal(suite.T(), []string{"start database", "start cache", "start consumer", "start server"}, log)
+	log = nil
+	assert.EqualError(suite.T(), Stop(context.Background()), "can't stop bean database: timeout")
+	assert.Equal(suite.T(), []string{"stop server", "stop consumer", "stop cache", "stop database"}, log)
+	log = nil
+	assert.NoError(suite.T(), Stop(context.Background()))
+	assert.Empty(suite.T(), log)
+}
+
+func (suite *TestSuite) TestLifecycleStartFailure() {
+	var log []string
+	suite.registerLifecycleBeans(
+		&lifecycleBean{name: "database", log: &log},
+		&lifecycleBean{name: "server", phase: 1, log: &log, startErr: errors.New("port is busy")},
+	)
+	overwritten, err := RegisterBeanInstance("unphased", &unphasedLifecycleBean{log: &log})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.EqualError(suite.T(), Start(context.Background()), "can't start bean server: port is busy")
+	assert.Equal(suite.T(), []string{"start database", "start unphased", "stop unphased", "stop database"}, log)
+}
+
+func (suite *TestSuite) TestCloseStopsLifecycleBeans() {
+	var log []string
+	suite.registerLifecycleBeans(&lifecycleBean{name: "server", log: &log})
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Start(context.Background()))
+	Close()
+	assert.Equal(suite.T(), []string{"start server", "stop server"}, log)
+}
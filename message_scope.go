@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+)
+
+// WrapMessageHandler function wraps the handler of messages consumed from a queue (Kafka, NATS, SQS, etc.), so that
+// every message gets its own set of Request-scoped beans, just like web requests do with Middleware. Beans are created
+// lazily in the context passed to the handler, and the ones implementing io.Closer are closed after the message is
+// handled (see SetRequestBeansClosePolicy).
+func WrapMessageHandler[M any](handler func(ctx context.Context, msg M) error) func(ctx context.Context, msg M) error {
+	return func(ctx context.Context, msg M) error {
+		scopeContext, done := BeginScope(ctx)
+		defer done()
+		return handler(scopeContext, msg)
+	}
+}
+
+// BeginScope function creates a scoped context for a unit of work outside of HTTP (e.g. a CLI command or a cron job):
+// Request-scoped beans are created lazily in the returned context, and the ones implementing io.Closer are closed when
+// `done` is called (see SetRequestBeansClosePolicy). Calling `done` more than once has no effect. The scope gets its
+// own RequestInfo with a generated ID.
+//
+//	ctx, done := di.BeginScope(context.Background())
+//	defer done()
+func BeginScope(ctx context.Context) (scopeContext context.Context, done func()) {
+	requestContext := beginScope(ctx)
+	return requestContext, requestContext.scope.close
+}
+
+// beginScope function creates the scoped context of a unit of work outside of HTTP, shared by BeginScope and
+// WrapCommand.
+func beginScope(ctx context.Context) *requestScopeContext {
+	requestContext := newRequestScopeContext(ctx)
+	requestContext.scope.info = newRequestInfo("", "")
+	return requestContext
+}
+
+// ScopeFromContext function returns the context of the unit of work (web request, message, etc.) holding Request-scoped
+// beans, that the passed context belongs to, or `false` if it doesn't belong to any.
+func ScopeFromContext(ctx context.Context) (context.Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	scopeContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext)
+	if !ok {
+		return nil, false
+	}
+	return scopeContext, true
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderMessage struct {
+	id string
+}
+
+func (suite *TestSuite) TestWrapMessageHandler() {
+	var closedBeans []string
+	overwritten, err := RegisterBeanFactory("unitOfWork", Request, func(context.Context) (interface{}, error) {
+		return &orderedCloseableBean{id: "unitOfWork", closed: &closedBeans}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var handledBeans []interface{}
+	handler := WrapMessageHandler(func(ctx context.Context, msg orderMessage) error {
+		scopeContext, ok := ScopeFromContext(ctx)
+		assert.True(suite.T(), ok)
+		assert.Same(suite.T(), ctx, scopeContext)
+		bean := MustFromContext[*orderedCloseableBean](ctx, "unitOfWork")
+		assert.Same(suite.T(), bean, MustFromContext[*orderedCloseableBean](ctx, "unitOfWork"))
+		handledBeans = append(handledBeans, bean)
+		if msg.id == "" {
+			return errors.New("empty id")
+		}
+		return nil
+	})
+	assert.NoError(suite.T(), handler(context.Background(), orderMessage{id: "1"}))
+	assert.EqualError(suite.T(), handler(context.Background(), orderMessage{}), "empty id")
+	assert.Len(suite.T(), handledBeans, 2)
+	assert.NotSame(suite.T(), handledBeans[0], handledBeans[1])
+	assert.Equal(suite.T(), []string{"unitOfWork", "unitOfWork"}, closedBeans)
+	_, ok := ScopeFromContext(context.Background())
+	assert.False(suite.T(), ok)
+}
+
+func (suite *TestSuite) TestBeginScope() {
+	var closedBeans []string
+	overwritten, err := RegisterBeanFactory("job", Request, func(context.Context) (interface{}, error) {
+		return &orderedCloseableBean{id: "job", closed: &closedBeans}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	bean, ok := FromContext[*orderedCloseableBean](ctx, "job")
+	assert.True(suite.T(), ok)
+	assert.Same(suite.T(), bean, MustFromContext[*orderedCloseableBean](ctx, "job"))
+	assert.Empty(suite.T(), closedBeans)
+	done()
+	done()
+	assert.Equal(suite.T(), []string{"job"}, closedBeans)
+}
@@ -16,40 +16,178 @@ package di
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 )
 
 // BeanKey is as a Context key, because usage of string keys is discouraged (due to obvious reasons).
+//
+// Deprecated: any package can create a BeanKey, so such keys can be collided with (e.g. shadowed by a value stored by
+// an unrelated middleware) or forged. Use RequestBean, FromContext or RequestScope instead.
 type BeanKey string
 
+// beanKey is a Context key of Request-scoped beans. Unlike BeanKey, it's unexported, so it can't be collided with or
+// forged by other packages.
+type beanKey struct {
+	beanID string
+}
+
+// RequestBean function returns Request-scoped bean from the context. The returned boolean is `false` if there's no such
+// bean in the context.
+func RequestBean(ctx context.Context, beanID string) (interface{}, bool) {
+	beanInstance := ctx.Value(beanKey{beanID: beanID})
+	return beanInstance, beanInstance != nil
+}
+
 // Middleware is a function that can be used with http routers to perform Request-scoped beans injection into the web
-// request context. If such bean implements io.Closer, it will be attempted to close upon corresponding context
-// cancellation (but may panic).
+// request context. Beans are created lazily: only upon the first lookup from the context. If such bean implements
+// io.Closer, it will be closed right after the request is handled (see SetRequestBeansClosePolicy).
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestContext := r.Context()
-		for beanID, scope := range scopes {
-			if scope != Request {
-				continue
-			}
-			beanInstance := getRequestBeanInstance(requestContext, beanID)
-			requestContext = context.WithValue(requestContext, BeanKey(beanID), beanInstance)
-			if isCloseable(beanInstance) {
-				go func(ctx context.Context, beanInstance interface{}) {
-					<-ctx.Done()
-					err := beanInstance.(io.Closer).Close()
-					if err != nil {
-						panic(err)
-					}
-				}(r.Context(), beanInstance)
-			}
-		}
-		next.ServeHTTP(w, r.WithContext(requestContext))
+		serveHTTP(next, w, r, newRequestScopeContext(r.Context()))
 	})
 }
 
+// MiddlewareFor function is similar to Middleware, but only the listed Request-scoped beans are made available in the
+// web request context.
+func MiddlewareFor(next http.Handler, beanIDs ...string) http.Handler {
+	if beanIDs == nil {
+		beanIDs = []string{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveHTTP(next, w, r, newRequestScopeContext(r.Context(), beanIDs...))
+	})
+}
+
+func serveHTTP(next http.Handler, w http.ResponseWriter, r *http.Request, requestContext *requestScopeContext) {
+	defer requestContext.scope.close()
+	r = r.WithContext(requestContext)
+	requestContext.scope.request = r
+	requestContext.scope.responseWriter = w
+	requestContext.scope.info = newRequestInfo(r.Header.Get(RequestIDHeader), r.RemoteAddr)
+	w.Header().Set(RequestIDHeader, requestContext.scope.info.ID)
+	next.ServeHTTP(w, r)
+}
+
+var httpRequestType = reflect.TypeOf((*http.Request)(nil))
+var responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+var requestInfoType = reflect.TypeOf((*RequestInfo)(nil))
+
+// scopeDependencyKind function checks if the field of the given type is injected with a dependency provided by the
+// scope itself (rather than by the container), i.e. the web request, the response writer, RequestInfo or CommandInfo.
+func scopeDependencyKind(fieldType reflect.Type) (injectionKind, bool) {
+	switch fieldType {
+	case httpRequestType:
+		return injectHTTPRequestKind, true
+	case responseWriterType:
+		return injectResponseWriterKind, true
+	case requestInfoType:
+		return injectRequestInfoKind, true
+	case commandInfoType:
+		return injectCommandInfoKind, true
+	}
+	return 0, false
+}
+
+// buildScopeDependencyStep function builds the injection of the dependency provided by the scope into the field tagged
+// with `di.inject:""`. Only Request-scoped beans can have such fields.
+func buildScopeDependencyStep(beanID string, field reflect.StructField, kind injectionKind) injectionStep {
+	step := injectionStep{kind: kind, field: field, reason: "the dependency is provided by the scope"}
+	if scopes[beanID] != Request {
+		step.err = errors.New("only request-scoped beans can be injected with " + field.Type.String() + ": " + beanID)
+		return step
+	}
+	onMissingDependency, err := getOnMissingPolicy(field)
+	if err != nil {
+		step.err = err
+		return step
+	}
+	step.optional = onMissingDependency == onMissingNil
+	return step
+}
+
+// injectScopeDependency function injects the dependency provided by the scope the bean is created in. The web request
+// and the response writer are only provided by Middleware, CommandInfo is only provided by WrapCommand: in other scopes
+// (e.g. created by BeginScope) the field is left nil for optional dependencies.
+func injectScopeDependency(ctx context.Context, beanID string, fieldToInject reflect.Value, step injectionStep) error {
+	var dependency interface{}
+	if requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext); ok {
+		scope := requestContext.scope
+		switch {
+		case step.kind == injectHTTPRequestKind && scope.request != nil:
+			dependency = scope.request
+		case step.kind == injectResponseWriterKind && scope.responseWriter != nil:
+			dependency = scope.responseWriter
+		case step.kind == injectRequestInfoKind && scope.info != nil:
+			dependency = scope.info
+		case step.kind == injectCommandInfoKind && scope.command != nil:
+			dependency = scope.command
+		}
+	}
+	if dependency == nil {
+		if step.optional {
+			return nil
+		}
+		if step.kind == injectRequestInfoKind {
+			return errors.New(step.field.Type.String() + " is not available in the scope of bean " + beanID)
+		}
+		if step.kind == injectCommandInfoKind {
+			return errors.New(step.field.Type.String() + " is not available in the scope of bean " + beanID +
+				": it's only set by WrapCommand")
+		}
+		return errors.New(step.field.Type.String() + " is not available in the scope of bean " + beanID +
+			": it's only set by Middleware")
+	}
+	fieldToInject.Set(reflect.ValueOf(dependency))
+	return nil
+}
+
 func isCloseable(beanInstance interface{}) bool {
 	_, ok := beanInstance.(io.Closer)
 	return ok
 }
+
+// FromContext function returns Request-scoped bean from the context, converted to `T`. The returned boolean is `false`
+// if there's no such bean in the context, or if it can't be converted to `T`.
+func FromContext[T any](ctx context.Context, beanID string) (T, bool) {
+	beanInstance, ok := ctx.Value(beanKey{beanID: beanID}).(T)
+	return beanInstance, ok
+}
+
+// FromContextSafe function returns Request-scoped bean from the context, converted to `T`. It doesn't panic, but
+// returns the error if there's no such bean in the context, if its creation fails, or if it can't be converted to `T`.
+func FromContextSafe[T any](ctx context.Context, beanID string) (T, error) {
+	var zero T
+	var beanInstance interface{}
+	if scope, ok := RequestScopeFromContext(ctx); ok && scope.context.scope.contains(resolveAlias(beanID)) {
+		var err error
+		if beanInstance, err = scope.Get(beanID); err != nil {
+			return zero, err
+		}
+	} else {
+		beanInstance = ctx.Value(beanKey{beanID: beanID})
+	}
+	if beanInstance == nil {
+		return zero, errors.New("request-scoped bean is not found in the context: " + beanID)
+	}
+	typedBeanInstance, ok := beanInstance.(T)
+	if !ok {
+		return zero, fmt.Errorf("request-scoped bean %s is of type %T, not %s", beanID, beanInstance,
+			reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typedBeanInstance, nil
+}
+
+// MustFromContext function returns Request-scoped bean from the context, converted to `T`. It panics if there's no such
+// bean in the context, or if it can't be converted to `T`, so if receiving the error in return is preferred, consider
+// using `FromContextSafe`.
+func MustFromContext[T any](ctx context.Context, beanID string) T {
+	beanInstance, err := FromContextSafe[T](ctx, beanID)
+	if err != nil {
+		panic(err)
+	}
+	return beanInstance
+}
@@ -16,9 +16,12 @@ package di
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -82,3 +85,293 @@ func (suite *TestSuite) TestMiddlewareNotInitialized() {
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), resp)
 }
+
+func (suite *TestSuite) TestFromContext() {
+	ctx := context.WithValue(context.Background(), beanKey{beanID: "requestBean"}, &requestBean{})
+	requestBeanInstance, ok := FromContext[*requestBean](ctx, "requestBean")
+	assert.True(suite.T(), ok)
+	assert.NotNil(suite.T(), requestBeanInstance)
+	_, ok = FromContext[*singletonBean](ctx, "requestBean")
+	assert.False(suite.T(), ok)
+	_, ok = FromContext[*requestBean](ctx, "otherBean")
+	assert.False(suite.T(), ok)
+	requestBeanInstance, err := FromContextSafe[*requestBean](ctx, "requestBean")
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), requestBeanInstance)
+	_, err = FromContextSafe[*singletonBean](ctx, "requestBean")
+	assert.Equal(suite.T(), errors.New("request-scoped bean requestBean is of type *di.requestBean, not *di.singletonBean"), err)
+	_, err = FromContextSafe[*requestBean](ctx, "otherBean")
+	assert.Equal(suite.T(), errors.New("request-scoped bean is not found in the context: otherBean"), err)
+	assert.Equal(suite.T(), requestBeanInstance, MustFromContext[*requestBean](ctx, "requestBean"))
+	assert.Panics(suite.T(), func() {
+		MustFromContext[*requestBean](ctx, "otherBean")
+	})
+}
+
+func (suite *TestSuite) TestFromContextSafeFailingRequestBean() {
+	overwritten, err := RegisterBeanFactory("failingBean", Request, func(context.Context) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	assert.NotPanics(suite.T(), func() {
+		_, err = FromContextSafe[*string](ctx, "failingBean")
+	})
+	assert.EqualError(suite.T(), err, "connection refused")
+	assert.Panics(suite.T(), func() {
+		MustFromContext[*string](ctx, "failingBean")
+	})
+}
+
+func (suite *TestSuite) TestMiddlewareLazyRequestBeans() {
+	var countOfCalls int32
+	overwritten, err := RegisterBeanFactory("lazyBean", Request, func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&countOfCalls, 1)
+		return new(string), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lazy" {
+			return
+		}
+		lazyBeanInstance1 := r.Context().Value(BeanKey("lazyBean"))
+		lazyBeanInstance2 := r.Context().Value(BeanKey("lazyBean"))
+		assert.NotNil(suite.T(), lazyBeanInstance1)
+		assert.True(suite.T(), lazyBeanInstance1 == lazyBeanInstance2)
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(suite.T(), int32(0), atomic.LoadInt32(&countOfCalls))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/lazy", nil))
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(&countOfCalls))
+}
+
+func (suite *TestSuite) TestMiddlewareFor() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("otherRequestBean", Request, func(context.Context) (interface{}, error) {
+		return new(string), nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := MiddlewareFor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(suite.T(), r.Context().Value(BeanKey("otherRequestBean")))
+		assert.Nil(suite.T(), r.Context().Value(BeanKey("requestBean")))
+	}), "otherRequestBean")
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	middleware = MiddlewareFor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(suite.T(), r.Context().Value(BeanKey("otherRequestBean")))
+		assert.Nil(suite.T(), r.Context().Value(BeanKey("requestBean")))
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+type orderedCloseableBean struct {
+	id     string
+	closed *[]string
+}
+
+func (ocb *orderedCloseableBean) Close() error {
+	*ocb.closed = append(*ocb.closed, ocb.id)
+	return nil
+}
+
+func (suite *TestSuite) TestMiddlewareClosesRequestBeansAfterRequest() {
+	var closedBeans []string
+	for _, beanID := range []string{"first", "second"} {
+		beanID := beanID
+		overwritten, err := RegisterBeanFactory(beanID, Request, func(context.Context) (interface{}, error) {
+			return &orderedCloseableBean{id: beanID, closed: &closedBeans}, nil
+		})
+		assert.False(suite.T(), overwritten)
+		assert.NoError(suite.T(), err)
+	}
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(suite.T(), r.Context().Value(BeanKey("first")))
+		assert.NotNil(suite.T(), r.Context().Value(BeanKey("second")))
+		assert.Empty(suite.T(), closedBeans)
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(suite.T(), []string{"second", "first"}, closedBeans)
+}
+
+type countingCloseableBean struct {
+	counter *int32
+}
+
+func (ccb *countingCloseableBean) Close() error {
+	atomic.AddInt32(ccb.counter, 1)
+	return nil
+}
+
+func (suite *TestSuite) TestMiddlewareClosesRequestBeansOnContextDone() {
+	var closedBeans int32
+	overwritten, err := RegisterBeanFactory("requestBean", Request, func(context.Context) (interface{}, error) {
+		return &countingCloseableBean{counter: &closedBeans}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	SetRequestBeansClosePolicy(CloseOnContextDone)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(suite.T(), r.Context().Value(BeanKey("requestBean")))
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	assert.Equal(suite.T(), int32(0), atomic.LoadInt32(&closedBeans))
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&closedBeans) == 1
+	}, time.Second, time.Millisecond)
+}
+
+type sharedRequestBean struct {
+	Scope Scope `di.scope:"request"`
+}
+
+type dependentRequestBean1 struct {
+	Scope  Scope              `di.scope:"request"`
+	Shared *sharedRequestBean `di.inject:"sharedRequestBean"`
+}
+
+type dependentRequestBean2 struct {
+	Scope  Scope              `di.scope:"request"`
+	Shared *sharedRequestBean `di.inject:""`
+}
+
+type circularRequestBean struct {
+	Scope    Scope                `di.scope:"request"`
+	Circular *circularRequestBean `di.inject:"circularRequestBean"`
+}
+
+func (suite *TestSuite) TestInjectRequestBeanIntoRequestBean() {
+	overwritten, err := RegisterBean("sharedRequestBean", reflect.TypeOf((*sharedRequestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("dependentRequestBean1", reflect.TypeOf((*dependentRequestBean1)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("dependentRequestBean2", reflect.TypeOf((*dependentRequestBean2)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var shared []*sharedRequestBean
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dependentRequestBean1Instance := MustFromContext[*dependentRequestBean1](r.Context(), "dependentRequestBean1")
+		dependentRequestBean2Instance := MustFromContext[*dependentRequestBean2](r.Context(), "dependentRequestBean2")
+		sharedRequestBeanInstance := MustFromContext[*sharedRequestBean](r.Context(), "sharedRequestBean")
+		assert.True(suite.T(), dependentRequestBean1Instance.Shared == sharedRequestBeanInstance)
+		assert.True(suite.T(), dependentRequestBean2Instance.Shared == sharedRequestBeanInstance)
+		shared = append(shared, sharedRequestBeanInstance)
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(suite.T(), shared, 2)
+	assert.False(suite.T(), shared[0] == shared[1])
+}
+
+func (suite *TestSuite) TestInjectRequestBeanIntoRequestBeanCircularDependency() {
+	overwritten, err := RegisterBean("circularRequestBean", reflect.TypeOf((*circularRequestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.PanicsWithError(suite.T(), "circular dependency detected for bean: circularRequestBean "+
+			"(circularRequestBean.Circular -> circularRequestBean)", func() {
+			r.Context().Value(BeanKey("circularRequestBean"))
+		})
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func (suite *TestSuite) TestRequestBeanKeysCantBeShadowed() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forged := &requestBean{}
+		ctx := context.WithValue(r.Context(), BeanKey("requestBean"), forged)
+		ctx = context.WithValue(ctx, "requestBean", forged)
+		beanInstance, ok := RequestBean(ctx, "requestBean")
+		assert.True(suite.T(), ok)
+		assert.NotSame(suite.T(), forged, beanInstance)
+		assert.Same(suite.T(), beanInstance, MustFromContext[*requestBean](ctx, "requestBean"))
+		_, ok = RequestBean(ctx, "unknownBean")
+		assert.False(suite.T(), ok)
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+type httpAwareRequestBean struct {
+	Scope          Scope               `di.scope:"request"`
+	request        *http.Request       `di.inject:""`
+	responseWriter http.ResponseWriter `di.inject:""`
+}
+
+type optionalHTTPAwareRequestBean struct {
+	Scope   Scope         `di.scope:"request"`
+	request *http.Request `di.inject:"" di.optional:"true"`
+}
+
+type httpAwareSingletonBean struct {
+	request *http.Request `di.inject:""`
+}
+
+func (suite *TestSuite) TestInjectHTTPRequestIntoRequestBean() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*httpAwareRequestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	recorder := httptest.NewRecorder()
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bean := MustFromContext[*httpAwareRequestBean](r.Context(), "requestBean")
+		assert.Same(suite.T(), r, bean.request)
+		assert.Equal(suite.T(), "value", bean.request.Header.Get("X-Header"))
+		bean.responseWriter.WriteHeader(http.StatusTeapot)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("X-Header", "value")
+	middleware.ServeHTTP(recorder, request)
+	assert.Equal(suite.T(), http.StatusTeapot, recorder.Code)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	scope, _ := RequestScopeFromContext(ctx)
+	_, err = scope.Get("requestBean")
+	assert.EqualError(suite.T(), err, "*http.Request is not available in the scope of bean requestBean: it's only set by Middleware")
+}
+
+func (suite *TestSuite) TestInjectOptionalHTTPRequestOutsideOfMiddleware() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*optionalHTTPAwareRequestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	assert.Nil(suite.T(), MustFromContext[*optionalHTTPAwareRequestBean](ctx, "requestBean").request)
+}
+
+func (suite *TestSuite) TestInjectHTTPRequestIntoSingleton() {
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*httpAwareSingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "only request-scoped beans can be injected with *http.Request: singletonBean")
+}
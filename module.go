@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Module is a named set of bean registrations, postprocessors and property sources, that lets compose the wiring per
+// package instead of one giant init function.
+type Module struct {
+	// Name of the module, used in diagnostics.
+	Name string
+	// Prefix is prepended to IDs of all beans registered by the module (as `prefix.beanID`), optional.
+	Prefix string
+	// Register function contributes registrations of the module.
+	Register func(registry *ModuleRegistry) error
+}
+
+// ModuleRegistry is passed to Module.Register function. It registers beans with the module-level ID prefix and reports
+// bean IDs that are already registered (by another module or outside of modules) as errors, instead of overwriting
+// them.
+type ModuleRegistry struct {
+	module Module
+}
+
+var beanModules = make(map[string]string)
+
+// RegisterModule function registers beans, postprocessors and property sources contributed by the module.
+func RegisterModule(module Module) error {
+	if module.Name == "" {
+		return errors.New("module name can't be empty")
+	}
+	if module.Register == nil {
+		return errors.New("module " + module.Name + " has no register function")
+	}
+	if err := module.Register(&ModuleRegistry{module: module}); err != nil {
+		return errors.New("can't register module " + module.Name + ": " + err.Error())
+	}
+	return nil
+}
+
+// ID method returns the bean ID with the module-level prefix: it's meant to be used for referencing beans of the module
+// (e.g. in `di.inject` tags or upon retrieval from the container).
+func (mr *ModuleRegistry) ID(beanID string) string {
+	if mr.module.Prefix == "" {
+		return beanID
+	}
+	return mr.module.Prefix + "." + beanID
+}
+
+// RegisterBean method registers the bean by type (see RegisterBean function).
+func (mr *ModuleRegistry) RegisterBean(beanID string, beanType reflect.Type) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	beanID, err := mr.checkDuplicate(beanID)
+	if err != nil {
+		return err
+	}
+	if _, err := registerBean(beanID, beanType, nil); err != nil {
+		return err
+	}
+	mr.trackBeanWithoutLock(beanID)
+	return nil
+}
+
+// RegisterBeanInstance method registers the bean instance (see RegisterBeanInstance function).
+func (mr *ModuleRegistry) RegisterBeanInstance(beanID string, beanInstance interface{}) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	beanID, err := mr.checkDuplicate(beanID)
+	if err != nil {
+		return err
+	}
+	if _, err := registerBeanInstance(beanID, beanInstance); err != nil {
+		return err
+	}
+	mr.trackBeanWithoutLock(beanID)
+	return nil
+}
+
+// RegisterBeanFactory method registers the bean factory (see RegisterBeanFactory function).
+func (mr *ModuleRegistry) RegisterBeanFactory(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, error)) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	beanID, err := mr.checkDuplicate(beanID)
+	if err != nil {
+		return err
+	}
+	if _, err := registerBeanFactory(beanID, beanScope, beanFactory); err != nil {
+		return err
+	}
+	mr.trackBeanWithoutLock(beanID)
+	return nil
+}
+
+// RegisterBeanPostprocessor method registers the postprocessor for beans (see RegisterBeanPostprocessor function).
+func (mr *ModuleRegistry) RegisterBeanPostprocessor(beanType reflect.Type, postprocessor func(bean interface{}) error) error {
+	return RegisterBeanPostprocessor(beanType, postprocessor)
+}
+
+// AddPropertySource method adds the property source (see AddPropertySource function).
+func (mr *ModuleRegistry) AddPropertySource(source PropertySource) error {
+	return AddPropertySource(source)
+}
+
+func (mr *ModuleRegistry) checkDuplicate(beanID string) (string, error) {
+	beanID = mr.ID(beanID)
+	if !isBeanRegistered(beanID) {
+		return beanID, nil
+	}
+	if module, ok := beanModules[beanID]; ok {
+		return beanID, errors.New("bean " + beanID + " is already registered by module " + module)
+	}
+	return beanID, errors.New("bean " + beanID + " is already registered outside of modules")
+}
+
+func (mr *ModuleRegistry) trackBeanWithoutLock(beanID string) {
+	if isBeanRegistered(beanID) {
+		beanModules[beanID] = mr.module.Name
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type billingService struct {
+	gateway *stubGateway `di.inject:"billing.gateway"`
+}
+
+func billingModule() Module {
+	return Module{
+		Name:   "billing",
+		Prefix: "billing",
+		Register: func(registry *ModuleRegistry) error {
+			if err := registry.RegisterBeanInstance("gateway", &stubGateway{name: "billing"}); err != nil {
+				return err
+			}
+			if err := registry.RegisterBean("service", reflect.TypeOf((*billingService)(nil))); err != nil {
+				return err
+			}
+			return registry.AddPropertySource(MapPropertySource{"billing.currency": "EUR"})
+		},
+	}
+}
+
+func (suite *TestSuite) TestRegisterModule() {
+	err := RegisterModule(billingModule())
+	assert.NoError(suite.T(), err)
+	err = RegisterModule(Module{
+		Name: "shipping",
+		Register: func(registry *ModuleRegistry) error {
+			assert.Equal(suite.T(), "gateway", registry.ID("gateway"))
+			return registry.RegisterBeanFactory("gateway", Singleton, func(context.Context) (interface{}, error) {
+				return &stubGateway{name: "shipping"}, nil
+			})
+		},
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "billing", GetInstance("billing.service").(*billingService).gateway.name)
+	assert.Equal(suite.T(), "shipping", GetInstance("gateway").(*stubGateway).name)
+	currency, ok := GetProperty("billing.currency")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "EUR", currency)
+}
+
+func (suite *TestSuite) TestRegisterModuleDuplicates() {
+	err := RegisterModule(billingModule())
+	assert.NoError(suite.T(), err)
+	err = RegisterModule(billingModule())
+	assert.EqualError(suite.T(), err, "can't register module billing: bean billing.gateway is already registered by module billing")
+	overwritten, err := RegisterBeanInstance("gateway", &stubGateway{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterModule(Module{
+		Name: "shipping",
+		Register: func(registry *ModuleRegistry) error {
+			return registry.RegisterBean("gateway", reflect.TypeOf((*stubGateway)(nil)))
+		},
+	})
+	assert.EqualError(suite.T(), err, "can't register module shipping: bean gateway is already registered outside of modules")
+}
+
+func (suite *TestSuite) TestRegisterModulesConcurrently() {
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = RegisterModule(Module{Name: "module", Register: func(registry *ModuleRegistry) error {
+				return registry.RegisterBeanInstance("gateway", &stubGateway{})
+			}})
+		}(i)
+	}
+	wg.Wait()
+	failures := 0
+	for _, err := range errs {
+		if err != nil {
+			failures++
+		}
+	}
+	assert.Equal(suite.T(), len(errs)-1, failures)
+}
+
+func (suite *TestSuite) TestRegisterModuleErrors() {
+	assert.Error(suite.T(), RegisterModule(Module{Register: func(*ModuleRegistry) error { return nil }}))
+	assert.Error(suite.T(), RegisterModule(Module{Name: "empty"}))
+	err := RegisterModule(Module{Name: "failing", Register: func(*ModuleRegistry) error { return errors.New("failure") }})
+	assert.EqualError(suite.T(), err, "can't register module failing: failure")
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// MustRegisterBean function is similar to RegisterBean, but panics on error. It's meant for the registration in main()
+// or init(), where the error can't be handled anyway.
+func MustRegisterBean(beanID string, beanType reflect.Type) (overwritten bool) {
+	overwritten, err := RegisterBean(beanID, beanType)
+	if err != nil {
+		panic(err)
+	}
+	return overwritten
+}
+
+// MustRegisterBeanInstance function is similar to RegisterBeanInstance, but panics on error.
+func MustRegisterBeanInstance(beanID string, beanInstance interface{}) (overwritten bool) {
+	overwritten, err := RegisterBeanInstance(beanID, beanInstance)
+	if err != nil {
+		panic(err)
+	}
+	return overwritten
+}
+
+// MustRegisterBeanFactory function is similar to RegisterBeanFactory, but panics on error.
+func MustRegisterBeanFactory(beanID string, beanScope Scope, beanFactory func(ctx context.Context) (interface{}, error)) (overwritten bool) {
+	overwritten, err := RegisterBeanFactory(beanID, beanScope, beanFactory)
+	if err != nil {
+		panic(err)
+	}
+	return overwritten
+}
+
+// MustInitializeContainer function is similar to InitializeContainer, but panics on error.
+func MustInitializeContainer() {
+	if err := InitializeContainer(); err != nil {
+		panic(err)
+	}
+}
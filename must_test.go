@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mustSingletonBean struct{}
+
+func (suite *TestSuite) TestMustRegister() {
+	assert.False(suite.T(), MustRegisterBean("singletonBean", reflect.TypeOf((*mustSingletonBean)(nil))))
+	assert.True(suite.T(), MustRegisterBean("singletonBean", reflect.TypeOf((*mustSingletonBean)(nil))))
+	assert.False(suite.T(), MustRegisterBeanInstance("instance", &resolvedRepository{}))
+	assert.False(suite.T(), MustRegisterBeanFactory("factory", Prototype, func(context.Context) (interface{}, error) {
+		return &resolvedRepository{}, nil
+	}))
+	MustInitializeContainer()
+	assert.IsType(suite.T(), &mustSingletonBean{}, GetInstance("singletonBean"))
+	assert.IsType(suite.T(), &resolvedRepository{}, GetInstance("instance"))
+	assert.IsType(suite.T(), &resolvedRepository{}, GetInstance("factory"))
+}
+
+func (suite *TestSuite) TestMustRegisterPanics() {
+	assert.PanicsWithError(suite.T(), "bean type must be a pointer", func() {
+		MustRegisterBean("singletonBean", reflect.TypeOf(mustSingletonBean{}))
+	})
+	assert.Panics(suite.T(), func() {
+		MustRegisterBeanInstance("instance", resolvedRepository{})
+	})
+	MustInitializeContainer()
+	assert.PanicsWithError(suite.T(), "container is already initialized: can't register new bean factory", func() {
+		MustRegisterBeanFactory("factory", Prototype, func(context.Context) (interface{}, error) {
+			return &resolvedRepository{}, nil
+		})
+	})
+	assert.PanicsWithError(suite.T(), "container is already initialized: reinitialization is not supported", func() {
+		MustInitializeContainer()
+	})
+}
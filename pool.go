@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PoolableBean is an interface that Pooled beans can implement to be reset before returning to the pool.
+type PoolableBean interface {
+	// Reset method is called on a bean before it's returned to the pool.
+	Reset()
+}
+
+var poolsLock sync.Mutex
+var pools = make(map[string]*sync.Pool)
+
+func getPool(beanID string) *sync.Pool {
+	poolsLock.Lock()
+	defer poolsLock.Unlock()
+	pool, ok := pools[beanID]
+	if !ok {
+		pool = &sync.Pool{}
+		pools[beanID] = pool
+	}
+	return pool
+}
+
+// getPooledInstance function borrows the instance of the Pooled bean from the pool (creating a new one if the pool is
+// empty). The instance is returned to the pool when the owning context ends: right after the web request (or other
+// unit of work, see BeginScope) is handled, or upon context cancellation. Instances borrowed with contexts that are
+// never canceled are not returned to the pool.
+func getPooledInstance(ctx context.Context, beanID string, chain *dependencyChain) (interface{}, error) {
+	pool := getPool(beanID)
+	instance := pool.Get()
+	if instance == nil {
+		var err error
+		instance, err = createBeanInstance(ctx, beanID, chain)
+		if err != nil {
+			return nil, err
+		}
+	} else if isTracing() {
+		logrus.WithField("beanID", beanID).Trace("instance borrowed from the pool")
+	}
+	release := func() {
+		if poolable, ok := instance.(PoolableBean); ok {
+			poolable.Reset()
+		}
+		pool.Put(instance)
+	}
+	if requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext); ok {
+		requestContext.scope.addRelease(release)
+	} else if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			release()
+		}()
+	}
+	return instance, nil
+}
+
+// checkPooledDependency function checks that the Pooled bean is injected into the bean with a shorter lifecycle.
+func checkPooledDependency(beanID string) error {
+	if scopes[beanID] == Singleton || scopes[beanID] == Refresh {
+		return errors.New("pooled beans can't be injected into singletons: they would never be returned to the pool")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pooledBufferResets int32
+
+type pooledBuffer struct {
+	Scope  Scope `di.scope:"pooled"`
+	buffer bytes.Buffer
+}
+
+func (pb *pooledBuffer) Reset() {
+	atomic.AddInt32(&pooledBufferResets, 1)
+	pb.buffer.Reset()
+}
+
+type pooledBufferConsumer struct {
+	Scope  Scope         `di.scope:"request"`
+	buffer *pooledBuffer `di.inject:""`
+}
+
+type singletonPooledBufferConsumer struct {
+	buffer *pooledBuffer `di.inject:""`
+}
+
+func (suite *TestSuite) TestPooledScope() {
+	atomic.StoreInt32(&pooledBufferResets, 0)
+	overwritten, err := RegisterBean("buffer", reflect.TypeOf((*pooledBuffer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*pooledBufferConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	consumer := MustFromContext[*pooledBufferConsumer](ctx, "consumer")
+	consumer.buffer.buffer.WriteString("dirty")
+	buffer := GetInstanceCtx(ctx, "buffer").(*pooledBuffer)
+	assert.NotSame(suite.T(), consumer.buffer, buffer)
+	assert.Equal(suite.T(), int32(0), atomic.LoadInt32(&pooledBufferResets))
+	done()
+	assert.Equal(suite.T(), int32(2), atomic.LoadInt32(&pooledBufferResets))
+	assert.Equal(suite.T(), 0, consumer.buffer.buffer.Len())
+	cancelableCtx, cancel := context.WithCancel(context.Background())
+	buffer = GetInstanceCtx(cancelableCtx, "buffer").(*pooledBuffer)
+	assert.Equal(suite.T(), 0, buffer.buffer.Len())
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&pooledBufferResets) == 3
+	}, time.Second, time.Millisecond)
+}
+
+func (suite *TestSuite) TestPooledBeanCantBeInjectedIntoSingleton() {
+	overwritten, err := RegisterBean("buffer", reflect.TypeOf((*pooledBuffer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*singletonPooledBufferConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+const (
+	// HighestPriority is a priority of postprocessors that are guaranteed to run first.
+	HighestPriority = math.MinInt32
+	// DefaultPriority is a priority of postprocessors registered without explicit priority.
+	DefaultPriority = 0
+	// LowestPriority is a priority of postprocessors that are guaranteed to run last.
+	LowestPriority = math.MaxInt32
+)
+
+// beanPostprocessor is a function postprocessing the bean and returning the instance to be used instead of it.
+type beanPostprocessor struct {
+	priority    int
+	postprocess func(beanID string, bean interface{}) (interface{}, error)
+}
+
+// RegisterBeanPostprocessorWithPriority function registers postprocessors for beans (see RegisterBeanPostprocessor)
+// with explicit priority: postprocessors with lower priority values run first, postprocessors with equal priorities
+// run in registration order.
+func RegisterBeanPostprocessorWithPriority(beanType reflect.Type, priority int, postprocessor func(bean interface{}) error) error {
+	return addBeanPostprocessor(beanType, priority, func(beanID string, bean interface{}) (interface{}, error) {
+		return bean, postprocessor(bean)
+	})
+}
+
+// anyBeanType is the key of global postprocessors, i.e. the ones applied to beans of any type.
+var anyBeanType reflect.Type
+
+// RegisterGlobalBeanPostprocessor function registers postprocessors for beans of any type, e.g. to inject a logger,
+// validate or instrument beans uniformly. Global postprocessors run along with the ones registered for the type of the
+// bean, ordered by priority; among postprocessors with equal priorities, global ones run first. Postprocessors are
+// registered with DefaultPriority and run in registration order.
+func RegisterGlobalBeanPostprocessor(postprocessor func(beanID string, bean interface{}) error) error {
+	return RegisterGlobalBeanPostprocessorWithPriority(DefaultPriority, postprocessor)
+}
+
+// RegisterGlobalBeanPostprocessorWithPriority function registers postprocessors for beans of any type (see
+// RegisterGlobalBeanPostprocessor) with explicit priority: postprocessors with lower priority values run first.
+func RegisterGlobalBeanPostprocessorWithPriority(priority int, postprocessor func(beanID string, bean interface{}) error) error {
+	return addBeanPostprocessor(anyBeanType, priority, func(beanID string, bean interface{}) (interface{}, error) {
+		return bean, postprocessor(beanID, bean)
+	})
+}
+
+// getBeanPostprocessors function returns postprocessors of the bean type, merged with the global ones by priority.
+func getBeanPostprocessors(beanType reflect.Type) []beanPostprocessor {
+	global, postprocessors := beanPostprocessors[anyBeanType], beanPostprocessors[beanType]
+	if len(global) == 0 {
+		return postprocessors
+	}
+	if len(postprocessors) == 0 {
+		return global
+	}
+	merged := make([]beanPostprocessor, 0, len(global)+len(postprocessors))
+	for len(global) > 0 && len(postprocessors) > 0 {
+		if global[0].priority <= postprocessors[0].priority {
+			merged, global = append(merged, global[0]), global[1:]
+		} else {
+			merged, postprocessors = append(merged, postprocessors[0]), postprocessors[1:]
+		}
+	}
+	return append(append(merged, global...), postprocessors...)
+}
+
+// RegisterBeanPostprocessorWithID function registers postprocessors for beans (see RegisterBeanPostprocessor), that
+// receive the ID of the bean along with its instance, so that beans of the same type (e.g. "primaryDB" and "replicaDB")
+// can be postprocessed differently. Postprocessors are registered with DefaultPriority and run in registration order.
+func RegisterBeanPostprocessorWithID(beanType reflect.Type, postprocessor func(beanID string, bean interface{}) error) error {
+	return RegisterBeanPostprocessorWithIDAndPriority(beanType, DefaultPriority, postprocessor)
+}
+
+// RegisterBeanPostprocessorWithIDAndPriority function registers postprocessors receiving the ID of the bean (see
+// RegisterBeanPostprocessorWithID) with explicit priority: postprocessors with lower priority values run first,
+// postprocessors with equal priorities run in registration order.
+func RegisterBeanPostprocessorWithIDAndPriority(beanType reflect.Type, priority int, postprocessor func(beanID string, bean interface{}) error) error {
+	return addBeanPostprocessor(beanType, priority, func(beanID string, bean interface{}) (interface{}, error) {
+		return bean, postprocessor(beanID, bean)
+	})
+}
+
+// RegisterReplacingBeanPostprocessorWithPriority function registers postprocessors that can replace the bean instance
+// (see RegisterReplacingBeanPostprocessor) with explicit priority: postprocessors with lower priority values run first,
+// postprocessors with equal priorities run in registration order.
+func RegisterReplacingBeanPostprocessorWithPriority(beanType reflect.Type, priority int, postprocessor func(beanID string, bean interface{}) (interface{}, error)) error {
+	return addBeanPostprocessor(beanType, priority, postprocessor)
+}
+
+func addBeanPostprocessor(beanType reflect.Type, priority int, postprocessor func(beanID string, bean interface{}) (interface{}, error)) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register bean postprocessor")
+	}
+	postprocessors := append(beanPostprocessors[beanType], beanPostprocessor{priority: priority, postprocess: postprocessor})
+	sort.SliceStable(postprocessors, func(i, j int) bool {
+		return postprocessors[i].priority < postprocessors[j].priority
+	})
+	beanPostprocessors[beanType] = postprocessors
+	return nil
+}
+
+// RegisterReplacingBeanPostprocessor function registers postprocessors for beans, that can replace the bean instance:
+// the instance returned by the postprocessor (e.g. a decorator or a proxy wrapping the bean) is stored in the container
+// and injected into other beans instead of the original one. Replacing instances should be assignable to the fields
+// the bean is injected into, so it makes sense to inject such beans by interfaces.
+func RegisterReplacingBeanPostprocessor(beanType reflect.Type, postprocessor func(beanID string, bean interface{}) (interface{}, error)) error {
+	return addBeanPostprocessor(beanType, DefaultPriority, postprocessor)
+}
+
+// replaceInjectedInstances function replaces singleton instances that were already injected into other singletons with
+// the instances returned by the postprocessors.
+func replaceInjectedInstances(instances map[string]interface{}, replacements map[interface{}]interface{}) error {
+	if len(replacements) == 0 {
+		return nil
+	}
+	for beanID, instance := range instances {
+		beanType, ok := beans[beanID]
+		if !ok || userCreatedInstances[beanID] || beanType.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		instanceValue := reflect.ValueOf(instance)
+		fields, err := injectableFields(beanType.Elem())
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			_, injected := inject.lookup(field)
+			_, groupInjected := injectGroup.lookup(field)
+			if !injected && !groupInjected {
+				continue
+			}
+			fieldValue, err := settableField(beanID, instanceValue.Elem(), field)
+			if err != nil {
+				return err
+			}
+			if err := replaceValue(beanID, field, fieldValue, replacements); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func replaceValue(beanID string, field reflect.StructField, fieldValue reflect.Value, replacements map[interface{}]interface{}) error {
+	switch fieldValue.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return replaceElement(beanID, field, fieldValue, replacements, func(replacement reflect.Value) { fieldValue.Set(replacement) })
+	case reflect.Slice:
+		for i := 0; i < fieldValue.Len(); i++ {
+			element := fieldValue.Index(i)
+			if err := replaceElement(beanID, field, element, replacements, func(replacement reflect.Value) { element.Set(replacement) }); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range fieldValue.MapKeys() {
+			key := key
+			err := replaceElement(beanID, field, fieldValue.MapIndex(key), replacements, func(replacement reflect.Value) {
+				fieldValue.SetMapIndex(key, replacement)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func replaceElement(beanID string, field reflect.StructField, element reflect.Value,
+	replacements map[interface{}]interface{}, set func(replacement reflect.Value)) error {
+	if !element.IsValid() {
+		return nil
+	}
+	// replaced instances are pointers or channels, while the field may already hold an unhashable value returned by a
+	// replacing postprocessor (e.g. a lazy bean), so other values are skipped before the lookup
+	instance := element.Interface()
+	if kind := reflect.ValueOf(instance).Kind(); kind != reflect.Ptr && kind != reflect.Chan {
+		return nil
+	}
+	replacement, ok := replacements[instance]
+	if !ok {
+		return nil
+	}
+	replacementValue := reflect.ValueOf(replacement)
+	if !replacementValue.Type().AssignableTo(element.Type()) {
+		return errors.New("replaced instance of type " + replacementValue.Type().String() + " can't be injected into field " +
+			field.Name + " of bean " + beanID)
+	}
+	set(replacementValue)
+	return nil
+}
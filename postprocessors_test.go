@@ -0,0 +1,265 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHandler struct {
+	handler
+	beanID string
+	calls  int
+}
+
+func (ch *countingHandler) Handle() string {
+	ch.calls++
+	return ch.handler.Handle()
+}
+
+type decoratedHandlersConsumer struct {
+	handler  handler            `di.inject:"userHandler"`
+	handlers []handler          `di.inject.group:"handlers"`
+	byID     map[string]handler `di.inject:""`
+	provider func() handler     `di.inject:"userHandler"`
+}
+
+type prototypeUserHandler struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+func (puh *prototypeUserHandler) Handle() string {
+	return "prototype"
+}
+
+func countingDecorator(beanID string, bean interface{}) (interface{}, error) {
+	return &countingHandler{handler: bean.(handler), beanID: beanID}, nil
+}
+
+func (suite *TestSuite) TestReplacingBeanPostprocessor() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("prototypeHandler", reflect.TypeOf((*prototypeUserHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*decoratedHandlersConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*userHandler)(nil)), countingDecorator)
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*prototypeUserHandler)(nil)), countingDecorator)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	decorated := GetInstance("userHandler").(*countingHandler)
+	assert.Equal(suite.T(), "userHandler", decorated.beanID)
+	consumer := GetInstance("consumer").(*decoratedHandlersConsumer)
+	assert.Same(suite.T(), decorated, consumer.handler)
+	assert.Same(suite.T(), decorated, consumer.handlers[0])
+	assert.Same(suite.T(), decorated, consumer.byID["userHandler"])
+	assert.Same(suite.T(), decorated, consumer.provider())
+	assert.Equal(suite.T(), "user", consumer.handler.Handle())
+	assert.Equal(suite.T(), 1, decorated.calls)
+	prototype := GetInstance("prototypeHandler").(*countingHandler)
+	assert.Equal(suite.T(), "prototype", prototype.Handle())
+}
+
+type userHandlerConsumer struct {
+	handler *userHandler `di.inject:""`
+}
+
+func (suite *TestSuite) TestReplacingBeanPostprocessorErrors() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*userHandlerConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*userHandler)(nil)), countingDecorator)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "replaced instance of type *di.countingHandler can't be injected into field handler of bean consumer")
+	Reset()
+	overwritten, err = RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*userHandler)(nil)), func(string, interface{}) (interface{}, error) {
+		return nil, errors.New("failure")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "failure")
+}
+
+type handlerChain []handler
+
+func (hc handlerChain) Handle() string {
+	result := ""
+	for _, h := range hc {
+		result += h.Handle()
+	}
+	return result
+}
+
+func (suite *TestSuite) TestReplacingBeanPostprocessorUnhashableInstances() {
+	_, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ConfigureBean("userHandler").Lazy().Err())
+	_, err = RegisterBean("debugHandler", reflect.TypeOf((*debugHandler)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*decoratedHandlersConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*userHandler)(nil)), func(beanID string, bean interface{}) (interface{}, error) {
+		return handlerChain{bean.(handler), bean.(handler)}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterReplacingBeanPostprocessor(reflect.TypeOf((*debugHandler)(nil)), countingDecorator)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "useruser", GetInstance("userHandler").(handler).Handle())
+	consumer := GetInstance("consumer").(*decoratedHandlersConsumer)
+	assert.Equal(suite.T(), "useruser", consumer.handler.Handle())
+}
+
+func (suite *TestSuite) TestPostprocessorsPriority() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	var order []string
+	beanType := reflect.TypeOf((*userHandler)(nil))
+	appendOrder := func(name string) func(bean interface{}) error {
+		return func(bean interface{}) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	assert.NoError(suite.T(), RegisterBeanPostprocessorWithPriority(beanType, LowestPriority, appendOrder("security")))
+	assert.NoError(suite.T(), RegisterBeanPostprocessor(beanType, appendOrder("default1")))
+	assert.NoError(suite.T(), RegisterBeanPostprocessorWithPriority(beanType, HighestPriority, appendOrder("first")))
+	assert.NoError(suite.T(), RegisterReplacingBeanPostprocessorWithPriority(beanType, 10,
+		func(beanID string, bean interface{}) (interface{}, error) {
+			order = append(order, "replacing")
+			return bean, nil
+		}))
+	assert.NoError(suite.T(), RegisterBeanPostprocessor(beanType, appendOrder("default2")))
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"first", "default1", "default2", "replacing", "security"}, order)
+	assert.Error(suite.T(), RegisterBeanPostprocessorWithPriority(beanType, HighestPriority, appendOrder("late")))
+}
+
+type postprocessedDataSource struct {
+	readOnly bool
+}
+
+func (suite *TestSuite) TestBeanPostprocessorWithID() {
+	beanType := reflect.TypeOf((*postprocessedDataSource)(nil))
+	for _, beanID := range []string{"primaryDB", "replicaDB"} {
+		overwritten, err := RegisterBean(beanID, beanType)
+		assert.False(suite.T(), overwritten)
+		assert.NoError(suite.T(), err)
+	}
+	var order []string
+	err := RegisterBeanPostprocessorWithID(beanType, func(beanID string, bean interface{}) error {
+		bean.(*postprocessedDataSource).readOnly = beanID == "replicaDB"
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanPostprocessor(beanType, func(bean interface{}) error {
+		order = append(order, "default")
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanPostprocessorWithIDAndPriority(beanType, HighestPriority, func(beanID string, bean interface{}) error {
+		order = append(order, "first")
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), GetInstance("primaryDB").(*postprocessedDataSource).readOnly)
+	assert.True(suite.T(), GetInstance("replicaDB").(*postprocessedDataSource).readOnly)
+	assert.Equal(suite.T(), []string{"first", "default", "first", "default"}, order)
+}
+
+func (suite *TestSuite) TestBeanPostprocessorWithIDError() {
+	overwritten, err := RegisterBean("replicaDB", reflect.TypeOf((*postprocessedDataSource)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanPostprocessorWithID(reflect.TypeOf((*postprocessedDataSource)(nil)),
+		func(beanID string, bean interface{}) error {
+			return errors.New("can't configure " + beanID)
+		})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "can't configure replicaDB")
+}
+
+type globallyPostprocessedBean struct {
+	Scope Scope `di.scope:"prototype"`
+	name  string
+}
+
+func (suite *TestSuite) TestGlobalBeanPostprocessor() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("prototypeBean", reflect.TypeOf((*globallyPostprocessedBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	var order []string
+	err = RegisterGlobalBeanPostprocessor(func(beanID string, bean interface{}) error {
+		order = append(order, "global:"+beanID)
+		if namedBean, ok := bean.(*globallyPostprocessedBean); ok {
+			namedBean.name = beanID
+		}
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanPostprocessor(reflect.TypeOf((*userHandler)(nil)), func(bean interface{}) error {
+		order = append(order, "typed")
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = RegisterGlobalBeanPostprocessorWithPriority(LowestPriority, func(beanID string, bean interface{}) error {
+		order = append(order, "last:"+beanID)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"global:userHandler", "typed", "last:userHandler"}, order)
+	order = nil
+	assert.Equal(suite.T(), "prototypeBean", GetInstance("prototypeBean").(*globallyPostprocessedBean).name)
+	assert.Equal(suite.T(), []string{"global:prototypeBean", "last:prototypeBean"}, order)
+	assert.Error(suite.T(), RegisterGlobalBeanPostprocessor(func(string, interface{}) error { return nil }))
+}
+
+func (suite *TestSuite) TestGlobalBeanPostprocessorError() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterGlobalBeanPostprocessor(func(beanID string, bean interface{}) error {
+		return errors.New("invalid bean: " + beanID)
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "invalid bean: userHandler")
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	// ActiveProfilesEnv is an environment variable with a comma-separated list of profiles that are active by default.
+	ActiveProfilesEnv = "DI_ACTIVE_PROFILES"
+	// DefaultProfile is a profile that is active when no other profiles are activated.
+	DefaultProfile = "default"
+)
+
+var activeProfiles = getDefaultActiveProfiles()
+
+// SetActiveProfiles function activates the given profiles (replacing the ones from DI_ACTIVE_PROFILES environment
+// variable). Beans can be bound to profiles with a tag `di.profile:"dev,test"` (a bean is registered if any of the
+// listed profiles is active) or `di.profile:"!prod"` (a bean is registered if the profile is not active). Since
+// profiles are checked upon registration, this function should be called before the beans are registered.
+func SetActiveProfiles(profiles ...string) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	activeProfiles = make(map[string]bool)
+	for _, profile := range profiles {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			activeProfiles[profile] = true
+		}
+	}
+}
+
+// GetActiveProfiles function returns a list of currently active profiles.
+func GetActiveProfiles() []string {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	profiles := make([]string, 0, len(activeProfiles))
+	for profile := range activeProfiles {
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+func getDefaultActiveProfiles() map[string]bool {
+	profiles := make(map[string]bool)
+	for _, profile := range strings.Split(os.Getenv(ActiveProfilesEnv), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles[profile] = true
+		}
+	}
+	return profiles
+}
+
+func isProfileActive(bean reflect.Type) bool {
+	if bean.Kind() != reflect.Ptr {
+		return true
+	}
+	beanElement := bean.Elem()
+	if beanElement.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < beanElement.NumField(); i++ {
+		if beanProfiles, ok := profile.lookup(beanElement.Field(i)); ok {
+			return matchProfiles(beanProfiles)
+		}
+	}
+	return true
+}
+
+func matchProfiles(beanProfiles string) bool {
+	for _, beanProfile := range strings.Split(beanProfiles, ",") {
+		beanProfile = strings.TrimSpace(beanProfile)
+		if negatedProfile := strings.TrimPrefix(beanProfile, "!"); negatedProfile != beanProfile {
+			if !isActiveProfile(negatedProfile) {
+				return true
+			}
+		} else if isActiveProfile(beanProfile) {
+			return true
+		}
+	}
+	return false
+}
+
+func isActiveProfile(profile string) bool {
+	if len(activeProfiles) == 0 {
+		return profile == DefaultProfile
+	}
+	return activeProfiles[profile]
+}
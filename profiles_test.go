@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type devEmailSender struct {
+	Profile struct{} `di.profile:"dev,test"`
+}
+
+func (*devEmailSender) Send(to string) string {
+	return "dev: " + to
+}
+
+type prodEmailSender struct {
+	Profile struct{} `di.profile:"prod"`
+}
+
+func (*prodEmailSender) Send(to string) string {
+	return "prod: " + to
+}
+
+type nonDevEmailSender struct {
+	Profile struct{} `di.profile:"!dev"`
+}
+
+func (*nonDevEmailSender) Send(to string) string {
+	return "non-dev: " + to
+}
+
+type defaultEmailSender struct {
+	Profile struct{} `di.profile:"default"`
+}
+
+func (*defaultEmailSender) Send(to string) string {
+	return "default: " + to
+}
+
+func (suite *TestSuite) registerProfiledEmailSenders() {
+	for _, beanType := range []reflect.Type{
+		reflect.TypeOf((*devEmailSender)(nil)),
+		reflect.TypeOf((*prodEmailSender)(nil)),
+		reflect.TypeOf((*defaultEmailSender)(nil)),
+	} {
+		_, err := RegisterBean("emailSender", beanType)
+		assert.NoError(suite.T(), err)
+	}
+}
+
+func (suite *TestSuite) TestActiveProfile() {
+	SetOverwritePolicy(OverwriteError)
+	SetActiveProfiles("test", "")
+	assert.Equal(suite.T(), []string{"test"}, GetActiveProfiles())
+	suite.registerProfiledEmailSenders()
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "dev: john", GetInstance("emailSender").(emailSender).Send("john"))
+}
+
+func (suite *TestSuite) TestNegatedProfile() {
+	SetOverwritePolicy(OverwriteError)
+	SetActiveProfiles("staging")
+	for _, beanType := range []reflect.Type{
+		reflect.TypeOf((*devEmailSender)(nil)),
+		reflect.TypeOf((*nonDevEmailSender)(nil)),
+	} {
+		_, err := RegisterBean("emailSender", beanType)
+		assert.NoError(suite.T(), err)
+	}
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "non-dev: john", GetInstance("emailSender").(emailSender).Send("john"))
+}
+
+func (suite *TestSuite) TestDefaultProfile() {
+	SetActiveProfiles()
+	for _, beanType := range []reflect.Type{
+		reflect.TypeOf((*devEmailSender)(nil)),
+		reflect.TypeOf((*defaultEmailSender)(nil)),
+	} {
+		_, err := RegisterBean("emailSender", beanType)
+		assert.NoError(suite.T(), err)
+	}
+	overwritten, err := RegisterBeanInstance("devEmailSender", &devEmailSender{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "default: john", GetInstance("emailSender").(emailSender).Send("john"))
+	_, err = GetInstanceSafe("devEmailSender")
+	assert.Error(suite.T(), err)
+}
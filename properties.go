@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PropertySource is a source of properties used to resolve `${name:default}` placeholders of the `di.value` tags.
+type PropertySource interface {
+	// Property method returns the value of the property and `true`, or `false` if the source doesn't contain it.
+	Property(name string) (string, bool)
+}
+
+// EnvPropertySource is a PropertySource backed by environment variables. It's the only property source used by default.
+type EnvPropertySource struct {
+}
+
+// Property method returns the value of the environment variable with such name. If there's no such variable, the name
+// is converted to the environment variable naming convention (`database.url` becomes `DATABASE_URL`) and looked up
+// again.
+func (EnvPropertySource) Property(name string) (string, bool) {
+	if property, ok := os.LookupEnv(name); ok {
+		return property, true
+	}
+	return os.LookupEnv(strings.ToUpper(envNameReplacer.Replace(name)))
+}
+
+var envNameReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// MapPropertySource is a PropertySource backed by a map.
+type MapPropertySource map[string]string
+
+// Property method returns the value from the map.
+func (mps MapPropertySource) Property(name string) (string, bool) {
+	property, ok := mps[name]
+	return property, ok
+}
+
+var propertySources = []PropertySource{EnvPropertySource{}}
+
+// propertySourcesLock guards the chain of property sources: it's replaced under the initializeShutdownLock, but read
+// without it (e.g. by Refresh-scoped beans or GetProperty callers).
+var propertySourcesLock sync.RWMutex
+
+func setPropertySources(sources []PropertySource) {
+	propertySourcesLock.Lock()
+	defer propertySourcesLock.Unlock()
+	propertySources = sources
+}
+
+// SetPropertySources function replaces the chain of property sources used to resolve `di.value` placeholders. The
+// sources are queried in the given order, the first one containing the property wins.
+func SetPropertySources(sources ...PropertySource) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't set property sources")
+	}
+	setPropertySources(append([]PropertySource(nil), sources...))
+	return nil
+}
+
+// GetProperty function returns the value of the property from the chain of property sources.
+func GetProperty(name string) (string, bool) {
+	propertySourcesLock.RLock()
+	sources := propertySources
+	propertySourcesLock.RUnlock()
+	for _, source := range sources {
+		if property, ok := source.Property(name); ok {
+			return property, true
+		}
+	}
+	return "", false
+}
+
+// resolvePlaceholders function replaces all `${name}` and `${name:default}` placeholders in the expression with the
+// values of the corresponding properties. If some property is not found and the placeholder has no default, the whole
+// expression is resolved to `defaultValue`, unless it's nil.
+func resolvePlaceholders(expression string, defaultValue *string) (string, error) {
+	var resolved strings.Builder
+	for {
+		start := strings.Index(expression, "${")
+		if start < 0 {
+			resolved.WriteString(expression)
+			return resolved.String(), nil
+		}
+		end := strings.Index(expression[start:], "}")
+		if end < 0 {
+			return "", errors.New("unclosed placeholder in di.value: " + expression)
+		}
+		end += start
+		resolved.WriteString(expression[:start])
+		name, placeholderDefault, hasDefault := strings.Cut(expression[start+2:end], ":")
+		property, ok := GetProperty(name)
+		switch {
+		case ok:
+			resolved.WriteString(property)
+		case hasDefault:
+			resolved.WriteString(placeholderDefault)
+		default:
+			if defaultValue != nil {
+				return *defaultValue, nil
+			}
+			return "", errors.New("property is not found: " + name)
+		}
+		expression = expression[end+1:]
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func isValueKindSupported(fieldType reflect.Type) bool {
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// injectValue function injects the value of the expression into the field. If some property of the expression is not
+// found, the literal from the `di.default` tag of the field is injected instead, if there's one.
+func injectValue(beanID string, fieldToInject reflect.Value, field reflect.StructField, valueExpression string) error {
+	var defaultValue *string
+	if tagValue, ok := defaultBean.lookup(field); ok {
+		defaultValue = &tagValue
+	}
+	resolvedValue, err := resolvePlaceholders(valueExpression, defaultValue)
+	if err != nil {
+		return err
+	}
+	if isTracing() {
+		logrus.WithFields(logrus.Fields{
+			"bean":  beanID,
+			"field": field.Name,
+		}).Trace("injecting value")
+	}
+	if err := setValue(fieldToInject, resolvedValue); err != nil {
+		return errors.New("can't inject value into field " + field.Name + " of bean " + beanID + ": " + err.Error())
+	}
+	return nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsedValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsedValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsedValue, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsedValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsedValue, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsedValue)
+	case reflect.Float32, reflect.Float64:
+		parsedValue, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsedValue)
+	default:
+		return errors.New(unsupportedValueType)
+	}
+	return nil
+}
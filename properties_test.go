@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type configBean struct {
+	Scope       Scope         `di.scope:"prototype"`
+	databaseURL string        `di.value:"jdbc:${DI_TEST_DATABASE_HOST:localhost}:${DI_TEST_DATABASE_PORT:5432}"`
+	debug       bool          `di.value:"${debug}"`
+	port        uint16        `di.value:"${port:8080}"`
+	ratio       float64       `di.value:"${ratio:0.5}"`
+	timeout     time.Duration `di.value:"${timeout:5s}"`
+	retries     int           `di.value:"3"`
+}
+
+func (suite *TestSuite) TestValueInjection() {
+	suite.T().Setenv("DI_TEST_DATABASE_HOST", "db")
+	err := SetPropertySources(EnvPropertySource{}, MapPropertySource{"debug": "true", "port": "9090"})
+	assert.NoError(suite.T(), err)
+	overwritten, err := RegisterBean("configBean", reflect.TypeOf((*configBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	instance := GetInstance("configBean").(*configBean)
+	assert.Equal(suite.T(), "jdbc:db:5432", instance.databaseURL)
+	assert.True(suite.T(), instance.debug)
+	assert.Equal(suite.T(), uint16(9090), instance.port)
+	assert.Equal(suite.T(), 0.5, instance.ratio)
+	assert.Equal(suite.T(), 5*time.Second, instance.timeout)
+	assert.Equal(suite.T(), 3, instance.retries)
+	err = SetPropertySources()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestValueInjectionPropertyNotFound() {
+	_ = os.Unsetenv("debug")
+	overwritten, err := RegisterBean("configBean", reflect.TypeOf((*configBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("configBean")
+	assert.Equal(suite.T(), errors.New("property is not found: debug"), err)
+}
+
+func (suite *TestSuite) TestValueInjectionInvalidValue() {
+	type SingletonBean struct {
+		port int `di.value:"${port}"`
+	}
+	err := SetPropertySources(MapPropertySource{"port": "http"})
+	assert.NoError(suite.T(), err)
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err,
+		"can't inject value into field port of bean singletonBean: strconv.ParseInt: parsing \"http\": invalid syntax")
+}
+
+func (suite *TestSuite) TestValueInjectionUnsupportedType() {
+	type SingletonBean struct {
+		ports []int `di.value:"${ports}"`
+	}
+	overwritten, err := RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.Equal(suite.T(), errors.New(unsupportedValueType), err)
+}
+
+func (suite *TestSuite) TestValueInjectionDefault() {
+	type SingletonBean struct {
+		port    int    `di.value:"${port}" di.default:"8080"`
+		host    string `di.value:"${host}:${port}" di.default:"localhost"`
+		timeout string `di.value:"${timeout:5s}" di.default:"10s"`
+	}
+	err := SetPropertySources(MapPropertySource{"host": "example.com"})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("singletonBean", reflect.TypeOf((*SingletonBean)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	instance := GetInstance("singletonBean").(*SingletonBean)
+	assert.Equal(suite.T(), 8080, instance.port)
+	assert.Equal(suite.T(), "localhost", instance.host)
+	assert.Equal(suite.T(), "5s", instance.timeout)
+}
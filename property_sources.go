@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// AddPropertySource function adds the property source to the end of the chain used to resolve `di.value` placeholders,
+// i.e. with the lowest precedence. Since environment variables are the first source of the chain by default, they
+// override the properties of the added sources (e.g. `DATABASE_URL` overrides `database.url`).
+func AddPropertySource(source PropertySource) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't add property source")
+	}
+	setPropertySources(append(propertySources[:len(propertySources):len(propertySources)], source))
+	return nil
+}
+
+// LoadYAMLPropertySource function reads the YAML file into the property source. Nested keys are flattened with dots
+// (`database.url`), list elements are addressed by index (`servers.0`).
+func LoadYAMLPropertySource(path string) (MapPropertySource, error) {
+	return loadPropertySource(path, yaml.Unmarshal)
+}
+
+// LoadJSONPropertySource function reads the JSON file into the property source. Nested keys are flattened with dots
+// (`database.url`), array elements are addressed by index (`servers.0`).
+func LoadJSONPropertySource(path string) (MapPropertySource, error) {
+	return loadPropertySource(path, unmarshalJSON)
+}
+
+// unmarshalJSON function keeps numbers as they are written in the file, so that large integers aren't converted to
+// floats (and then formatted in the exponent notation).
+func unmarshalJSON(content []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after the top-level JSON value")
+	}
+	return nil
+}
+
+// LoadTOMLPropertySource function reads the TOML file into the property source. Nested keys are flattened with dots
+// (`database.url`), array elements are addressed by index (`servers.0`).
+func LoadTOMLPropertySource(path string) (MapPropertySource, error) {
+	return loadPropertySource(path, toml.Unmarshal)
+}
+
+func loadPropertySource(path string, unmarshal func([]byte, interface{}) error) (MapPropertySource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var properties map[string]interface{}
+	if err := unmarshal(content, &properties); err != nil {
+		return nil, errors.New("can't parse property source " + path + ": " + err.Error())
+	}
+	source := make(MapPropertySource)
+	flattenProperties(source, "", properties)
+	return source, nil
+}
+
+func flattenProperties(source MapPropertySource, prefix string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenProperties(source, joinPropertyName(prefix, key), value[key])
+		}
+	case map[interface{}]interface{}:
+		for key, nestedValue := range value {
+			flattenProperties(source, joinPropertyName(prefix, fmt.Sprint(key)), nestedValue)
+		}
+	case []interface{}:
+		for i, nestedValue := range value {
+			flattenProperties(source, joinPropertyName(prefix, strconv.Itoa(i)), nestedValue)
+		}
+	case []map[string]interface{}:
+		for i, nestedValue := range value {
+			flattenProperties(source, joinPropertyName(prefix, strconv.Itoa(i)), nestedValue)
+		}
+	case nil:
+		source[prefix] = ""
+	case float64:
+		source[prefix] = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		source[prefix] = fmt.Sprint(value)
+	}
+}
+
+func joinPropertyName(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
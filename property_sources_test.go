@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type databaseConfigBean struct {
+	url     string `di.value:"${database.url}"`
+	pool    int    `di.value:"${database.pool:1}"`
+	replica string `di.value:"${database.replicas.1}"`
+}
+
+func (suite *TestSuite) writePropertiesFile(name string, content string) string {
+	path := filepath.Join(suite.T().TempDir(), name)
+	assert.NoError(suite.T(), os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (suite *TestSuite) TestFilePropertySources() {
+	yamlSource, err := LoadYAMLPropertySource(suite.writePropertiesFile("application.yaml", `
+database:
+  url: postgres://yaml
+  replicas:
+    - replica0
+    - replica1
+`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MapPropertySource{
+		"database.url":        "postgres://yaml",
+		"database.replicas.0": "replica0",
+		"database.replicas.1": "replica1",
+	}, yamlSource)
+	jsonSource, err := LoadJSONPropertySource(suite.writePropertiesFile("application.json",
+		`{"database": {"url": "postgres://json", "pool": 10}}`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MapPropertySource{"database.url": "postgres://json", "database.pool": "10"}, jsonSource)
+	tomlSource, err := LoadTOMLPropertySource(suite.writePropertiesFile("application.toml", `
+[database]
+url = "postgres://toml"
+pool = 5
+`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MapPropertySource{"database.url": "postgres://toml", "database.pool": "5"}, tomlSource)
+	for _, source := range []PropertySource{yamlSource, jsonSource, tomlSource} {
+		assert.NoError(suite.T(), AddPropertySource(source))
+	}
+	suite.T().Setenv("DATABASE_REPLICAS_1", "replicaFromEnv")
+	overwritten, err := RegisterBean("databaseConfigBean", reflect.TypeOf((*databaseConfigBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	instance := GetInstance("databaseConfigBean").(*databaseConfigBean)
+	assert.Equal(suite.T(), "postgres://yaml", instance.url)
+	assert.Equal(suite.T(), 10, instance.pool)
+	assert.Equal(suite.T(), "replicaFromEnv", instance.replica)
+	assert.Error(suite.T(), AddPropertySource(MapPropertySource{}))
+}
+
+func (suite *TestSuite) TestJSONPropertySourceNumbers() {
+	source, err := LoadJSONPropertySource(suite.writePropertiesFile("application.json",
+		`{"upload": {"maxSize": 10485760, "ratio": 0.75, "id": 9007199254740993}}`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MapPropertySource{
+		"upload.maxSize": "10485760",
+		"upload.ratio":   "0.75",
+		"upload.id":      "9007199254740993",
+	}, source)
+}
+
+func (suite *TestSuite) TestFilePropertySourceErrors() {
+	_, err := LoadYAMLPropertySource(filepath.Join(suite.T().TempDir(), "missing.yaml"))
+	assert.Error(suite.T(), err)
+	_, err = LoadJSONPropertySource(suite.writePropertiesFile("application.json", `{"database": `))
+	assert.Error(suite.T(), err)
+}
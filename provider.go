@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Provider is a lazy resolver of the bean: a field of type `di.Provider[T]` (or just `func() T`) tagged with
+// `di.inject` is satisfied with a closure that retrieves the bean from the container upon each call, instead of the
+// bean instance. It breaks construction-time cycles and lets singletons obtain fresh Prototype instances on demand.
+// The closure panics if the bean can't be retrieved.
+type Provider[T any] func() T
+
+// Get method retrieves the bean from the container.
+func (p Provider[T]) Get() T {
+	return p()
+}
+
+// isProviderType function checks whether the type is a provider: a function without arguments returning a reference.
+func isProviderType(fieldType reflect.Type) bool {
+	if fieldType.Kind() != reflect.Func || fieldType.NumIn() != 0 || fieldType.NumOut() != 1 {
+		return false
+	}
+	return fieldType.Out(0).Kind() == reflect.Ptr || fieldType.Out(0).Kind() == reflect.Interface
+}
+
+// newProvider function creates a provider of the given type, resolving the dependency of the bean.
+func newProvider(ctx context.Context, beanID string, providerType reflect.Type, dependencyID string) (reflect.Value, error) {
+	if beanType, ok := getBeanType(dependencyID); ok && !beanType.AssignableTo(providerType.Out(0)) {
+		return reflect.Value{}, errors.New("bean " + dependencyID + " of type " + beanType.String() +
+			" can't be provided by " + providerType.String())
+	}
+	return reflect.MakeFunc(providerType, func([]reflect.Value) []reflect.Value {
+		var instance interface{}
+		var err error
+		chain := acquireChain()
+		defer releaseChain(chain)
+		if scopes[dependencyID] == Refresh {
+			instance, err = getInstance(ctx, dependencyID, chain)
+		} else {
+			instance, err = getDependencyInstance(ctx, beanID, dependencyID, chain)
+		}
+		if err != nil {
+			panic(err)
+		}
+		result := reflect.New(providerType.Out(0)).Elem()
+		if !isNilBean(instance) {
+			result.Set(reflect.ValueOf(instance))
+		}
+		return []reflect.Value{result}
+	}), nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type prototypeCounter struct {
+	Scope Scope `di.scope:"prototype"`
+	value int
+}
+
+type providerConsumer struct {
+	counters Provider[*prototypeCounter] `di.inject:""`
+	handler  func() handler              `di.inject:"userHandler"`
+	missing  func() *stubGateway         `di.inject:"" di.optional:"true"`
+}
+
+type cyclicProviderBeanA struct {
+	b func() *cyclicProviderBeanB `di.inject:"b"`
+}
+
+type cyclicProviderBeanB struct {
+	a *cyclicProviderBeanA `di.inject:"a"`
+}
+
+func (suite *TestSuite) TestProviderInjection() {
+	overwritten, err := RegisterBean("counter", reflect.TypeOf((*prototypeCounter)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*providerConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*providerConsumer)
+	first := consumer.counters.Get()
+	first.value = 1
+	assert.NotSame(suite.T(), first, consumer.counters())
+	assert.Equal(suite.T(), 0, consumer.counters().value)
+	assert.Same(suite.T(), GetInstance("userHandler"), consumer.handler())
+	assert.Nil(suite.T(), consumer.missing)
+}
+
+func (suite *TestSuite) TestProviderBreaksCycle() {
+	overwritten, err := RegisterBean("a", reflect.TypeOf((*cyclicProviderBeanA)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("b", reflect.TypeOf((*cyclicProviderBeanB)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	a := GetInstance("a").(*cyclicProviderBeanA)
+	assert.Same(suite.T(), a, a.b().a)
+}
+
+type wrongProviderConsumer struct {
+	handler func() *adminHandler `di.inject:"userHandler"`
+}
+
+type mismatchedFuncConsumer struct {
+	handler func(string) handler `di.inject:"userHandler"`
+}
+
+func (suite *TestSuite) TestProviderErrors() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*wrongProviderConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestFunctionFieldOfMismatchedBean() {
+	overwritten, err := RegisterBean("userHandler", reflect.TypeOf((*userHandler)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*mismatchedFuncConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean userHandler can't be injected into field handler of type func(string) di.handler")
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// refreshScope holds Refresh-scoped beans. Beans are created lazily, upon the first lookup, and are dropped upon
+// RefreshScope call.
+type refreshScope struct {
+	lock    sync.Mutex
+	entries map[string]*requestScopeEntry
+	// beanIDs keeps IDs of the beans in creation order.
+	beanIDs []string
+}
+
+var refreshBeans = &refreshScope{entries: make(map[string]*requestScopeEntry)}
+
+// Refreshable is a stable proxy to the Refresh-scoped bean: it can be injected into the beans of any scope instead of
+// the bean itself and always returns the bean instance created after the latest RefreshScope call:
+//
+//	type service struct {
+//		config *di.Refreshable[*Config] `di.inject:"config"`
+//	}
+type Refreshable[T any] struct {
+	beanID string
+}
+
+// Get method returns the current instance of the bean.
+func (r *Refreshable[T]) Get() T {
+	return GetInstance(r.beanID).(T)
+}
+
+// BeanID method returns ID of the bean behind the proxy.
+func (r *Refreshable[T]) BeanID() string {
+	return r.beanID
+}
+
+func (r *Refreshable[T]) setBeanID(beanID string) {
+	r.beanID = beanID
+}
+
+func (r *Refreshable[T]) beanType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (r *Refreshable[T]) checkScope(beanScope Scope) error {
+	if beanScope == Request {
+		return errors.New(requestScopedBeansCantBeInjected)
+	}
+	return nil
+}
+
+// beanProxy is implemented by the types that are injected instead of the beans, referring to them by ID (such as
+// Refreshable or Factory).
+type beanProxy interface {
+	setBeanID(beanID string)
+	beanType() reflect.Type
+	checkScope(beanScope Scope) error
+}
+
+var beanProxyType = reflect.TypeOf((*beanProxy)(nil)).Elem()
+
+// isBeanProxy function checks whether the field is a bean proxy.
+func isBeanProxy(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Ptr && fieldType.Implements(beanProxyType)
+}
+
+// newBeanProxy function creates a bean proxy of the given type for the bean.
+func newBeanProxy(fieldType reflect.Type, beanID string) (reflect.Value, error) {
+	proxy := reflect.New(fieldType.Elem())
+	if err := proxy.Interface().(beanProxy).checkScope(scopes[beanID]); err != nil {
+		return reflect.Value{}, err
+	}
+	proxyBeanType := proxy.Interface().(beanProxy).beanType()
+	if beanType, ok := getBeanType(beanID); ok && !beanType.AssignableTo(proxyBeanType) {
+		return reflect.Value{}, errors.New("bean " + beanID + " of type " + beanType.String() +
+			" can't be referred to by " + fieldType.String())
+	}
+	proxy.Interface().(beanProxy).setBeanID(beanID)
+	return proxy, nil
+}
+
+// RefreshScope function drops all instances of Refresh-scoped beans, so that they are re-created upon the next lookup
+// (e.g. with the updated configuration). Dropped instances implementing io.Closer are closed in reverse creation order.
+func RefreshScope() {
+	refreshBeans.refresh()
+}
+
+func (rs *refreshScope) getInstance(beanID string, create func() (interface{}, error)) (interface{}, error) {
+	rs.lock.Lock()
+	entry, ok := rs.entries[beanID]
+	if !ok {
+		entry = &requestScopeEntry{}
+		rs.entries[beanID] = entry
+		rs.beanIDs = append(rs.beanIDs, beanID)
+	}
+	rs.lock.Unlock()
+	entry.once.Do(func() {
+		entry.beanInstance, entry.err = create()
+	})
+	if entry.err != nil {
+		rs.lock.Lock()
+		if rs.entries[beanID] == entry {
+			delete(rs.entries, beanID)
+			rs.untrack(beanID)
+		}
+		rs.lock.Unlock()
+	}
+	return entry.beanInstance, entry.err
+}
+
+// untrack method removes the bean ID from the creation order, so that the bean that failed to be created is tracked
+// only once when it's retried. Should be called under the lock.
+func (rs *refreshScope) untrack(beanID string) {
+	for i := len(rs.beanIDs) - 1; i >= 0; i-- {
+		if rs.beanIDs[i] == beanID {
+			rs.beanIDs = append(rs.beanIDs[:i], rs.beanIDs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (rs *refreshScope) refresh() {
+	rs.lock.Lock()
+	entries := rs.entries
+	beanIDs := rs.beanIDs
+	rs.entries = make(map[string]*requestScopeEntry)
+	rs.beanIDs = nil
+	rs.lock.Unlock()
+	logrus.Trace("refreshing refresh-scoped beans")
+	for i := len(beanIDs) - 1; i >= 0; i-- {
+		entry, ok := entries[beanIDs[i]]
+		if !ok {
+			continue
+		}
+		if closer, ok := entry.beanInstance.(io.Closer); ok {
+			err := closer.Close()
+			if err != nil {
+				logrus.WithField("beanID", beanIDs[i]).Error(err)
+			}
+			emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: beanIDs[i], Bean: entry.beanInstance, Err: err})
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialsBean struct {
+	Scope    Scope  `di.scope:"refresh"`
+	password string `di.value:"${db.password}"`
+	closed   bool
+}
+
+func (cb *credentialsBean) Close() error {
+	cb.closed = true
+	return nil
+}
+
+type credentialsConsumer struct {
+	credentials *Refreshable[*credentialsBean] `di.inject:""`
+}
+
+type staleCredentialsConsumer struct {
+	credentials *credentialsBean `di.inject:""`
+}
+
+func (suite *TestSuite) TestRefreshScope() {
+	properties := MapPropertySource{"db.password": "secret"}
+	assert.NoError(suite.T(), SetPropertySources(properties))
+	overwritten, err := RegisterBean("credentials", reflect.TypeOf((*credentialsBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*credentialsConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*credentialsConsumer)
+	assert.Equal(suite.T(), "credentials", consumer.credentials.BeanID())
+	credentials := consumer.credentials.Get()
+	assert.Equal(suite.T(), "secret", credentials.password)
+	assert.Same(suite.T(), credentials, GetInstance("credentials"))
+	properties["db.password"] = "rotated"
+	RefreshScope()
+	assert.True(suite.T(), credentials.closed)
+	assert.Equal(suite.T(), "rotated", consumer.credentials.Get().password)
+	assert.NotSame(suite.T(), credentials, consumer.credentials.Get())
+}
+
+func (suite *TestSuite) TestRefreshScopedBeanCantBeInjectedIntoSingleton() {
+	assert.NoError(suite.T(), SetPropertySources(MapPropertySource{"db.password": "secret"}))
+	overwritten, err := RegisterBean("credentials", reflect.TypeOf((*credentialsBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*staleCredentialsConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+}
+
+type countingCloser struct {
+	closes int
+}
+
+func (cc *countingCloser) Close() error {
+	cc.closes++
+	return nil
+}
+
+func (suite *TestSuite) TestRefreshScopeRetryAfterFailure() {
+	instance := &countingCloser{}
+	attempts := 0
+	overwritten, err := RegisterBeanFactory("flaky", Refresh, func(context.Context) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("not ready")
+		}
+		return instance, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("flaky")
+	assert.Error(suite.T(), err)
+	assert.Same(suite.T(), instance, GetInstance("flaky"))
+	RefreshScope()
+	assert.Equal(suite.T(), 1, instance.closes)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// ReleaseInstance function releases the instance of the Prototype bean the caller is done with, so that prototypes get
+// the same teardown as the beans owned by the container: the instance is closed if it implements io.Closer, the cleanup
+// function returned by its factory is called (see RegisterBeanFactoryWithCleanup), and it's reported as released by
+// GetInstanceStats. The error of closing the instance is returned. Prototype beans created within a scope (see
+// Middleware and BeginScope) are released along with it, while Singleton beans are released by Close.
+func ReleaseInstance(beanInstance interface{}) error {
+	if isNilBean(beanInstance) {
+		return errors.New("can't release nil instance")
+	}
+	if !reflect.TypeOf(beanInstance).Comparable() {
+		return errors.New("can't release instance of type " + reflect.TypeOf(beanInstance).String())
+	}
+	initializeShutdownLock.Lock()
+	for beanID, singleton := range singletonInstances {
+		if singleton == beanInstance {
+			initializeShutdownLock.Unlock()
+			return errors.New("singleton beans can't be released, they're closed by Close: " + beanID)
+		}
+	}
+	beanID := findPrototypeID(reflect.TypeOf(beanInstance))
+	initializeShutdownLock.Unlock()
+	cleanups := takeContainerCleanups(beanInstance)
+	if len(cleanups) > 0 {
+		beanID = cleanups[0].beanID
+	}
+	tracked, isTracked := takeUnscopedInstance(beanInstance)
+	if isTracked {
+		beanID = tracked.beanID
+	}
+	var err error
+	if closer, ok := beanInstance.(io.Closer); ok {
+		err = closer.Close()
+		emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: beanID, Bean: beanInstance, Err: err})
+	}
+	runCleanups(cleanups)
+	if isTracked {
+		tracked.release()
+	}
+	return err
+}
+
+// findPrototypeID function returns the ID of the only Prototype bean of the given type, or an empty string if there's
+// no such bean or there are many of them.
+func findPrototypeID(beanType reflect.Type) string {
+	found := ""
+	for beanID, beanScope := range scopes {
+		if beanScope != Prototype {
+			continue
+		}
+		if prototypeType, ok := getBeanType(beanID); ok && prototypeType == beanType {
+			if found != "" {
+				return ""
+			}
+			found = beanID
+		}
+	}
+	return found
+}
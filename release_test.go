@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type releasablePrototype struct {
+	Scope  Scope `di.scope:"prototype"`
+	closed int
+	err    error
+}
+
+func (rp *releasablePrototype) Close() error {
+	rp.closed++
+	return rp.err
+}
+
+func (suite *TestSuite) TestReleaseInstance() {
+	_, err := RegisterBean("prototype", reflect.TypeOf((*releasablePrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	var events []ContainerEvent
+	err = RegisterContainerHook(func(event ContainerEvent) {
+		if event.Type == BeanClosed {
+			events = append(events, event)
+		}
+	})
+	assert.NoError(suite.T(), err)
+	SetInstanceTracking(true)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	released := GetInstance("prototype").(*releasablePrototype)
+	kept := GetInstance("prototype").(*releasablePrototype)
+	assert.NoError(suite.T(), ReleaseInstance(released))
+	assert.Equal(suite.T(), 1, released.closed)
+	assert.Equal(suite.T(), 0, kept.closed)
+	if assert.Len(suite.T(), events, 1) {
+		assert.Equal(suite.T(), "prototype", events[0].BeanID)
+		assert.Same(suite.T(), released, events[0].Bean)
+	}
+	stats := GetInstanceStats()
+	if assert.Len(suite.T(), stats, 1) {
+		assert.Equal(suite.T(), 2, stats[0].Created)
+		assert.Equal(suite.T(), 1, stats[0].Released)
+		assert.Len(suite.T(), stats[0].Live, 1)
+	}
+	kept.err = errors.New("already closed")
+	assert.EqualError(suite.T(), ReleaseInstance(kept), "already closed")
+	assert.Equal(suite.T(), 1, kept.closed)
+	assert.Equal(suite.T(), 2, GetInstanceStats()[0].Released)
+}
+
+func (suite *TestSuite) TestReleaseInstanceRunsCleanup() {
+	var cleanups []string
+	_, err := RegisterBeanFactoryWithCleanup("prototype", Prototype,
+		func(context.Context) (interface{}, func(), error) {
+			bean := &releasablePrototype{}
+			return bean, func() {
+				cleanups = append(cleanups, "prototype")
+			}, nil
+		})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	released := GetInstance("prototype").(*releasablePrototype)
+	GetInstance("prototype")
+	assert.NoError(suite.T(), ReleaseInstance(released))
+	assert.Equal(suite.T(), 1, released.closed)
+	assert.Equal(suite.T(), []string{"prototype"}, cleanups)
+	Close()
+	assert.Equal(suite.T(), 1, released.closed)
+	assert.Equal(suite.T(), []string{"prototype", "prototype"}, cleanups)
+}
+
+func (suite *TestSuite) TestReleaseInstanceErrors() {
+	singleton := &singletonBean{}
+	_, err := RegisterBeanInstance("singleton", singleton)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.EqualError(suite.T(), ReleaseInstance(singleton),
+		"singleton beans can't be released, they're closed by Close: singleton")
+	assert.EqualError(suite.T(), ReleaseInstance(nil), "can't release nil instance")
+	assert.EqualError(suite.T(), ReleaseInstance((*releasablePrototype)(nil)), "can't release nil instance")
+	assert.EqualError(suite.T(), ReleaseInstance(func() {}), "can't release instance of type func()")
+}
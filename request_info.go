@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// RequestIDHeader is the header of the web request carrying the request ID. If it's present, Middleware uses its value
+// as the ID of RequestInfo, otherwise the ID is generated. Either way, the ID is also set to the response header.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestInfo holds the correlation data of a unit of work (web request, message, job, etc.). It's available in every
+// scope created by Middleware or BeginScope: Request-scoped beans can have it injected by type (`di.inject:""`), other
+// code can retrieve it with RequestInfoFromContext.
+type RequestInfo struct {
+	// ID of the request: taken from the RequestIDHeader or generated.
+	ID string
+	// StartTime is the time the scope is created at.
+	StartTime time.Time
+	// RemoteAddr is the network address of the client, it's empty for scopes created outside of HTTP.
+	RemoteAddr string
+}
+
+// RequestInfoFromContext function returns RequestInfo of the scope the passed context belongs to, or `false` if it
+// doesn't belong to any.
+func RequestInfoFromContext(ctx context.Context) (*RequestInfo, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext)
+	if !ok || requestContext.scope.info == nil {
+		return nil, false
+	}
+	return requestContext.scope.info, true
+}
+
+func newRequestInfo(requestID string, remoteAddr string) *RequestInfo {
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	return &RequestInfo{ID: requestID, StartTime: time.Now(), RemoteAddr: remoteAddr}
+}
+
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestInfoAwareBean struct {
+	Scope Scope        `di.scope:"request"`
+	info  *RequestInfo `di.inject:""`
+}
+
+func (suite *TestSuite) TestRequestInfo() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestInfoAwareBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var requestIDs []string
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := RequestInfoFromContext(r.Context())
+		assert.True(suite.T(), ok)
+		assert.Same(suite.T(), info, MustFromContext[*requestInfoAwareBean](r.Context(), "requestBean").info)
+		assert.Equal(suite.T(), r.RemoteAddr, info.RemoteAddr)
+		assert.False(suite.T(), info.StartTime.IsZero())
+		requestIDs = append(requestIDs, info.ID)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "request-id")
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, request)
+	assert.Equal(suite.T(), "request-id", recorder.Header().Get(RequestIDHeader))
+	recorder = httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(suite.T(), requestIDs, 3)
+	assert.Equal(suite.T(), "request-id", requestIDs[0])
+	assert.Len(suite.T(), requestIDs[1], 32)
+	assert.Equal(suite.T(), requestIDs[1], recorder.Header().Get(RequestIDHeader))
+	assert.NotEqual(suite.T(), requestIDs[1], requestIDs[2])
+}
+
+func (suite *TestSuite) TestRequestInfoInScope() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestInfoAwareBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	info, ok := RequestInfoFromContext(ctx)
+	assert.True(suite.T(), ok)
+	assert.NotEmpty(suite.T(), info.ID)
+	assert.Empty(suite.T(), info.RemoteAddr)
+	assert.Same(suite.T(), info, MustFromContext[*requestInfoAwareBean](ctx, "requestBean").info)
+	_, ok = RequestInfoFromContext(context.Background())
+	assert.False(suite.T(), ok)
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestBeansClosePolicy defines when Request-scoped beans implementing io.Closer are closed.
+type RequestBeansClosePolicy int32
+
+const (
+	// CloseAfterRequest policy closes Request-scoped beans synchronously (in reverse creation order) right after the
+	// request handler returns. Errors returned by io.Closer are logged. This is the default policy.
+	CloseAfterRequest RequestBeansClosePolicy = iota
+	// CloseOnContextDone policy closes every Request-scoped bean in a separate goroutine upon request context
+	// cancellation. Errors returned by io.Closer cause panic.
+	CloseOnContextDone
+)
+
+var requestBeansClosePolicy int32
+
+// SetRequestBeansClosePolicy function sets the policy defining when Request-scoped beans implementing io.Closer are
+// closed.
+func SetRequestBeansClosePolicy(policy RequestBeansClosePolicy) {
+	atomic.StoreInt32(&requestBeansClosePolicy, int32(policy))
+}
+
+// requestScope holds Request-scoped beans of a single web request. Beans are created lazily, upon the first lookup.
+type requestScope struct {
+	lock    sync.Mutex
+	ctx     context.Context
+	entries map[string]*requestScopeEntry
+	// beanIDs restricts the set of Request-scoped beans available in the scope, nil means all of them are available.
+	beanIDs map[string]bool
+	// closeables keep IDs of created io.Closer beans in creation order.
+	closeables *closeableBeans
+	// cleanups keep cleanup functions returned by factories of the beans created in the scope, in creation order.
+	cleanups []beanCleanup
+	// releases return Pooled beans borrowed in the scope to their pools.
+	releases []func()
+	// request and responseWriter are set if the scope is created for a web request.
+	request        *http.Request
+	responseWriter http.ResponseWriter
+	// info is set for scopes of units of work (i.e. created by Middleware or BeginScope).
+	info *RequestInfo
+	// command is set if the scope is created for a CLI command (i.e. by WrapCommand).
+	command *CommandInfo
+}
+
+type requestScopeEntry struct {
+	once         sync.Once
+	beanInstance interface{}
+	err          error
+}
+
+// requestScopeContext is a context that resolves bean keys of Request-scoped beans from the requestScope.
+type requestScopeContext struct {
+	context.Context
+	scope *requestScope
+	view  *RequestScope
+}
+
+func newRequestScopeContext(ctx context.Context, beanIDs ...string) *requestScopeContext {
+	scope := &requestScope{entries: make(map[string]*requestScopeEntry)}
+	if beanIDs != nil {
+		scope.beanIDs = make(map[string]bool, len(beanIDs))
+		for _, beanID := range beanIDs {
+			scope.beanIDs[beanID] = true
+		}
+	}
+	requestContext := &requestScopeContext{Context: ctx, scope: scope}
+	requestContext.view = &RequestScope{context: requestContext}
+	scope.ctx = requestContext
+	return requestContext
+}
+
+// Value method materializes Request-scoped beans on demand, all other keys are looked up in the parent context.
+func (rsc *requestScopeContext) Value(key interface{}) interface{} {
+	switch key := key.(type) {
+	case beanKey:
+		if beanID := resolveAlias(key.beanID); rsc.scope.contains(beanID) {
+			return getRequestBeanInstance(rsc, beanID)
+		}
+	case BeanKey:
+		if beanID := resolveAlias(string(key)); rsc.scope.contains(beanID) {
+			return getRequestBeanInstance(rsc, beanID)
+		}
+	case requestContextKey:
+		return rsc
+	}
+	return rsc.Context.Value(key)
+}
+
+func (rs *requestScope) contains(beanID string) bool {
+	if scopes[beanID] != Request {
+		return false
+	}
+	return rs.beanIDs == nil || rs.beanIDs[beanID]
+}
+
+func (rs *requestScope) getInstance(beanID string, chain *dependencyChain) (interface{}, error) {
+	if chain.contains(beanID) {
+		return nil, chain.circularDependencyError(beanID)
+	}
+	rs.lock.Lock()
+	entry, ok := rs.entries[beanID]
+	if !ok {
+		entry = &requestScopeEntry{}
+		rs.entries[beanID] = entry
+	}
+	rs.lock.Unlock()
+	entry.once.Do(func() {
+		entry.beanInstance, entry.err = getInstance(rs.ctx, beanID, chain)
+		if entry.err != nil || !isCloseable(entry.beanInstance) {
+			return
+		}
+		if RequestBeansClosePolicy(atomic.LoadInt32(&requestBeansClosePolicy)) == CloseAfterRequest {
+			rs.addCloseable(beanID)
+		} else {
+			go func(ctx context.Context, beanID string, beanInstance interface{}) {
+				<-ctx.Done()
+				err := beanInstance.(io.Closer).Close()
+				if err != nil {
+					panic(err)
+				}
+				emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: beanID, Bean: beanInstance})
+			}(rs.ctx, beanID, entry.beanInstance)
+		}
+	})
+	return entry.beanInstance, entry.err
+}
+
+// addCleanup method registers the cleanup function of the bean created in the scope, according to the close policy.
+func (rs *requestScope) addCleanup(cleanup beanCleanup) {
+	if RequestBeansClosePolicy(atomic.LoadInt32(&requestBeansClosePolicy)) == CloseAfterRequest {
+		rs.lock.Lock()
+		rs.cleanups = append(rs.cleanups, cleanup)
+		rs.lock.Unlock()
+		return
+	}
+	go func(ctx context.Context) {
+		<-ctx.Done()
+		cleanup.run()
+	}(rs.ctx)
+}
+
+func (rs *requestScope) addRelease(release func()) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.releases = append(rs.releases, release)
+}
+
+// addCloseable method registers the created io.Closer bean, to be closed along with the scope.
+func (rs *requestScope) addCloseable(beanID string) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	if rs.closeables == nil {
+		rs.closeables = &closeableBeans{}
+		runtime.SetFinalizer(rs.closeables, reportLeakedBeans)
+	}
+	rs.closeables.beanIDs = append(rs.closeables.beanIDs, beanID)
+}
+
+// close method closes created io.Closer beans and calls cleanup functions of the beans created in the scope in reverse
+// creation order, and returns borrowed Pooled beans to their pools.
+func (rs *requestScope) close() {
+	rs.lock.Lock()
+	var closeableBeanIDs []string
+	var closeableInstances []interface{}
+	if rs.closeables != nil {
+		closeableBeanIDs = rs.closeables.beanIDs
+		rs.closeables.beanIDs = nil
+		closeableInstances = make([]interface{}, len(closeableBeanIDs))
+		for i, beanID := range closeableBeanIDs {
+			closeableInstances[i] = rs.entries[beanID].beanInstance
+		}
+	}
+	cleanups := rs.cleanups
+	rs.cleanups = nil
+	releases := rs.releases
+	rs.releases = nil
+	rs.lock.Unlock()
+	defer func() {
+		runCleanups(cleanups)
+		for _, release := range releases {
+			release()
+		}
+	}()
+	for i := len(closeableBeanIDs) - 1; i >= 0; i-- {
+		beanID, beanInstance := closeableBeanIDs[i], closeableInstances[i]
+		err := beanInstance.(io.Closer).Close()
+		if err != nil {
+			logrus.WithField("beanID", beanID).Error(err)
+		}
+		emitContainerEvent(ContainerEvent{Type: BeanClosed, BeanID: beanID, Bean: beanInstance, Err: err})
+	}
+}
+
+// RequestScope is a view of the container for a single unit of work (web request, message, job, etc.). It's stored in
+// the context as a single value (see RequestScopeFromContext) and gives handlers one entry point to all beans, while
+// Request-scoped beans are still created lazily, upon the first lookup.
+type RequestScope struct {
+	context *requestScopeContext
+}
+
+// RequestScopeFromContext function returns the scope that the passed context belongs to (see Middleware and
+// BeginScope), or `false` if it doesn't belong to any.
+func RequestScopeFromContext(ctx context.Context) (*RequestScope, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	requestContext, ok := ctx.Value(requestContextKey{}).(*requestScopeContext)
+	if !ok {
+		return nil, false
+	}
+	return requestContext.view, true
+}
+
+// Get method returns bean instance by its ID. Request-scoped beans are created once per scope, all other beans are
+// retrieved from the container with the scope context (see GetInstanceSafeCtx).
+func (rs *RequestScope) Get(beanID string) (interface{}, error) {
+	if atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+		return nil, errors.New("container is not initialized: can't lookup instances of beans yet")
+	}
+	beanID = resolveAlias(beanID)
+	if scopes[beanID] != Request {
+		return GetInstanceSafeCtx(rs.context, beanID)
+	}
+	if !rs.context.scope.contains(beanID) {
+		return nil, errors.New("request-scoped bean is not available in the scope: " + beanID)
+	}
+	chain := acquireChain()
+	defer releaseChain(chain)
+	return rs.context.scope.getInstance(beanID, chain)
+}
+
+// Context method returns the context of the scope.
+func (rs *RequestScope) Context() context.Context {
+	return rs.context
+}
+
+// Close method closes Request-scoped beans of the scope implementing io.Closer (see SetRequestBeansClosePolicy), calls
+// cleanup functions of the beans created in the scope and returns borrowed Pooled beans to their pools. Middleware and
+// BeginScope close their scopes automatically, calling it more than once has no effect.
+func (rs *RequestScope) Close() {
+	rs.context.scope.close()
+}
+
+// GetTyped function returns bean instance from the scope, converted to `T`. It doesn't panic, but returns the error if
+// the bean can't be retrieved or converted to `T`.
+func GetTyped[T any](scope *RequestScope, beanID string) (T, error) {
+	var zero T
+	beanInstance, err := scope.Get(beanID)
+	if err != nil {
+		return zero, err
+	}
+	typedBeanInstance, ok := beanInstance.(T)
+	if !ok {
+		return zero, fmt.Errorf("bean %s is of type %T, not %s", beanID, beanInstance, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typedBeanInstance, nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestSuite) TestRequestScope() {
+	closed = false
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("singletonBean", reflect.TypeOf((*singletonBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := RequestScopeFromContext(r.Context())
+		assert.True(suite.T(), ok)
+		assert.Same(suite.T(), r.Context(), scope.Context())
+		bean, err := GetTyped[*requestBean](scope, "requestBean")
+		assert.NoError(suite.T(), err)
+		assert.Same(suite.T(), r.Context(), bean.ctx)
+		sameBean, err := scope.Get("requestBean")
+		assert.NoError(suite.T(), err)
+		assert.Same(suite.T(), bean, sameBean)
+		assert.Same(suite.T(), bean, r.Context().Value(BeanKey("requestBean")))
+		singleton, err := GetTyped[*singletonBean](scope, "singletonBean")
+		assert.NoError(suite.T(), err)
+		assert.Same(suite.T(), GetInstance("singletonBean"), singleton)
+		_, err = GetTyped[*singletonBean](scope, "requestBean")
+		assert.EqualError(suite.T(), err, "bean requestBean is of type *di.requestBean, not *di.singletonBean")
+		_, err = scope.Get("unknownBean")
+		assert.EqualError(suite.T(), err, "bean is not registered: unknownBean")
+		assert.False(suite.T(), closed)
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(suite.T(), closed)
+}
+
+func (suite *TestSuite) TestRequestScopeClose() {
+	closed = false
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	defer done()
+	scope, ok := RequestScopeFromContext(ctx)
+	assert.True(suite.T(), ok)
+	_, err = scope.Get("requestBean")
+	assert.NoError(suite.T(), err)
+	scope.Close()
+	assert.True(suite.T(), closed)
+	closed = false
+	scope.Close()
+	assert.False(suite.T(), closed)
+}
+
+func (suite *TestSuite) TestRequestScopeCloseWhileCreatingBeans() {
+	for i := 0; i < 20; i++ {
+		overwritten, err := RegisterBeanFactory("closeable"+strconv.Itoa(i), Request,
+			func(context.Context) (interface{}, error) {
+				return &countingCloser{}, nil
+			})
+		assert.False(suite.T(), overwritten)
+		assert.NoError(suite.T(), err)
+	}
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	ctx, done := BeginScope(context.Background())
+	scope, _ := RequestScopeFromContext(ctx)
+	first, err := scope.Get("closeable0")
+	assert.NoError(suite.T(), err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < 20; i++ {
+			_, _ = scope.Get("closeable" + strconv.Itoa(i))
+		}
+	}()
+	done()
+	wg.Wait()
+	assert.Equal(suite.T(), 1, first.(*countingCloser).closes)
+}
+
+func (suite *TestSuite) TestRequestScopeRestrictedBeans() {
+	overwritten, err := RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	middleware := MiddlewareFor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := RequestScopeFromContext(r.Context())
+		assert.True(suite.T(), ok)
+		_, err := scope.Get("requestBean")
+		assert.EqualError(suite.T(), err, "request-scoped bean is not available in the scope: requestBean")
+	}))
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func (suite *TestSuite) TestRequestScopeFromContextWithoutScope() {
+	_, ok := RequestScopeFromContext(context.Background())
+	assert.False(suite.T(), ok)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Resolver resolves dependencies of beans created by factories registered with RegisterResolvingBeanFactory.
+type Resolver interface {
+	// Resolve method returns the instance of the bean with the given ID (or alias).
+	Resolve(beanID string) (interface{}, error)
+}
+
+var resolvingBeanFactories = make(map[string]func(context.Context, Resolver) (interface{}, error))
+
+// RegisterResolvingBeanFactory function registers bean, provided the bean factory that receives the Resolver of its
+// dependencies along with the context. Unlike calling GetInstanceSafe from a regular factory, resolving dependencies
+// works during the container initialization too (i.e. for Singleton beans), with the same scope and circular
+// dependencies checks as for the regular injection. Note that Singleton dependencies resolved during the initialization
+// may not be injected and initialized yet: the factory should keep references to them, not call them. Return value of
+// `overwritten` is set to `true` if the bean with the same `beanID` has been registered already.
+func RegisterResolvingBeanFactory(beanID string, beanScope Scope, beanFactory func(ctx context.Context, deps Resolver) (interface{}, error)) (overwritten bool, err error) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean factory")
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{})
+	if err != nil {
+		return false, err
+	}
+	unregisterBean(beanID)
+	scopes[beanID] = beanScope
+	// the plain factory is used when the bean is created outside of the container, e.g. by the bean definitions API
+	beanFactories[beanID] = func(ctx context.Context) (interface{}, error) {
+		return callResolvingBeanFactory(ctx, beanID, newDependencyChain(beanID), beanFactory)
+	}
+	resolvingBeanFactories[beanID] = beanFactory
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
+}
+
+// Resolve function resolves the dependency with the given ID, converted to `T`.
+func Resolve[T any](deps Resolver, beanID string) (T, error) {
+	var zero T
+	beanInstance, err := deps.Resolve(beanID)
+	if err != nil {
+		return zero, err
+	}
+	typedBeanInstance, ok := beanInstance.(T)
+	if !ok {
+		return zero, fmt.Errorf("bean %s is of type %T, not %s", beanID, beanInstance, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typedBeanInstance, nil
+}
+
+// factoryResolver resolves dependencies of the bean being created by the factory, in the chain of beans being created.
+type factoryResolver struct {
+	ctx    context.Context
+	beanID string
+	chain  *dependencyChain
+	// lateBindings are bindings of dependencies resolved with ResolveLate.
+	lateBindings []func() error
+}
+
+// lateBindings are bindings of dependencies of Singleton beans created by factories upon the container initialization,
+// they're performed once all singletons are created (see ResolveLate).
+var lateBindings []func() error
+
+// ResolveLate function resolves the dependency with the given ID, converted to `T`, and passes it to `bind` once it's
+// available. This allows circular dependencies between Singleton beans created by factories (which can't be resolved
+// with Resolve, since none of the beans can be created first): dependencies of Singleton beans are bound upon the
+// container initialization once all singletons are created (but before they are initialized), dependencies of other
+// beans are bound right after the factory returns.
+func ResolveLate[T any](deps Resolver, beanID string, bind func(T)) error {
+	resolver, ok := deps.(*factoryResolver)
+	if !ok {
+		return errors.New("late binding is only supported by the resolver passed to the bean factory")
+	}
+	resolver.lateBindings = append(resolver.lateBindings, func() error {
+		dependency, err := Resolve[T](&factoryResolver{ctx: resolver.ctx, beanID: resolver.beanID,
+			chain: newDependencyChain(resolver.beanID)}, beanID)
+		if err != nil {
+			return err
+		}
+		bind(dependency)
+		return nil
+	})
+	return nil
+}
+
+func (fr *factoryResolver) Resolve(dependencyID string) (interface{}, error) {
+	dependencyID = resolveAlias(dependencyID)
+	if !isBeanRegistered(dependencyID) {
+		return nil, errors.New("no dependency found: " + dependencyID)
+	}
+	if isEagerSingleton(dependencyID) && atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+		if _, created := singletonInstances[dependencyID]; !created {
+			if err := createSingletonFactoryInstance(dependencyID, fr.chain); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return getDependencyInstance(fr.ctx, fr.beanID, dependencyID, fr.chain)
+}
+
+// callBeanFactory function calls the factory of the bean, passing the resolver to the resolving factories.
+func callBeanFactory(ctx context.Context, beanID string, chain *dependencyChain) (beanInstance interface{}, err error) {
+	defer recoverBeanPanic(beanID, "factory", &err)
+	if resolvingBeanFactory, ok := resolvingBeanFactories[beanID]; ok {
+		return callResolvingBeanFactory(ctx, beanID, chain, resolvingBeanFactory)
+	}
+	return beanFactories[beanID](ctx)
+}
+
+// callResolvingBeanFactory function calls the factory of the bean, passing the resolver of its dependencies. Late
+// bindings of Singleton beans created upon the container initialization are deferred (see bindLateDependencies), others
+// are performed right away.
+func callResolvingBeanFactory(ctx context.Context, beanID string, chain *dependencyChain,
+	beanFactory func(context.Context, Resolver) (interface{}, error)) (interface{}, error) {
+	resolver := &factoryResolver{ctx: ctx, beanID: beanID, chain: chain}
+	beanInstance, err := beanFactory(ctx, resolver)
+	if err != nil || len(resolver.lateBindings) == 0 {
+		return beanInstance, err
+	}
+	if scopes[beanID] == Singleton && atomic.CompareAndSwapInt32(&containerInitialized, 0, 0) {
+		lateBindings = append(lateBindings, resolver.lateBindings...)
+		return beanInstance, nil
+	}
+	for _, lateBinding := range resolver.lateBindings {
+		if err := lateBinding(); err != nil {
+			return nil, err
+		}
+	}
+	return beanInstance, nil
+}
+
+// bindLateDependencies function performs late bindings of Singleton beans deferred upon the container initialization.
+func bindLateDependencies() error {
+	bindings := lateBindings
+	lateBindings = nil
+	for _, lateBinding := range bindings {
+		if err := lateBinding(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type resolvedRepository struct {
+	name string
+}
+
+type resolvedService struct {
+	repository *resolvedRepository
+}
+
+type resolvedSingleton struct{}
+
+type resolvedPair struct {
+	singleton interface{}
+	prototype interface{}
+}
+
+type resolvedPrototype struct {
+	Scope Scope `di.scope:"prototype"`
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoryResolvesSingletonFactoryDuringInitialization() {
+	// registering the dependent bean first doesn't matter: dependencies are created on demand
+	overwritten, err := RegisterResolvingBeanFactory("service", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		repository, err := Resolve[*resolvedRepository](deps, "repository")
+		if err != nil {
+			return nil, err
+		}
+		return &resolvedService{repository: repository}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	var created int32
+	_, err = RegisterBeanFactory("repository", Singleton, func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&created, 1)
+		return &resolvedRepository{name: "repository"}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	service := GetInstance("service").(*resolvedService)
+	assert.Same(suite.T(), GetInstance("repository"), service.repository)
+	assert.Equal(suite.T(), "repository", service.repository.name)
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(&created))
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoryResolvesStructBeansAndAliases() {
+	_, err := RegisterBean("singleton", reflect.TypeOf((*resolvedSingleton)(nil)))
+	assert.NoError(suite.T(), err)
+	err = RegisterAlias("alias", "singleton")
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prototype", reflect.TypeOf((*resolvedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("bean", Prototype, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		singleton, err := deps.Resolve("alias")
+		if err != nil {
+			return nil, err
+		}
+		prototype, err := deps.Resolve("prototype")
+		if err != nil {
+			return nil, err
+		}
+		return &resolvedPair{singleton: singleton, prototype: prototype}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	first := GetInstance("bean").(*resolvedPair)
+	second := GetInstance("bean").(*resolvedPair)
+	assert.Same(suite.T(), GetInstance("singleton"), first.singleton)
+	assert.Same(suite.T(), first.singleton, second.singleton)
+	assert.NotSame(suite.T(), first.prototype, second.prototype)
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoryWithMissingDependency() {
+	_, err := RegisterResolvingBeanFactory("bean", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("missing")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no dependency found: missing")
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoryWithWrongDependencyType() {
+	_, err := RegisterBean("singleton", reflect.TypeOf((*resolvedSingleton)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("bean", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return Resolve[*resolvedPrototype](deps, "singleton")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "bean singleton is of type *di.resolvedSingleton, not *di.resolvedPrototype")
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoriesWithCircularDependency() {
+	_, err := RegisterResolvingBeanFactory("first", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("second")
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("second", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("first")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "circular dependency detected for bean: ")
+}
+
+func (suite *TestSuite) TestResolvingPrototypeFactoriesWithCircularDependency() {
+	_, err := RegisterResolvingBeanFactory("first", Prototype, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("second")
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("second", Prototype, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("first")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafe("first")
+	assert.EqualError(suite.T(), err, "circular dependency detected for bean: first (first -> second -> first)")
+}
+
+func (suite *TestSuite) TestResolvingBeanFactoryIsUnregisteredWhenOverwritten() {
+	_, err := RegisterResolvingBeanFactory("bean", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return deps.Resolve("missing")
+	})
+	assert.NoError(suite.T(), err)
+	overwritten, err := RegisterBeanFactory("bean", Singleton, func(context.Context) (interface{}, error) {
+		return &resolvedSingleton{}, nil
+	})
+	assert.True(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.IsType(suite.T(), &resolvedSingleton{}, GetInstance("bean"))
+}
+
+type lateBoundParent struct {
+	child *lateBoundChild
+	// childAtPostConstruct is the child seen by PostConstruct, it must already be bound.
+	childAtPostConstruct *lateBoundChild
+}
+
+func (p *lateBoundParent) PostConstruct() error {
+	p.childAtPostConstruct = p.child
+	return nil
+}
+
+type lateBoundChild struct {
+	parent *lateBoundParent
+}
+
+func (suite *TestSuite) TestCircularSingletonFactoriesCantBeResolved() {
+	_, err := RegisterResolvingBeanFactory("parent", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		child, err := Resolve[*lateBoundChild](deps, "child")
+		return &lateBoundParent{child: child}, err
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("child", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		parent, err := Resolve[*lateBoundParent](deps, "parent")
+		return &lateBoundChild{parent: parent}, err
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.ErrorContains(suite.T(), err, "circular dependency detected for bean: ")
+}
+
+func (suite *TestSuite) TestResolveLateBindsCircularSingletonFactories() {
+	_, err := RegisterResolvingBeanFactory("parent", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		parent := &lateBoundParent{}
+		return parent, ResolveLate(deps, "child", func(child *lateBoundChild) {
+			parent.child = child
+		})
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterResolvingBeanFactory("child", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		parent, err := Resolve[*lateBoundParent](deps, "parent")
+		return &lateBoundChild{parent: parent}, err
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	parent := GetInstance("parent").(*lateBoundParent)
+	child := GetInstance("child").(*lateBoundChild)
+	assert.Same(suite.T(), child, parent.child)
+	assert.Same(suite.T(), child, parent.childAtPostConstruct)
+	assert.Same(suite.T(), parent, child.parent)
+}
+
+func (suite *TestSuite) TestResolveLateOfPrototypeFactory() {
+	_, err := RegisterResolvingBeanFactory("parent", Prototype, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		parent := &lateBoundParent{}
+		return parent, ResolveLate(deps, "child", func(child *lateBoundChild) {
+			parent.child = child
+		})
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("child", Singleton, func(context.Context) (interface{}, error) {
+		return &lateBoundChild{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Same(suite.T(), GetInstance("child"), GetInstance("parent").(*lateBoundParent).child)
+}
+
+func (suite *TestSuite) TestResolveLateFailure() {
+	_, err := RegisterResolvingBeanFactory("parent", Singleton, func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return &lateBoundParent{}, ResolveLate(deps, "child", func(*lateBoundChild) {})
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no dependency found: child")
+}
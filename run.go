@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultShutdownTimeout is a default timeout of the graceful shutdown performed by Run.
+const DefaultShutdownTimeout = 30 * time.Second
+
+type runOptions struct {
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+// RunOption is an option of Run function.
+type RunOption func(options *runOptions)
+
+// WithShutdownTimeout option sets the timeout of the graceful shutdown (DefaultShutdownTimeout by default).
+func WithShutdownTimeout(timeout time.Duration) RunOption {
+	return func(options *runOptions) {
+		options.shutdownTimeout = timeout
+	}
+}
+
+// WithSignals option sets the signals triggering the shutdown (SIGINT and SIGTERM by default). If no signals are
+// passed, the shutdown is triggered only by the context cancellation.
+func WithSignals(signals ...os.Signal) RunOption {
+	return func(options *runOptions) {
+		options.signals = signals
+	}
+}
+
+// Run function runs the application: it initializes the container, starts Lifecycle beans and blocks until one of
+// the signals (SIGINT or SIGTERM by default) is received or the context is canceled. Then it performs the graceful
+// shutdown: Lifecycle beans are stopped (the context passed to them is canceled upon the shutdown timeout) and the
+// container is closed.
+func Run(ctx context.Context, opts ...RunOption) error {
+	options := runOptions{
+		shutdownTimeout: DefaultShutdownTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := InitializeContainer(); err != nil {
+		return err
+	}
+	if err := Start(ctx); err != nil {
+		Close()
+		return err
+	}
+	if len(options.signals) > 0 {
+		var stopNotify context.CancelFunc
+		ctx, stopNotify = signal.NotifyContext(ctx, options.signals...)
+		defer stopNotify()
+	}
+	<-ctx.Done()
+	logrus.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), options.shutdownTimeout)
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- Stop(shutdownCtx)
+	}()
+	var err error
+	select {
+	case err = <-stopped:
+	case <-shutdownCtx.Done():
+		err = errors.New("graceful shutdown timed out")
+	}
+	Close()
+	return err
+}
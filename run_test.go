@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingLifecycleBean struct {
+	started chan struct{}
+}
+
+func (blb *blockingLifecycleBean) Start(context.Context) error {
+	close(blb.started)
+	return nil
+}
+
+func (blb *blockingLifecycleBean) Stop(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (suite *TestSuite) TestRun() {
+	var log []string
+	suite.registerLifecycleBeans(&lifecycleBean{name: "server", log: &log})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(suite.T(), Run(ctx))
+	assert.Equal(suite.T(), []string{"start server", "stop server"}, log)
+	_, err := GetInstanceSafe("server")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestRunStartFailure() {
+	var log []string
+	suite.registerLifecycleBeans(&lifecycleBean{name: "server", log: &log, startErr: errors.New("port is busy")})
+	assert.EqualError(suite.T(), Run(context.Background()), "can't start bean server: port is busy")
+}
+
+func (suite *TestSuite) TestRunShutdownTimeout() {
+	bean := &blockingLifecycleBean{started: make(chan struct{})}
+	overwritten, err := RegisterBeanInstance("server", bean)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-bean.started
+		cancel()
+	}()
+	err = Run(ctx, WithShutdownTimeout(10*time.Millisecond), WithSignals())
+	assert.Error(suite.T(), err)
+}
+
+type stuckLifecycleBean struct {
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (slb *stuckLifecycleBean) Start(context.Context) error {
+	close(slb.started)
+	return nil
+}
+
+func (slb *stuckLifecycleBean) Stop(context.Context) error {
+	<-slb.released
+	return nil
+}
+
+func (suite *TestSuite) TestRunShutdownTimeoutWithStuckBean() {
+	bean := &stuckLifecycleBean{started: make(chan struct{}), released: make(chan struct{})}
+	defer close(bean.released)
+	overwritten, err := RegisterBeanInstance("server", bean)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-bean.started
+		cancel()
+	}()
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, WithShutdownTimeout(10*time.Millisecond), WithSignals())
+	}()
+	select {
+	case err = <-done:
+		assert.EqualError(suite.T(), err, "graceful shutdown timed out")
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("Run didn't return after the shutdown timeout")
+	}
+}
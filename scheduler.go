@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduled is an interface marking singleton beans that should run periodically. The container-managed scheduler
+// starts running them after the container is initialized and stops upon Close.
+type Scheduled interface {
+	// Schedule method returns the schedule of the bean in the form of `@every <duration>` (e.g. `@every 5m`).
+	Schedule() string
+	// RunScheduled method is called according to the schedule. Runs of one bean never overlap, returned errors are
+	// logged. The context is canceled when the scheduler stops.
+	RunScheduled(ctx context.Context) error
+}
+
+type scheduler struct {
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+}
+
+var currentScheduler *scheduler
+
+// parseSchedule function parses the schedule in the form of `@every <duration>`.
+func parseSchedule(schedule string) (time.Duration, error) {
+	every, ok := strings.CutPrefix(strings.TrimSpace(schedule), "@every ")
+	if !ok {
+		return 0, errors.New("unsupported schedule: " + schedule)
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(every))
+	if err != nil {
+		return 0, errors.New("unsupported schedule: " + schedule)
+	}
+	if interval <= 0 {
+		return 0, errors.New("schedule interval must be positive: " + schedule)
+	}
+	return interval, nil
+}
+
+// startScheduler function starts running Scheduled singletons. Schedules are validated before any of them starts.
+func startScheduler() error {
+	intervals := make(map[string]time.Duration)
+	for beanID, instance := range singletonInstances {
+		scheduled, ok := instance.(Scheduled)
+		if !ok {
+			continue
+		}
+		interval, err := parseSchedule(scheduled.Schedule())
+		if err != nil {
+			return errors.New("invalid schedule of bean " + beanID + ": " + err.Error())
+		}
+		intervals[beanID] = interval
+	}
+	if len(intervals) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	currentScheduler = &scheduler{cancel: cancel}
+	for beanID, interval := range intervals {
+		currentScheduler.waitGroup.Add(1)
+		go runScheduled(ctx, &currentScheduler.waitGroup, beanID, singletonInstances[beanID].(Scheduled), interval)
+	}
+	return nil
+}
+
+func runScheduled(ctx context.Context, waitGroup *sync.WaitGroup, beanID string, scheduled Scheduled, interval time.Duration) {
+	defer waitGroup.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logrus.WithField("beanID", beanID).Trace("running scheduled bean")
+			if err := callScheduled(ctx, beanID, scheduled); err != nil {
+				logrus.WithField("beanID", beanID).Error(err)
+			}
+		}
+	}
+}
+
+// callScheduled function runs the scheduled bean, converting the panic to the error, so that it doesn't crash the
+// application and the next runs still happen.
+func callScheduled(ctx context.Context, beanID string, scheduled Scheduled) (err error) {
+	defer recoverBeanPanic(beanID, "RunScheduled", &err)
+	return scheduled.RunScheduled(ctx)
+}
+
+// stopScheduler function stops the scheduler, waiting for the running beans if `wait` is set.
+func stopScheduler(wait bool) {
+	if currentScheduler == nil {
+		return
+	}
+	currentScheduler.cancel()
+	if wait {
+		currentScheduler.waitGroup.Wait()
+	}
+	currentScheduler = nil
+}
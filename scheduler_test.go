@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scheduledBean struct {
+	schedule string
+	runs     int32
+}
+
+func (sb *scheduledBean) Schedule() string {
+	return sb.schedule
+}
+
+func (sb *scheduledBean) RunScheduled(context.Context) error {
+	if atomic.AddInt32(&sb.runs, 1)%2 == 0 {
+		return errors.New("every second run fails")
+	}
+	return nil
+}
+
+func (suite *TestSuite) TestScheduler() {
+	bean := &scheduledBean{schedule: "@every 1ms"}
+	overwritten, err := RegisterBeanInstance("cleanup", bean)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&bean.runs) >= 3
+	}, time.Second, time.Millisecond)
+	Close()
+	runs := atomic.LoadInt32(&bean.runs)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(suite.T(), runs, atomic.LoadInt32(&bean.runs))
+}
+
+type panickingScheduledBean struct {
+	runs int32
+}
+
+func (psb *panickingScheduledBean) Schedule() string {
+	return "@every 1ms"
+}
+
+func (psb *panickingScheduledBean) RunScheduled(context.Context) error {
+	atomic.AddInt32(&psb.runs, 1)
+	panic("scheduled run failed")
+}
+
+func (suite *TestSuite) TestSchedulerRecoversPanics() {
+	bean := &panickingScheduledBean{}
+	overwritten, err := RegisterBeanInstance("cleanup", bean)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&bean.runs) >= 3
+	}, time.Second, time.Millisecond)
+	err = callScheduled(context.Background(), "cleanup", bean)
+	var panicErr *BeanPanicError
+	if assert.ErrorAs(suite.T(), err, &panicErr) {
+		assert.Equal(suite.T(), "cleanup", panicErr.BeanID)
+		assert.Equal(suite.T(), "RunScheduled", panicErr.Phase)
+		assert.Equal(suite.T(), "scheduled run failed", panicErr.Value)
+	}
+}
+
+func (suite *TestSuite) TestSchedulerInvalidSchedule() {
+	for _, schedule := range []string{"*/5 * * * *", "@every soon", "@every -1s"} {
+		Reset()
+		overwritten, err := RegisterBeanInstance("cleanup", &scheduledBean{schedule: schedule})
+		assert.False(suite.T(), overwritten)
+		assert.NoError(suite.T(), err)
+		err = InitializeContainer()
+		assert.Error(suite.T(), err, schedule)
+	}
+}
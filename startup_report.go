@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BeanTiming holds durations of the Singleton bean startup phases.
+type BeanTiming struct {
+	// BeanID is the ID of the bean.
+	BeanID string
+	// Creation is the duration of the bean creation (i.e. of the factory call, for the beans registered with factories,
+	// including the creation of the dependencies they resolve).
+	Creation time.Duration
+	// Initialization is the duration of PostConstruct and postprocessors of the bean.
+	Initialization time.Duration
+}
+
+// Total method returns the total duration of the bean startup.
+func (bt BeanTiming) Total() time.Duration {
+	return bt.Creation + bt.Initialization
+}
+
+// StartupReport holds durations of the container initialization, see GetStartupReport.
+type StartupReport struct {
+	// Total is the duration of the whole InitializeContainer call.
+	Total time.Duration
+	// Beans are timings of Singleton beans, the slowest first.
+	Beans []BeanTiming
+}
+
+var startupTimings = make(map[string]*BeanTiming)
+var startupDuration time.Duration
+
+// startupReportSize is the number of the slowest beans logged after the initialization, see SetStartupReportLogging.
+var startupReportSize int32
+
+// GetStartupReport function returns durations of the container initialization: of the whole InitializeContainer call,
+// and of creation and initialization of each Singleton bean. The report is empty until the container is initialized.
+func GetStartupReport() StartupReport {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	return newStartupReport()
+}
+
+// SetStartupReportLogging function makes the container log the startup duration and the given number of the slowest
+// beans after the initialization (at the info level). Zero, which is the default, disables logging.
+func SetStartupReportLogging(slowestBeans int) {
+	atomic.StoreInt32(&startupReportSize, int32(slowestBeans))
+}
+
+func newStartupReport() StartupReport {
+	report := StartupReport{Total: startupDuration, Beans: make([]BeanTiming, 0, len(startupTimings))}
+	for _, timing := range startupTimings {
+		report.Beans = append(report.Beans, *timing)
+	}
+	sort.Slice(report.Beans, func(i, j int) bool {
+		if report.Beans[i].Total() != report.Beans[j].Total() {
+			return report.Beans[i].Total() > report.Beans[j].Total()
+		}
+		return report.Beans[i].BeanID < report.Beans[j].BeanID
+	})
+	return report
+}
+
+func getStartupTiming(beanID string) *BeanTiming {
+	timing, ok := startupTimings[beanID]
+	if !ok {
+		timing = &BeanTiming{BeanID: beanID}
+		startupTimings[beanID] = timing
+	}
+	return timing
+}
+
+func recordCreation(beanID string, start time.Time) {
+	getStartupTiming(beanID).Creation = time.Since(start)
+}
+
+func recordInitialization(beanID string, start time.Time) {
+	getStartupTiming(beanID).Initialization = time.Since(start)
+}
+
+// completeStartupReport function records the duration of the initialization and logs the slowest beans, if enabled.
+func completeStartupReport(start time.Time) {
+	startupDuration = time.Since(start)
+	slowestBeans := int(atomic.LoadInt32(&startupReportSize))
+	if slowestBeans <= 0 {
+		return
+	}
+	report := newStartupReport()
+	if len(report.Beans) > slowestBeans {
+		report.Beans = report.Beans[:slowestBeans]
+	}
+	logrus.WithField("duration", report.Total).Info("container initialized")
+	for _, timing := range report.Beans {
+		logrus.WithFields(logrus.Fields{
+			"beanID":         timing.BeanID,
+			"creation":       timing.Creation,
+			"initialization": timing.Initialization,
+		}).Info("slow bean startup")
+	}
+}
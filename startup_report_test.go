@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowlyInitializedBean struct{}
+
+func (*slowlyInitializedBean) PostConstruct() error {
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func (suite *TestSuite) TestStartupReport() {
+	assert.Equal(suite.T(), StartupReport{Beans: []BeanTiming{}}, GetStartupReport())
+	_, err := RegisterBeanFactory("slowlyCreated", Singleton, func(context.Context) (interface{}, error) {
+		time.Sleep(40 * time.Millisecond)
+		return &resolvedRepository{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("slowlyInitialized", reflect.TypeOf((*slowlyInitializedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("prototype", reflect.TypeOf((*resolvedPrototype)(nil)))
+	assert.NoError(suite.T(), err)
+	SetStartupReportLogging(1)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	report := GetStartupReport()
+	assert.GreaterOrEqual(suite.T(), report.Total, 60*time.Millisecond)
+	if assert.Len(suite.T(), report.Beans, 2) {
+		assert.Equal(suite.T(), "slowlyCreated", report.Beans[0].BeanID)
+		assert.GreaterOrEqual(suite.T(), report.Beans[0].Creation, 40*time.Millisecond)
+		assert.Equal(suite.T(), "slowlyInitialized", report.Beans[1].BeanID)
+		assert.GreaterOrEqual(suite.T(), report.Beans[1].Initialization, 20*time.Millisecond)
+		assert.Equal(suite.T(), report.Beans[1].Creation+report.Beans[1].Initialization, report.Beans[1].Total())
+	}
+	Reset()
+	assert.Equal(suite.T(), StartupReport{Beans: []BeanTiming{}}, GetStartupReport())
+	assert.Zero(suite.T(), startupReportSize)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"sync/atomic"
+)
+
+// ContainerState is the state of the container, see State.
+type ContainerState int
+
+const (
+	// Uninitialized state means beans can be registered, but can't be retrieved yet.
+	Uninitialized ContainerState = iota
+	// Initializing state means InitializeContainer is in progress.
+	Initializing
+	// Initialized state means the container is initialized: beans can be retrieved, but can't be registered.
+	Initialized
+	// Closing state means Close is in progress.
+	Closing
+	// Closed state means the container is closed. Since closing resets the container, beans can be registered and the
+	// container can be initialized again.
+	Closed
+)
+
+// String method returns the name of the state.
+func (s ContainerState) String() string {
+	switch s {
+	case Uninitialized:
+		return "uninitialized"
+	case Initializing:
+		return "initializing"
+	case Initialized:
+		return "initialized"
+	case Closing:
+		return "closing"
+	case Closed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// ContainerStatus holds the state of the container and the numbers of registered beans.
+type ContainerStatus struct {
+	State ContainerState
+	// Beans are the numbers of registered beans per scope. They're not counted (i.e. the map is nil) while the
+	// container is initializing or closing, since the registry is being changed.
+	Beans map[Scope]int
+}
+
+var containerClosing int32
+var containerClosed int32
+
+// State function returns the state of the container, so that libraries (e.g. registering beans lazily) can check it
+// without triggering errors. It doesn't block while the container is initializing or closing, so it can be called from
+// PostConstruct methods, Close methods, etc.
+func State() ContainerStatus {
+	state := currentState()
+	if state == Initializing || state == Closing {
+		return ContainerStatus{State: state}
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	status := ContainerStatus{State: currentState(), Beans: make(map[Scope]int)}
+	for _, scope := range scopes {
+		status.Beans[scope]++
+	}
+	return status
+}
+
+// IsInitialized function checks if the container is initialized, i.e. beans can be retrieved from it.
+func IsInitialized() bool {
+	return atomic.LoadInt32(&containerInitialized) == 1
+}
+
+func currentState() ContainerState {
+	currentInitializationLock.Lock()
+	initializing := currentInitialization != nil
+	currentInitializationLock.Unlock()
+	switch {
+	case initializing:
+		return Initializing
+	case atomic.LoadInt32(&containerClosing) == 1:
+		return Closing
+	case IsInitialized():
+		return Initialized
+	case atomic.LoadInt32(&containerClosed) == 1:
+		return Closed
+	}
+	return Uninitialized
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stateObservingBean struct {
+	initializing ContainerStatus
+	closing      ContainerStatus
+}
+
+func (sob *stateObservingBean) PostConstruct() error {
+	sob.initializing = State()
+	return nil
+}
+
+func (sob *stateObservingBean) Close() error {
+	sob.closing = State()
+	return nil
+}
+
+func (suite *TestSuite) TestState() {
+	assert.Equal(suite.T(), ContainerStatus{State: Uninitialized, Beans: map[Scope]int{}}, State())
+	assert.False(suite.T(), IsInitialized())
+	overwritten, err := RegisterBean("observer", reflect.TypeOf((*stateObservingBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanFactory("prototype", Prototype, func(context.Context) (interface{}, error) {
+		return &singletonBean{}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("requestBean", reflect.TypeOf((*requestBean)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanInstance("singleton", &singletonBean{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	expectedBeans := map[Scope]int{Singleton: 2, Prototype: 1, Request: 1}
+	assert.Equal(suite.T(), ContainerStatus{State: Uninitialized, Beans: expectedBeans}, State())
+	assert.NoError(suite.T(), InitializeContainer())
+	assert.True(suite.T(), IsInitialized())
+	assert.Equal(suite.T(), ContainerStatus{State: Initialized, Beans: expectedBeans}, State())
+	observer := GetInstance("observer").(*stateObservingBean)
+	assert.Equal(suite.T(), ContainerStatus{State: Initializing}, observer.initializing)
+	Close()
+	assert.False(suite.T(), IsInitialized())
+	assert.Equal(suite.T(), ContainerStatus{State: Closing}, observer.closing)
+	assert.Equal(suite.T(), ContainerStatus{State: Closed, Beans: map[Scope]int{}}, State())
+	Reset()
+	assert.Equal(suite.T(), Uninitialized, State().State)
+}
+
+func (suite *TestSuite) TestContainerStateString() {
+	assert.Equal(suite.T(), "uninitialized", Uninitialized.String())
+	assert.Equal(suite.T(), "initializing", Initializing.String())
+	assert.Equal(suite.T(), "initialized", Initialized.String())
+	assert.Equal(suite.T(), "closing", Closing.String())
+	assert.Equal(suite.T(), "closed", Closed.String())
+	assert.Equal(suite.T(), "unknown", ContainerState(42).String())
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TagValidation defines how the container reacts to unknown tags of the `di` namespace (e.g. typos like `di.injekt`)
+// upon the bean registration.
+type TagValidation int
+
+const (
+	// StrictTagValidation makes the registration of the bean with unknown tags fail. This is the default mode.
+	StrictTagValidation TagValidation = iota
+	// LenientTagValidation only logs a warning about unknown tags.
+	LenientTagValidation
+)
+
+var tagValidation = StrictTagValidation
+
+var knownTags = map[tag]bool{
+	scope: true, inject: true, optional: true, onMissing: true, profile: true, value: true, group: true,
+	injectGroup: true, primary: true, qualifier: true, defaultBean: true, includeSelf: true, exclude: true,
+	minSize: true, key: true, injectStruct: true,
+}
+
+var tagAliases = make(map[tag][]string)
+
+var tagPrefixes []string
+
+// RegisterTagAlias function makes the container recognize an additional tag key as the given di tag, e.g.
+// `RegisterTagAlias("inject", "di.inject")` makes fields tagged with `inject:""` (as in facebookgo/inject) injected, so
+// that existing codebases can be migrated without rewriting tags. The di tag itself is still recognized and takes
+// precedence over its aliases.
+func RegisterTagAlias(alias string, tagName string) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register tag alias")
+	}
+	if !strings.HasPrefix(tagName, "di.") {
+		return errors.New("not a di tag: " + tagName)
+	}
+	if alias == "" || strings.HasPrefix(alias, "di.") {
+		return errors.New("invalid tag alias: " + alias)
+	}
+	tagAliases[tag(tagName)] = append(tagAliases[tag(tagName)], alias)
+	invalidateInjectionPlans()
+	return nil
+}
+
+// RegisterTagPrefix function makes the container recognize all di tags with a custom prefix instead of `di`, e.g.
+// `RegisterTagPrefix("autowire")` makes `autowire.inject`, `autowire.scope`, `autowire.value` etc. equivalent to
+// `di.inject`, `di.scope` and `di.value`.
+func RegisterTagPrefix(prefix string) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register tag prefix")
+	}
+	if prefix == "" || prefix == "di" || strings.Contains(prefix, ".") {
+		return errors.New("invalid tag prefix: " + prefix)
+	}
+	tagPrefixes = append(tagPrefixes, prefix)
+	invalidateInjectionPlans()
+	return nil
+}
+
+// SetTagValidation function sets the mode of validation of tags of the `di` namespace (and of custom prefixes, see
+// RegisterTagPrefix) upon the bean registration.
+func SetTagValidation(mode TagValidation) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	tagValidation = mode
+}
+
+// validateTags function checks that fields of the bean struct have no unknown tags of the `di` namespace (or of custom
+// prefixes).
+func validateTags(beanID string, beanType reflect.Type) error {
+	fields, err := injectableFields(beanType.Elem())
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		for _, tagKey := range tagKeys(field.Tag) {
+			if isKnownTag(tagKey) {
+				continue
+			}
+			if tagValidation == LenientTagValidation {
+				logrus.WithFields(logrus.Fields{
+					"beanID": beanID,
+					"field":  field.Name,
+					"tag":    tagKey,
+				}).Warn("unknown tag")
+				continue
+			}
+			return errors.New("unknown tag " + tagKey + " of field " + field.Name + " of bean " + beanID)
+		}
+	}
+	return nil
+}
+
+// isKnownTag function checks if the key of the struct tag is either a known tag of the `di` namespace (or of custom
+// prefixes), or doesn't belong to these namespaces at all.
+func isKnownTag(tagKey string) bool {
+	for _, prefix := range append([]string{"di"}, tagPrefixes...) {
+		if tagKey == prefix || strings.HasPrefix(tagKey, prefix+".") {
+			return knownTags[tag("di"+strings.TrimPrefix(tagKey, prefix))]
+		}
+	}
+	return true
+}
+
+// tagKeys function returns keys of the struct tag, following the conventional format of `key:"value"` pairs separated
+// by spaces (see reflect.StructTag).
+func tagKeys(structTag reflect.StructTag) []string {
+	var keys []string
+	for structTag != "" {
+		i := 0
+		for i < len(structTag) && structTag[i] == ' ' {
+			i++
+		}
+		structTag = structTag[i:]
+		if structTag == "" {
+			break
+		}
+		i = 0
+		for i < len(structTag) && structTag[i] > ' ' && structTag[i] != ':' && structTag[i] != '"' && structTag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(structTag) || structTag[i] != ':' || structTag[i+1] != '"' {
+			break
+		}
+		keys = append(keys, string(structTag[:i]))
+		structTag = structTag[i+1:]
+		i = 1
+		for i < len(structTag) && structTag[i] != '"' {
+			if structTag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(structTag) {
+			break
+		}
+		structTag = structTag[i+1:]
+	}
+	return keys
+}
+
+// lookup method returns the value of the tag of the field, looking up its aliases and custom prefixes if the tag itself
+// is not present.
+func (t tag) lookup(field reflect.StructField) (string, bool) {
+	if tagValue, ok := field.Tag.Lookup(string(t)); ok {
+		return tagValue, true
+	}
+	for _, alias := range tagAliases[t] {
+		if tagValue, ok := field.Tag.Lookup(alias); ok {
+			return tagValue, true
+		}
+	}
+	for _, prefix := range tagPrefixes {
+		if tagValue, ok := field.Tag.Lookup(prefix + strings.TrimPrefix(string(t), "di")); ok {
+			return tagValue, true
+		}
+	}
+	return "", false
+}
+
+// get method returns the value of the tag of the field (see lookup), or an empty string if there's no such tag.
+func (t tag) get(field reflect.StructField) string {
+	tagValue, _ := t.lookup(field)
+	return tagValue
+}
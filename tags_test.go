@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type legacyTaggedBean struct {
+	Scope      struct{}             `autowire.scope:"prototype"`
+	Dependency *singletonDependency `inject:""`
+	Optional   *singletonDependency `inject:"missing" autowire.optional:"true"`
+	Value      string               `autowire.value:"legacy"`
+	Native     *singletonDependency `di.inject:"dependency" inject:"missing"`
+}
+
+func (suite *TestSuite) TestTagAliasesAndPrefixes() {
+	err := RegisterTagAlias("inject", "di.inject")
+	assert.NoError(suite.T(), err)
+	err = RegisterTagPrefix("autowire")
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("bean", reflect.TypeOf((*legacyTaggedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Prototype, scopes["bean"])
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	bean := GetInstance("bean").(*legacyTaggedBean)
+	assert.Same(suite.T(), GetInstance("dependency"), bean.Dependency)
+	assert.Nil(suite.T(), bean.Optional)
+	assert.Equal(suite.T(), "legacy", bean.Value)
+	assert.Same(suite.T(), GetInstance("dependency"), bean.Native)
+}
+
+func (suite *TestSuite) TestTagsAreNotAliasedByDefault() {
+	_, err := RegisterBean("dependency", reflect.TypeOf((*singletonDependency)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("bean", reflect.TypeOf((*legacyTaggedBean)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Singleton, scopes["bean"])
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	bean := GetInstance("bean").(*legacyTaggedBean)
+	assert.Nil(suite.T(), bean.Dependency)
+	assert.Empty(suite.T(), bean.Value)
+}
+
+func (suite *TestSuite) TestInvalidTagAliasesAndPrefixes() {
+	assert.EqualError(suite.T(), RegisterTagAlias("inject", "inject"), "not a di tag: inject")
+	assert.EqualError(suite.T(), RegisterTagAlias("di.autowire", "di.inject"), "invalid tag alias: di.autowire")
+	assert.EqualError(suite.T(), RegisterTagPrefix("di"), "invalid tag prefix: di")
+	assert.EqualError(suite.T(), RegisterTagPrefix("auto.wire"), "invalid tag prefix: auto.wire")
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.EqualError(suite.T(), RegisterTagAlias("inject", "di.inject"),
+		"container is already initialized: can't register tag alias")
+	assert.EqualError(suite.T(), RegisterTagPrefix("autowire"),
+		"container is already initialized: can't register tag prefix")
+}
+
+type misspelledTagBean struct {
+	Dependency *singletonDependency `di.injekt:""`
+	JSON       string               `json:"value" di.value:"value"`
+}
+
+func (suite *TestSuite) TestUnknownTagsFailRegistration() {
+	_, err := RegisterBean("bean", reflect.TypeOf((*misspelledTagBean)(nil)))
+	assert.EqualError(suite.T(), err, "unknown tag di.injekt of field Dependency of bean bean")
+	assert.False(suite.T(), isBeanRegistered("bean"))
+}
+
+func (suite *TestSuite) TestUnknownTagsOfCustomPrefixFailRegistration() {
+	type Bean struct {
+		Nested struct {
+			Dependency *singletonDependency `autowire.scop:"prototype"`
+		} `autowire.inject.struct:"true"`
+	}
+	err := RegisterTagPrefix("autowire")
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("bean", reflect.TypeOf((*Bean)(nil)))
+	assert.EqualError(suite.T(), err, "unknown tag autowire.scop of field Nested.Dependency of bean bean")
+}
+
+func (suite *TestSuite) TestUnknownTagsAreAllowedInLenientMode() {
+	SetTagValidation(LenientTagValidation)
+	_, err := RegisterBean("bean", reflect.TypeOf((*misspelledTagBean)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), GetInstance("bean").(*misspelledTagBean).Dependency)
+}
+
+func (suite *TestSuite) TestTagKeys() {
+	assert.Equal(suite.T(), []string{"json", "di.inject", "di.value"},
+		tagKeys(`json:"a,omitempty"  di.inject:"\"quoted\"" di.value:"${x}"`))
+	assert.Equal(suite.T(), []string{"di.inject"}, tagKeys(`di.inject:"" malformed`))
+	assert.Empty(suite.T(), tagKeys(""))
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TenantScopeConfig is a configuration of the Tenant scope.
+type TenantScopeConfig struct {
+	// Resolver extracts the tenant ID from the context, it's mandatory.
+	Resolver func(ctx context.Context) (tenantID string, ok bool)
+	// MaxTenants limits the number of tenants whose beans are cached: the least recently used tenant is evicted once
+	// the limit is exceeded. Zero means no limit.
+	MaxTenants int
+	// TTL is a period after the last access, upon which beans of the tenant are evicted. Zero means no expiration.
+	TTL time.Duration
+}
+
+// tenantScope holds Tenant-scoped beans, cached per tenant.
+type tenantScope struct {
+	lock    sync.Mutex
+	config  TenantScopeConfig
+	tenants map[string]*list.Element
+	// lru keeps tenants ordered by the last access, the most recently used tenant is at the front.
+	lru *list.List
+}
+
+type tenantEntry struct {
+	tenantID   string
+	lastAccess time.Time
+	scope      *requestScope
+}
+
+var tenantBeans = newTenantScope()
+
+func newTenantScope() *tenantScope {
+	return &tenantScope{tenants: make(map[string]*list.Element), lru: list.New()}
+}
+
+// ConfigureTenantScope function configures the Tenant scope. Evicted beans implementing io.Closer are closed in reverse
+// creation order.
+func ConfigureTenantScope(config TenantScopeConfig) error {
+	if config.Resolver == nil {
+		return errors.New("tenant resolver is mandatory")
+	}
+	if config.MaxTenants < 0 || config.TTL < 0 {
+		return errors.New("tenant scope limits can't be negative")
+	}
+	tenantBeans.lock.Lock()
+	defer tenantBeans.lock.Unlock()
+	tenantBeans.config = config
+	return nil
+}
+
+func (ts *tenantScope) getInstance(ctx context.Context, beanID string, create func() (interface{}, error)) (interface{}, error) {
+	ts.lock.Lock()
+	resolver := ts.config.Resolver
+	ts.lock.Unlock()
+	if resolver == nil {
+		return nil, errors.New("tenant scope is not configured: see ConfigureTenantScope")
+	}
+	tenantID, ok := resolver(ctx)
+	if !ok {
+		return nil, errors.New("tenant can't be resolved from the context for bean: " + beanID)
+	}
+	scope, evicted := ts.getTenantScope(tenantID)
+	for _, evictedScope := range evicted {
+		evictedScope.close()
+	}
+	return scope.getOrCreate(beanID, create)
+}
+
+// getTenantScope method returns the scope of the tenant (creating it, if needed) and the scopes of the evicted tenants.
+func (ts *tenantScope) getTenantScope(tenantID string) (*requestScope, []*requestScope) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	now := time.Now()
+	var evicted []*requestScope
+	if ts.config.TTL > 0 {
+		for element := ts.lru.Back(); element != nil; element = ts.lru.Back() {
+			entry := element.Value.(*tenantEntry)
+			if now.Sub(entry.lastAccess) < ts.config.TTL {
+				break
+			}
+			evicted = append(evicted, ts.evict(element))
+		}
+	}
+	element, ok := ts.tenants[tenantID]
+	if ok {
+		element.Value.(*tenantEntry).lastAccess = now
+		ts.lru.MoveToFront(element)
+		return element.Value.(*tenantEntry).scope, evicted
+	}
+	entry := &tenantEntry{
+		tenantID:   tenantID,
+		lastAccess: now,
+		scope:      &requestScope{entries: make(map[string]*requestScopeEntry)},
+	}
+	ts.tenants[tenantID] = ts.lru.PushFront(entry)
+	if ts.config.MaxTenants > 0 && ts.lru.Len() > ts.config.MaxTenants {
+		evicted = append(evicted, ts.evict(ts.lru.Back()))
+	}
+	return entry.scope, evicted
+}
+
+func (ts *tenantScope) evict(element *list.Element) *requestScope {
+	entry := ts.lru.Remove(element).(*tenantEntry)
+	delete(ts.tenants, entry.tenantID)
+	logrus.WithField("tenantID", entry.tenantID).Trace("evicting tenant beans")
+	return entry.scope
+}
+
+// close method evicts all the tenants.
+func (ts *tenantScope) close() {
+	ts.lock.Lock()
+	var evicted []*requestScope
+	for element := ts.lru.Back(); element != nil; element = ts.lru.Back() {
+		evicted = append(evicted, ts.evict(element))
+	}
+	ts.lock.Unlock()
+	for _, scope := range evicted {
+		scope.close()
+	}
+}
+
+// getOrCreate method returns the bean instance of the scope, creating it once.
+func (rs *requestScope) getOrCreate(beanID string, create func() (interface{}, error)) (interface{}, error) {
+	rs.lock.Lock()
+	entry, ok := rs.entries[beanID]
+	if !ok {
+		entry = &requestScopeEntry{}
+		rs.entries[beanID] = entry
+	}
+	rs.lock.Unlock()
+	entry.once.Do(func() {
+		entry.beanInstance, entry.err = create()
+		if entry.err == nil && isCloseable(entry.beanInstance) {
+			rs.addCloseable(beanID)
+		}
+	})
+	if entry.err != nil {
+		rs.lock.Lock()
+		if rs.entries[beanID] == entry {
+			delete(rs.entries, beanID)
+		}
+		rs.lock.Unlock()
+	}
+	return entry.beanInstance, entry.err
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+type tenantConnection struct {
+	Scope  Scope `di.scope:"tenant"`
+	closed bool
+}
+
+func (tc *tenantConnection) Close() error {
+	tc.closed = true
+	return nil
+}
+
+type tenantConnectionConsumer struct {
+	connection *tenantConnection `di.inject:""`
+}
+
+func tenantContext(tenantID string) context.Context {
+	return context.WithValue(context.Background(), tenantKey{}, tenantID)
+}
+
+func resolveTenant(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok
+}
+
+func (suite *TestSuite) TestTenantScope() {
+	assert.Error(suite.T(), ConfigureTenantScope(TenantScopeConfig{}))
+	assert.Error(suite.T(), ConfigureTenantScope(TenantScopeConfig{Resolver: resolveTenant, MaxTenants: -1}))
+	assert.NoError(suite.T(), ConfigureTenantScope(TenantScopeConfig{Resolver: resolveTenant, MaxTenants: 2}))
+	overwritten, err := RegisterBean("connection", reflect.TypeOf((*tenantConnection)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	acme := GetInstanceCtx(tenantContext("acme"), "connection").(*tenantConnection)
+	assert.Same(suite.T(), acme, GetInstanceCtx(tenantContext("acme"), "connection"))
+	globex := GetInstanceCtx(tenantContext("globex"), "connection").(*tenantConnection)
+	assert.NotSame(suite.T(), acme, globex)
+	GetInstanceCtx(tenantContext("acme"), "connection")
+	initech := GetInstanceCtx(tenantContext("initech"), "connection").(*tenantConnection)
+	assert.True(suite.T(), globex.closed)
+	assert.False(suite.T(), acme.closed)
+	assert.NotSame(suite.T(), globex, GetInstanceCtx(tenantContext("globex"), "connection"))
+	assert.True(suite.T(), acme.closed)
+	_, err = GetInstanceSafeCtx(context.Background(), "connection")
+	assert.Error(suite.T(), err)
+	Close()
+	assert.True(suite.T(), initech.closed)
+}
+
+func (suite *TestSuite) TestTenantScopeTTL() {
+	assert.NoError(suite.T(), ConfigureTenantScope(TenantScopeConfig{Resolver: resolveTenant, TTL: time.Millisecond}))
+	overwritten, err := RegisterBean("connection", reflect.TypeOf((*tenantConnection)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	acme := GetInstanceCtx(tenantContext("acme"), "connection").(*tenantConnection)
+	time.Sleep(5 * time.Millisecond)
+	assert.NotSame(suite.T(), acme, GetInstanceCtx(tenantContext("acme"), "connection"))
+	assert.True(suite.T(), acme.closed)
+}
+
+func (suite *TestSuite) TestTenantScopeErrors() {
+	overwritten, err := RegisterBean("connection", reflect.TypeOf((*tenantConnection)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("consumer", reflect.TypeOf((*tenantConnectionConsumer)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.Error(suite.T(), err)
+	Reset()
+	overwritten, err = RegisterBean("connection", reflect.TypeOf((*tenantConnection)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	_, err = GetInstanceSafeCtx(tenantContext("acme"), "connection")
+	assert.EqualError(suite.T(), err, "tenant scope is not configured: see ConfigureTenantScope")
+}
@@ -0,0 +1,430 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+)
+
+// beanRegistration holds everything the container knows about a single bean.
+type beanRegistration struct {
+	beanType     reflect.Type
+	beanFactory  func(context.Context) (interface{}, error)
+	resolving    func(context.Context, Resolver) (interface{}, error)
+	factoryType  reflect.Type
+	sampledType  reflect.Type
+	primary      bool
+	lazy         bool
+	dependsOn    []string
+	qualifier    *string
+	decorators   []beanDecorator
+	groups       map[string]bool
+	module       *string
+	beanScope    Scope
+	instance     interface{}
+	registered   bool
+	instantiated bool
+	userCreated  bool
+}
+
+func saveBeanRegistration(beanID string) beanRegistration {
+	registration := beanRegistration{
+		beanType:    beans[beanID],
+		beanFactory: beanFactories[beanID],
+		resolving:   resolvingBeanFactories[beanID],
+		factoryType: factoryBeanTypes[beanID],
+		sampledType: sampledBeanTypes[beanID],
+		primary:     primaryBeans[beanID],
+		lazy:        lazyBeans[beanID],
+		dependsOn:   append([]string(nil), beanDependencies[beanID]...),
+		decorators:  append([]beanDecorator(nil), beanDecorators[beanID]...),
+		beanScope:   scopes[beanID],
+		userCreated: userCreatedInstances[beanID],
+	}
+	if qualifier, ok := beanQualifiers[beanID]; ok {
+		registration.qualifier = &qualifier
+	}
+	if groups, ok := beanGroups[beanID]; ok {
+		registration.groups = make(map[string]bool, len(groups))
+		for group := range groups {
+			registration.groups[group] = true
+		}
+	}
+	if module, ok := beanModules[beanID]; ok {
+		registration.module = &module
+	}
+	_, registration.registered = scopes[beanID]
+	registration.instance, registration.instantiated = singletonInstances[beanID]
+	return registration
+}
+
+func restoreBeanRegistration(beanID string, registration beanRegistration) {
+	unregisterBean(beanID)
+	if !registration.registered {
+		return
+	}
+	if registration.beanType != nil {
+		beans[beanID] = registration.beanType
+	}
+	if registration.beanFactory != nil {
+		beanFactories[beanID] = registration.beanFactory
+	}
+	if registration.resolving != nil {
+		resolvingBeanFactories[beanID] = registration.resolving
+	}
+	if registration.factoryType != nil {
+		factoryBeanTypes[beanID] = registration.factoryType
+	}
+	if registration.sampledType != nil {
+		sampledBeanTypes[beanID] = registration.sampledType
+	}
+	if registration.primary {
+		primaryBeans[beanID] = true
+	}
+	if registration.lazy {
+		lazyBeans[beanID] = true
+	}
+	if registration.dependsOn != nil {
+		beanDependencies[beanID] = registration.dependsOn
+	}
+	if registration.qualifier != nil {
+		beanQualifiers[beanID] = *registration.qualifier
+	}
+	if registration.decorators != nil {
+		beanDecorators[beanID] = registration.decorators
+	}
+	if registration.groups != nil {
+		beanGroups[beanID] = registration.groups
+	}
+	if registration.module != nil {
+		beanModules[beanID] = *registration.module
+	}
+	scopes[beanID] = registration.beanScope
+	if registration.instantiated {
+		singletonInstances[beanID] = registration.instance
+	}
+	if registration.userCreated {
+		userCreatedInstances[beanID] = true
+	}
+}
+
+// overriddenInstances holds instances set by OverrideBeanInstance after the container initialization. They are never
+// written to the registration maps (that are read without locking once the container is initialized), but published
+// along with the singletons instead.
+var overriddenInstances = make(map[string]interface{})
+
+// OverrideBeanInstance function replaces the bean with the given instance (e.g. a mock) and returns the function that
+// restores the original bean. It's meant to be used in tests and, unlike registration functions, works even after the
+// container is initialized. Note that beans that have been already injected with the original bean keep referencing it:
+// only lookups and beans created after the override get the new instance. It panics if the instance is not a pointer.
+//
+// Once the container is initialized, the override is published atomically (the same way as singletons are), so it's
+// safe to override beans while other goroutines look them up. It's not visible from Request scopes though:
+// Request-scoped beans are still created by their original definitions. An override made before the initialization must
+// be restored before it as well (or once the container is closed), otherwise the restore function panics.
+func OverrideBeanInstance(beanID string, beanInstance interface{}) (restore func()) {
+	beanType := reflect.TypeOf(beanInstance)
+	if beanType == nil || beanType.Kind() != reflect.Ptr {
+		panic(errors.New("bean instance must be a pointer"))
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return overrideInitializedBeanInstance(beanID, beanInstance)
+	}
+	registration := saveBeanRegistration(beanID)
+	unregisterBean(beanID)
+	beans[beanID] = beanType
+	scopes[beanID] = Singleton
+	singletonInstances[beanID] = beanInstance
+	userCreatedInstances[beanID] = true
+	return func() {
+		initializeShutdownLock.Lock()
+		defer initializeShutdownLock.Unlock()
+		if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+			panic(errors.New("bean " + beanID + " was overridden before the container initialization: " +
+				"it can only be restored before the initialization or after the container is closed"))
+		}
+		restoreBeanRegistration(beanID, registration)
+	}
+}
+
+// overrideInitializedBeanInstance function overrides the bean of the initialized container. It should be called with
+// the initializeShutdownLock held.
+func overrideInitializedBeanInstance(beanID string, beanInstance interface{}) (restore func()) {
+	previousInstance, overridden := overriddenInstances[beanID]
+	overriddenInstances[beanID] = beanInstance
+	publishSingletons()
+	return func() {
+		initializeShutdownLock.Lock()
+		defer initializeShutdownLock.Unlock()
+		if overridden {
+			overriddenInstances[beanID] = previousInstance
+		} else {
+			delete(overriddenInstances, beanID)
+		}
+		if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+			publishSingletons()
+		}
+	}
+}
+
+// ContainerSnapshot is an opaque copy of the container registrations, see Snapshot.
+type ContainerSnapshot struct {
+	beans                        map[string]reflect.Type
+	beanFactories                map[string]func(context.Context) (interface{}, error)
+	resolvingBeanFactories       map[string]func(context.Context, Resolver) (interface{}, error)
+	factoryBeanTypes             map[string]reflect.Type
+	scopes                       map[string]Scope
+	singletonInstances           map[string]interface{}
+	userCreatedInstances         map[string]bool
+	beanPostprocessors           map[reflect.Type][]beanPostprocessor
+	defaultBeans                 map[reflect.Type]string
+	overwritePolicy              OverwritePolicy
+	ambiguityPolicy              AmbiguityPolicy
+	optionalAmbiguityPolicy      OptionalAmbiguityPolicy
+	emptyCollectionPolicy        EmptyCollectionPolicy
+	tagAliases                   map[tag][]string
+	tagPrefixes                  []string
+	tagValidation                TagValidation
+	maxResolutionDepth           int32
+	invokers                     []reflect.Value
+	interceptors                 []interceptor
+	proxyFactories               map[reflect.Type]proxyFactory
+	primaryBeans                 map[string]bool
+	lazyBeans                    map[string]bool
+	beanDependencies             map[string][]string
+	beanQualifiers               map[string]string
+	beanDecorators               map[string][]beanDecorator
+	requestBeansClosePolicy      int32
+	unsafeInjection              int32
+	nilBeansAllowed              int32
+	startupReportSize            int32
+	instanceTracking             int32
+	activeProfiles               map[string]bool
+	propertySources              []PropertySource
+	aliases                      map[string]string
+	registeredTypes              map[string]reflect.Type
+	beanModules                  map[string]string
+	beanGroups                   map[string]map[string]bool
+	beanDefinitionPostprocessors []func(registry *BeanDefinitionRegistry) error
+	containerHooks               []func(event ContainerEvent)
+	leakHandler                  func(beanID string)
+	beanValidator                BeanValidator
+	tenantScopeConfig            TenantScopeConfig
+}
+
+// Snapshot function captures the registrations of the container (beans, postprocessors and settings), so that they can
+// be restored with Restore later, e.g. between test cases. Instances of beans created by the container itself are not
+// captured, so the snapshot of an initialized container restores it to the registered-but-uninitialized state.
+func Snapshot() *ContainerSnapshot {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	snapshot := &ContainerSnapshot{
+		beans:         make(map[string]reflect.Type, len(beans)),
+		beanFactories: make(map[string]func(context.Context) (interface{}, error), len(beanFactories)),
+		resolvingBeanFactories: make(map[string]func(context.Context, Resolver) (interface{}, error),
+			len(resolvingBeanFactories)),
+		factoryBeanTypes:        make(map[string]reflect.Type, len(factoryBeanTypes)),
+		scopes:                  make(map[string]Scope, len(scopes)),
+		singletonInstances:      make(map[string]interface{}, len(userCreatedInstances)),
+		userCreatedInstances:    make(map[string]bool, len(userCreatedInstances)),
+		beanPostprocessors:      make(map[reflect.Type][]beanPostprocessor, len(beanPostprocessors)),
+		defaultBeans:            make(map[reflect.Type]string, len(defaultBeans)),
+		proxyFactories:          make(map[reflect.Type]proxyFactory, len(proxyFactories)),
+		aliases:                 make(map[string]string, len(aliases)),
+		registeredTypes:         make(map[string]reflect.Type, len(registeredTypes)),
+		beanModules:             make(map[string]string, len(beanModules)),
+		beanGroups:              make(map[string]map[string]bool, len(beanGroups)),
+		overwritePolicy:         overwritePolicy,
+		ambiguityPolicy:         ambiguityPolicy,
+		optionalAmbiguityPolicy: optionalAmbiguityPolicy,
+		emptyCollectionPolicy:   emptyCollectionPolicy,
+		tagAliases:              make(map[tag][]string, len(tagAliases)),
+		tagPrefixes:             append([]string(nil), tagPrefixes...),
+		tagValidation:           tagValidation,
+		maxResolutionDepth:      atomic.LoadInt32(&maxResolutionDepth),
+		invokers:                append([]reflect.Value(nil), invokers...),
+		interceptors:            append([]interceptor(nil), interceptors...),
+		primaryBeans:            make(map[string]bool, len(primaryBeans)),
+		lazyBeans:               make(map[string]bool, len(lazyBeans)),
+		beanDependencies:        make(map[string][]string, len(beanDependencies)),
+		beanQualifiers:          make(map[string]string, len(beanQualifiers)),
+		beanDecorators:          make(map[string][]beanDecorator, len(beanDecorators)),
+		requestBeansClosePolicy: atomic.LoadInt32(&requestBeansClosePolicy),
+		unsafeInjection:         atomic.LoadInt32(&unsafeInjection),
+		nilBeansAllowed:         atomic.LoadInt32(&nilBeansAllowed),
+		startupReportSize:       atomic.LoadInt32(&startupReportSize),
+		instanceTracking:        atomic.LoadInt32(&instanceTracking),
+		activeProfiles:          make(map[string]bool, len(activeProfiles)),
+		propertySources:         append([]PropertySource(nil), propertySources...),
+		beanDefinitionPostprocessors: append([]func(registry *BeanDefinitionRegistry) error(nil),
+			beanDefinitionPostprocessors...),
+		containerHooks:    append(containerHooks[:0:0], containerHooks...),
+		tenantScopeConfig: tenantBeans.config,
+		leakHandler:       getLeakHandler(),
+		beanValidator:     getBeanValidator(),
+	}
+	for k, v := range activeProfiles {
+		snapshot.activeProfiles[k] = v
+	}
+	copySnapshotMaps(snapshot, &ContainerSnapshot{
+		beans:                  beans,
+		beanFactories:          beanFactories,
+		resolvingBeanFactories: resolvingBeanFactories,
+		factoryBeanTypes:       factoryBeanTypes,
+		scopes:                 scopes,
+		singletonInstances:     singletonInstances,
+		userCreatedInstances:   userCreatedInstances,
+		beanPostprocessors:     beanPostprocessors,
+		defaultBeans:           defaultBeans,
+		proxyFactories:         proxyFactories,
+		aliases:                aliases,
+		registeredTypes:        registeredTypes,
+		beanModules:            beanModules,
+		beanGroups:             beanGroups,
+		primaryBeans:           primaryBeans,
+		lazyBeans:              lazyBeans,
+		beanDependencies:       beanDependencies,
+		beanQualifiers:         beanQualifiers,
+		beanDecorators:         beanDecorators,
+		tagAliases:             tagAliases,
+	})
+	return snapshot
+}
+
+// Restore function resets the container (see Reset) and restores the registrations captured by Snapshot. The snapshot
+// itself is not modified, so it can be restored multiple times.
+func Restore(snapshot *ContainerSnapshot) {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	resetContainerWithoutLock()
+	copySnapshotMaps(&ContainerSnapshot{
+		beans:                  beans,
+		beanFactories:          beanFactories,
+		resolvingBeanFactories: resolvingBeanFactories,
+		factoryBeanTypes:       factoryBeanTypes,
+		scopes:                 scopes,
+		singletonInstances:     singletonInstances,
+		userCreatedInstances:   userCreatedInstances,
+		beanPostprocessors:     beanPostprocessors,
+		defaultBeans:           defaultBeans,
+		proxyFactories:         proxyFactories,
+		aliases:                aliases,
+		registeredTypes:        registeredTypes,
+		beanModules:            beanModules,
+		beanGroups:             beanGroups,
+		primaryBeans:           primaryBeans,
+		lazyBeans:              lazyBeans,
+		beanDependencies:       beanDependencies,
+		beanQualifiers:         beanQualifiers,
+		beanDecorators:         beanDecorators,
+		tagAliases:             tagAliases,
+	}, snapshot)
+	overwritePolicy = snapshot.overwritePolicy
+	ambiguityPolicy = snapshot.ambiguityPolicy
+	optionalAmbiguityPolicy = snapshot.optionalAmbiguityPolicy
+	emptyCollectionPolicy = snapshot.emptyCollectionPolicy
+	tagPrefixes = append([]string(nil), snapshot.tagPrefixes...)
+	tagValidation = snapshot.tagValidation
+	atomic.StoreInt32(&maxResolutionDepth, snapshot.maxResolutionDepth)
+	invokers = append([]reflect.Value(nil), snapshot.invokers...)
+	interceptors = append([]interceptor(nil), snapshot.interceptors...)
+	atomic.StoreInt32(&requestBeansClosePolicy, snapshot.requestBeansClosePolicy)
+	atomic.StoreInt32(&unsafeInjection, snapshot.unsafeInjection)
+	atomic.StoreInt32(&nilBeansAllowed, snapshot.nilBeansAllowed)
+	atomic.StoreInt32(&startupReportSize, snapshot.startupReportSize)
+	atomic.StoreInt32(&instanceTracking, snapshot.instanceTracking)
+	setPropertySources(append([]PropertySource(nil), snapshot.propertySources...))
+	beanDefinitionPostprocessors = append([]func(registry *BeanDefinitionRegistry) error(nil),
+		snapshot.beanDefinitionPostprocessors...)
+	setContainerHooks(append(snapshot.containerHooks[:0:0], snapshot.containerHooks...))
+	tenantBeans.config = snapshot.tenantScopeConfig
+	SetLeakHandler(snapshot.leakHandler)
+	SetBeanValidator(snapshot.beanValidator)
+	activeProfiles = make(map[string]bool, len(snapshot.activeProfiles))
+	for k, v := range snapshot.activeProfiles {
+		activeProfiles[k] = v
+	}
+}
+
+// copySnapshotMaps function copies the registrations from one set of maps to another. Only user-created singleton
+// instances are copied.
+func copySnapshotMaps(dst *ContainerSnapshot, src *ContainerSnapshot) {
+	for k, v := range src.beans {
+		dst.beans[k] = v
+	}
+	for k, v := range src.beanFactories {
+		dst.beanFactories[k] = v
+	}
+	for k, v := range src.resolvingBeanFactories {
+		dst.resolvingBeanFactories[k] = v
+	}
+	for k, v := range src.factoryBeanTypes {
+		dst.factoryBeanTypes[k] = v
+	}
+	for k, v := range src.scopes {
+		dst.scopes[k] = v
+	}
+	for k, v := range src.userCreatedInstances {
+		dst.userCreatedInstances[k] = v
+		dst.singletonInstances[k] = src.singletonInstances[k]
+	}
+	for k, v := range src.beanPostprocessors {
+		dst.beanPostprocessors[k] = append([]beanPostprocessor(nil), v...)
+	}
+	for k, v := range src.defaultBeans {
+		dst.defaultBeans[k] = v
+	}
+	for k, v := range src.proxyFactories {
+		dst.proxyFactories[k] = v
+	}
+	for k, v := range src.aliases {
+		dst.aliases[k] = v
+	}
+	for k, v := range src.registeredTypes {
+		dst.registeredTypes[k] = v
+	}
+	for k, v := range src.beanModules {
+		dst.beanModules[k] = v
+	}
+	for k, v := range src.primaryBeans {
+		dst.primaryBeans[k] = v
+	}
+	for k, v := range src.lazyBeans {
+		dst.lazyBeans[k] = v
+	}
+	for k, v := range src.beanDependencies {
+		dst.beanDependencies[k] = append([]string(nil), v...)
+	}
+	for k, v := range src.beanQualifiers {
+		dst.beanQualifiers[k] = v
+	}
+	for k, v := range src.beanDecorators {
+		dst.beanDecorators[k] = append([]beanDecorator(nil), v...)
+	}
+	for k, v := range src.tagAliases {
+		dst.tagAliases[k] = append([]string(nil), v...)
+	}
+	for k, v := range src.beanGroups {
+		dst.beanGroups[k] = make(map[string]bool, len(v))
+		for group := range v {
+			dst.beanGroups[k][group] = true
+		}
+	}
+}
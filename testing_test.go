@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type emailSender interface {
+	Send(to string) string
+}
+
+type smtpEmailSender struct {
+	host string
+}
+
+func (*smtpEmailSender) Send(to string) string {
+	return "smtp: " + to
+}
+
+type mockEmailSender struct {
+}
+
+func (*mockEmailSender) Send(to string) string {
+	return "mock: " + to
+}
+
+func (suite *TestSuite) TestOverrideBeanInstance() {
+	overwritten, err := RegisterBean("emailSender", reflect.TypeOf((*smtpEmailSender)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "smtp: john", GetInstance("emailSender").(emailSender).Send("john"))
+	restore := OverrideBeanInstance("emailSender", &mockEmailSender{})
+	assert.Equal(suite.T(), "mock: john", GetInstance("emailSender").(emailSender).Send("john"))
+	restore()
+	assert.Equal(suite.T(), "smtp: john", GetInstance("emailSender").(emailSender).Send("john"))
+}
+
+type emailNotifier struct {
+	Scope  Scope       `di.scope:"prototype"`
+	sender emailSender `di.inject:"emailSender"`
+}
+
+func (suite *TestSuite) TestOverrideBeanInstanceConcurrently() {
+	overwritten, err := RegisterBean("emailSender", reflect.TypeOf((*smtpEmailSender)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("emailNotifier", reflect.TypeOf((*emailNotifier)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	var wg, started sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					notifier, err := GetInstanceSafe("emailNotifier")
+					if assert.NoError(suite.T(), err) {
+						assert.NotNil(suite.T(), notifier.(*emailNotifier).sender)
+					}
+				}
+			}
+		}()
+	}
+	started.Wait()
+	for i := 0; i < 1000; i++ {
+		OverrideBeanInstance("emailSender", &mockEmailSender{})()
+	}
+	close(stop)
+	wg.Wait()
+	restore := OverrideBeanInstance("emailSender", &mockEmailSender{})
+	assert.Equal(suite.T(), "mock: john", GetInstance("emailNotifier").(*emailNotifier).sender.Send("john"))
+	restore()
+	assert.Equal(suite.T(), "smtp: john", GetInstance("emailNotifier").(*emailNotifier).sender.Send("john"))
+}
+
+func (suite *TestSuite) TestOverrideBeanInstanceBeforeInitialization() {
+	restore := OverrideBeanInstance("emailSender", &mockEmailSender{})
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "mock: john", GetInstance("emailSender").(emailSender).Send("john"))
+	assert.Panics(suite.T(), restore)
+	Close()
+	assert.NotPanics(suite.T(), restore)
+}
+
+func (suite *TestSuite) TestOverrideNotRegisteredBeanInstance() {
+	err := InitializeContainer()
+	assert.NoError(suite.T(), err)
+	restore := OverrideBeanInstance("emailSender", &mockEmailSender{})
+	assert.Equal(suite.T(), "mock: john", GetInstance("emailSender").(emailSender).Send("john"))
+	restore()
+	_, err = GetInstanceSafe("emailSender")
+	assert.Error(suite.T(), err)
+	assert.Panics(suite.T(), func() {
+		OverrideBeanInstance("emailSender", mockEmailSender{})
+	})
+}
+
+func (suite *TestSuite) TestSnapshotRestore() {
+	overwritten, err := RegisterBean("emailSender", reflect.TypeOf((*smtpEmailSender)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBeanInstance("string", new(string))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	snapshot := Snapshot()
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	emailSender1 := GetInstance("emailSender")
+	string1 := GetInstance("string")
+	Restore(snapshot)
+	_, err = GetInstanceSafe("emailSender")
+	assert.Error(suite.T(), err)
+	overwritten, err = RegisterBeanInstance("otherString", new(string))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), emailSender1 == GetInstance("emailSender"))
+	assert.True(suite.T(), string1 == GetInstance("string"))
+	assert.NotNil(suite.T(), GetInstance("otherString"))
+	Restore(snapshot)
+	assert.Len(suite.T(), GetBeanTypes(), 2)
+	Reset()
+	assert.Len(suite.T(), GetBeanTypes(), 0)
+}
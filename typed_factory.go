@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// factoryBeanTypes keep declared types of the beans registered with RegisterTypedBeanFactory.
+var factoryBeanTypes = make(map[string]reflect.Type)
+
+// sampledBeanTypes keep types of Singleton beans created by the factories with unknown return type, sampled upon the
+// container initialization.
+var sampledBeanTypes = make(map[string]reflect.Type)
+
+// RegisterTypedBeanFactory function registers bean, provided the bean factory producing instances of type `T` (which
+// must be a pointer, an interface, a function or a channel). Unlike with RegisterBeanFactory, the container is aware of
+// the type of the bean, so it's returned by GetBeanTypes and the bean can be injected by type. Return value of
+// `overwritten` is set to `true` if the bean with the same `beanID` has been registered already.
+func RegisterTypedBeanFactory[T any](beanID string, beanScope Scope, beanFactory func(ctx context.Context) (T, error)) (overwritten bool, err error) {
+	beanType := reflect.TypeOf((*T)(nil)).Elem()
+	if kind := beanType.Kind(); kind != reflect.Ptr && kind != reflect.Interface && kind != reflect.Func &&
+		kind != reflect.Chan {
+		return false, errors.New("bean factory must produce a pointer, an interface, a function or a channel: " +
+			beanType.String())
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean factory")
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{})
+	if err != nil {
+		return false, err
+	}
+	unregisterBean(beanID)
+	scopes[beanID] = beanScope
+	beanFactories[beanID] = func(ctx context.Context) (interface{}, error) {
+		return beanFactory(ctx)
+	}
+	factoryBeanTypes[beanID] = beanType
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+	return overwritten, nil
+}
+
+// getBeanType function returns the type of the bean registered by type or as a pre-created instance, the declared
+// type of the bean registered with RegisterTypedBeanFactory, or the sampled type of the Singleton bean created by
+// the factory. The returned boolean is `false` if the type is unknown.
+func getBeanType(beanID string) (reflect.Type, bool) {
+	for _, beanTypes := range []map[string]reflect.Type{beans, factoryBeanTypes, sampledBeanTypes} {
+		if beanType, ok := beanTypes[beanID]; ok {
+			return beanType, true
+		}
+	}
+	return nil, false
+}
+
+// sampleBeanType function records the type of the Singleton bean created by the factory, unless it's declared.
+func sampleBeanType(beanID string, beanInstance interface{}) {
+	if _, ok := factoryBeanTypes[beanID]; ok || isNilBean(beanInstance) {
+		return
+	}
+	sampledBeanTypes[beanID] = reflect.TypeOf(beanInstance)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type typedGreeter struct {
+	greeting string
+}
+
+func (tg *typedGreeter) Greet() string {
+	return tg.greeting
+}
+
+type greeterConsumer struct {
+	Greeter  greeter   `di.inject:""`
+	Greeters []greeter `di.inject:""`
+}
+
+func (suite *TestSuite) TestTypedBeanFactoryBeansAreInjectedByType() {
+	overwritten, err := RegisterTypedBeanFactory("greeter", Singleton, func(context.Context) (*typedGreeter, error) {
+		return &typedGreeter{greeting: "hello"}, nil
+	})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*greeterConsumer)
+	assert.Same(suite.T(), GetInstance("greeter"), consumer.Greeter)
+	assert.Equal(suite.T(), "hello", consumer.Greeter.Greet())
+	assert.Len(suite.T(), consumer.Greeters, 1)
+}
+
+func (suite *TestSuite) TestTypedBeanFactoryBeansAreAmbiguousWithBeansOfTheSameType() {
+	_, err := RegisterTypedBeanFactory("factoryGreeter", Prototype, func(context.Context) (greeter, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("greeter", reflect.TypeOf((*typedGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "more than one candidate found for the injection into field Greeter of bean "+
+		"consumer (di.greeter): factoryGreeter, greeter")
+}
+
+func (suite *TestSuite) TestTypedBeanFactoryTypesAreIntrospectable() {
+	_, err := RegisterTypedBeanFactory("greeter", Prototype, func(context.Context) (greeter, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("untyped", Prototype, func(context.Context) (interface{}, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	greeterType := reflect.TypeOf((*greeter)(nil)).Elem()
+	assert.Equal(suite.T(), map[string]reflect.Type{"greeter": greeterType}, GetBeanTypes())
+	definition, ok := (&BeanDefinitionRegistry{}).GetBeanDefinition("greeter")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), greeterType, definition.Type)
+	assert.True(suite.T(), definition.Factory)
+}
+
+func (suite *TestSuite) TestTypedBeanFactoryMustProduceReferences() {
+	_, err := RegisterTypedBeanFactory("string", Singleton, func(context.Context) (string, error) {
+		return "bean", nil
+	})
+	assert.EqualError(suite.T(), err, "bean factory must produce a pointer, an interface, a function or a channel: string")
+}
+
+func (suite *TestSuite) TestTypedBeanFactoryTypeIsDroppedWhenOverwritten() {
+	_, err := RegisterTypedBeanFactory("greeter", Singleton, func(context.Context) (*typedGreeter, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBeanFactory("greeter", Singleton, func(context.Context) (interface{}, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), GetBeanTypes())
+}
+
+func (suite *TestSuite) TestSingletonFactoryBeansAreInjectedBySampledType() {
+	_, err := RegisterBeanFactory("greeter", Singleton, func(context.Context) (interface{}, error) {
+		return &typedGreeter{greeting: "hi"}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), GetBeanTypes(), "greeter")
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	consumer := GetInstance("consumer").(*greeterConsumer)
+	assert.Same(suite.T(), GetInstance("greeter"), consumer.Greeter)
+	assert.Equal(suite.T(), reflect.TypeOf((*typedGreeter)(nil)), GetBeanTypes()["greeter"])
+}
+
+func (suite *TestSuite) TestPrototypeFactoryBeansAreNotInjectedByType() {
+	_, err := RegisterBeanFactory("greeter", Prototype, func(context.Context) (interface{}, error) {
+		return &typedGreeter{}, nil
+	})
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*greeterConsumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no candidates found for the injection")
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+// BeanValidator validates beans once they're initialized. It's satisfied by `*validator.Validate` of
+// github.com/go-playground/validator, which runs `validate` struct tags, so it can be plugged in directly:
+// `di.SetBeanValidator(validator.New())`.
+type BeanValidator interface {
+	// Struct method validates the bean, returning the error describing the invalid fields, if any.
+	Struct(bean interface{}) error
+}
+
+// BeanValidatorFunc is an adapter allowing the use of ordinary functions as BeanValidator-s.
+type BeanValidatorFunc func(bean interface{}) error
+
+// Struct method calls the function.
+func (f BeanValidatorFunc) Struct(bean interface{}) error {
+	return f(bean)
+}
+
+var beanValidator BeanValidator
+
+// beanValidatorLock guards the bean validator: it's used upon creation of beans, after the container is initialized.
+var beanValidatorLock sync.RWMutex
+
+// SetBeanValidator function enables the validation of beans: every bean struct created by the container (or registered
+// as an instance or a value) is validated after its PostConstruct and postprocessors, and the error of the validation
+// fails the container initialization (or the bean retrieval) with BeanValidationError. Nil validator, which is the
+// default, disables the validation.
+func SetBeanValidator(validator BeanValidator) {
+	beanValidatorLock.Lock()
+	defer beanValidatorLock.Unlock()
+	beanValidator = validator
+}
+
+func getBeanValidator() BeanValidator {
+	beanValidatorLock.RLock()
+	defer beanValidatorLock.RUnlock()
+	return beanValidator
+}
+
+// validateBean function validates the initialized bean, if it's a struct (or a pointer to a struct) and the validation
+// is enabled.
+func validateBean(beanID string, instance interface{}) error {
+	validator := getBeanValidator()
+	if validator == nil {
+		return nil
+	}
+	beanValue := reflect.ValueOf(instance)
+	if beanValue.Kind() == reflect.Ptr {
+		if beanValue.IsNil() {
+			return nil
+		}
+		beanValue = beanValue.Elem()
+	}
+	if beanValue.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := validator.Struct(instance); err != nil {
+		return &BeanValidationError{BeanID: beanID, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requiredFieldsErrors mimics `validator.ValidationErrors`, listing the names of the invalid fields.
+type requiredFieldsErrors []string
+
+func (e requiredFieldsErrors) Error() string {
+	return "required fields are empty: " + strings.Join(e, ", ")
+}
+
+// validateRequiredFields function mimics `validator.Validate`, supporting the `validate:"required"` tag only.
+func validateRequiredFields(bean interface{}) error {
+	beanValue := reflect.Indirect(reflect.ValueOf(bean))
+	var invalidFields requiredFieldsErrors
+	for i := 0; i < beanValue.NumField(); i++ {
+		if beanValue.Type().Field(i).Tag.Get("validate") == "required" && beanValue.Field(i).IsZero() {
+			invalidFields = append(invalidFields, beanValue.Type().Field(i).Name)
+		}
+	}
+	if invalidFields != nil {
+		return invalidFields
+	}
+	return nil
+}
+
+type validatedConfig struct {
+	URL     string `validate:"required"`
+	Retries int    `validate:"required"`
+}
+
+type validatedPrototype struct {
+	Scope Scope  `di.scope:"prototype"`
+	Name  string `validate:"required"`
+}
+
+type invalidPrototype struct {
+	Scope Scope  `di.scope:"prototype"`
+	Name  string `validate:"required"`
+}
+
+func (suite *TestSuite) TestBeanValidation() {
+	SetBeanValidator(BeanValidatorFunc(validateRequiredFields))
+	overwritten, err := RegisterBeanInstance("config", &validatedConfig{URL: "http://localhost"})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "validation of bean config failed: required fields are empty: Retries")
+	var validationError *BeanValidationError
+	if assert.True(suite.T(), errors.As(err, &validationError)) {
+		assert.Equal(suite.T(), "config", validationError.BeanID)
+	}
+	var fieldErrors requiredFieldsErrors
+	if assert.True(suite.T(), errors.As(err, &fieldErrors)) {
+		assert.Equal(suite.T(), requiredFieldsErrors{"Retries"}, fieldErrors)
+	}
+}
+
+func (suite *TestSuite) TestBeanValidationOfCreatedBeans() {
+	SetBeanValidator(BeanValidatorFunc(validateRequiredFields))
+	overwritten, err := RegisterBeanValue("config", validatedConfig{URL: "http://localhost", Retries: 3})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("prototype", reflect.TypeOf((*validatedPrototype)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = RegisterBeanPostprocessor(reflect.TypeOf((*validatedPrototype)(nil)), func(bean interface{}) error {
+		bean.(*validatedPrototype).Name = "postprocessed"
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	overwritten, err = RegisterBean("invalidPrototype", reflect.TypeOf((*invalidPrototype)(nil)))
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "postprocessed", GetInstance("prototype").(*validatedPrototype).Name)
+	_, err = GetInstanceSafe("invalidPrototype")
+	assert.EqualError(suite.T(), err, "validation of bean invalidPrototype failed: required fields are empty: Name")
+}
+
+func (suite *TestSuite) TestBeanValidationDisabledByDefault() {
+	overwritten, err := RegisterBeanInstance("config", &validatedConfig{})
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+}
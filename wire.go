@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var cleanupType = reflect.TypeOf((func())(nil))
+
+// RegisterProvider function registers bean, provided the wire-style provider function (constructor), so that existing
+// providers of google/wire can be reused verbatim. The provider must return the bean (a pointer or an interface),
+// optionally followed by the cleanup function and/or the error: `T`, `(T, error)`, `(T, func())` or
+// `(T, func(), error)`. Its parameters are resolved by type upon every call, same as fields tagged with `di.inject:""`
+// (the parameter of `context.Context` type receives the context the bean is created with). The cleanup function is
+// called when the bean goes out of scope, see RegisterBeanFactoryWithCleanup. The container is aware of the type of the
+// bean, so it can be injected by type. Return value of `overwritten` is set to `true` if the bean with the same
+// `beanID` has been registered already.
+func RegisterProvider(beanID string, beanScope Scope, provider interface{}) (overwritten bool, err error) {
+	providerValue := reflect.ValueOf(provider)
+	if err := checkProvider(providerValue); err != nil {
+		return false, err
+	}
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return false, errors.New("container is already initialized: can't register new bean factory")
+	}
+	overwritten, err = checkOverwriting(beanID, logrus.Fields{})
+	if err != nil {
+		return false, err
+	}
+	registerProvider(beanID, beanScope, providerValue)
+	return overwritten, nil
+}
+
+// RegisterProviders function registers beans provided by the wire-style provider functions (see RegisterProvider),
+// e.g. the ones listed in a wire provider set. IDs of the beans are derived from the types they provide (see
+// BeanIDOf). Registration fails if any of the derived IDs is already taken.
+func RegisterProviders(beanScope Scope, providers ...interface{}) error {
+	initializeShutdownLock.Lock()
+	defer initializeShutdownLock.Unlock()
+	if atomic.CompareAndSwapInt32(&containerInitialized, 1, 1) {
+		return errors.New("container is already initialized: can't register new bean factory")
+	}
+	for _, provider := range providers {
+		providerValue := reflect.ValueOf(provider)
+		if err := checkProvider(providerValue); err != nil {
+			return err
+		}
+		beanID := BeanIDOf(providerValue.Type().Out(0))
+		if beanID == "" {
+			return errors.New("can't derive bean ID from unnamed type: " + providerValue.Type().Out(0).String())
+		}
+		if isBeanRegistered(beanID) {
+			return errors.New("bean ID derived from the type is already registered: " + beanID)
+		}
+		registerProvider(beanID, beanScope, providerValue)
+	}
+	return nil
+}
+
+// registerProvider function registers the provider as the resolving bean factory of the type it produces.
+func registerProvider(beanID string, beanScope Scope, provider reflect.Value) {
+	unregisterBean(beanID)
+	beanFactory := func(ctx context.Context, deps Resolver) (interface{}, error) {
+		return callProvider(ctx, beanID, provider, deps)
+	}
+	scopes[beanID] = beanScope
+	beanFactories[beanID] = func(ctx context.Context) (interface{}, error) {
+		return callResolvingBeanFactory(ctx, beanID, newDependencyChain(beanID), beanFactory)
+	}
+	resolvingBeanFactories[beanID] = beanFactory
+	factoryBeanTypes[beanID] = provider.Type().Out(0)
+	emitContainerEvent(ContainerEvent{Type: BeanRegistered, BeanID: beanID})
+}
+
+// checkProvider function checks that the provider is a function with one of the supported signatures.
+func checkProvider(provider reflect.Value) error {
+	if provider.Kind() != reflect.Func || provider.IsNil() {
+		return errors.New("provider must be a function")
+	}
+	providerType := provider.Type()
+	if providerType.IsVariadic() {
+		return errors.New("provider can't be variadic: " + providerType.String())
+	}
+	if providerType.NumOut() < 1 || providerType.NumOut() > 3 {
+		return errors.New("unsupported provider signature: " + providerType.String())
+	}
+	if beanType := providerType.Out(0); beanType.Kind() != reflect.Ptr && beanType.Kind() != reflect.Interface {
+		return errors.New("provider must produce a pointer or an interface: " + providerType.String())
+	}
+	switch providerType.NumOut() {
+	case 2:
+		if providerType.Out(1) != errorType && providerType.Out(1) != cleanupType {
+			return errors.New("unsupported provider signature: " + providerType.String())
+		}
+	case 3:
+		if providerType.Out(1) != cleanupType || providerType.Out(2) != errorType {
+			return errors.New("unsupported provider signature: " + providerType.String())
+		}
+	}
+	return nil
+}
+
+// callProvider function resolves parameters of the provider by type and calls it, registering the returned cleanup
+// function (if any).
+func callProvider(ctx context.Context, beanID string, provider reflect.Value, deps Resolver) (interface{}, error) {
+	providerType := provider.Type()
+	args := make([]reflect.Value, providerType.NumIn())
+	for i := range args {
+		paramType := providerType.In(i)
+		if paramType == contextType {
+			args[i] = reflect.ValueOf(&ctx).Elem()
+			continue
+		}
+		dependencyID, err := resolveProviderParameter(beanID, i, paramType)
+		if err != nil {
+			return nil, err
+		}
+		dependency, err := deps.Resolve(dependencyID)
+		if err != nil {
+			return nil, err
+		}
+		if isNilBean(dependency) {
+			args[i] = reflect.Zero(paramType)
+			continue
+		}
+		args[i] = reflect.ValueOf(dependency)
+	}
+	results := provider.Call(args)
+	if last := results[len(results)-1]; last.Type() == errorType && !last.IsNil() {
+		return nil, last.Interface().(error)
+	}
+	beanInstance := results[0].Interface()
+	if len(results) == 1 || results[1].Type() != cleanupType || results[1].IsNil() {
+		return beanInstance, nil
+	}
+	cleanup := results[1].Interface().(func())
+	if err := checkFactoryResult(beanID, beanInstance); err != nil {
+		cleanup()
+		return nil, err
+	}
+	registerCleanup(ctx, beanCleanup{beanID: beanID, beanInstance: beanInstance, cleanup: cleanup})
+	return beanInstance, nil
+}
+
+// resolveProviderParameter function finds the bean to be passed as the parameter of the provider of the bean, the same
+// way as the candidate for the injection by type is found.
+func resolveProviderParameter(beanID string, index int, paramType reflect.Type) (string, error) {
+	return resolveParameter("provider of bean "+beanID, beanID, index, paramType)
+}
+
+// resolveParameter function finds the bean to be passed as the parameter of the function (described by `function`,
+// called to create the bean `beanID`, if any), the same way as the candidate for the injection by type is found.
+func resolveParameter(function string, beanID string, index int, paramType reflect.Type) (string, error) {
+	param := reflect.StructField{Name: "#" + strconv.Itoa(index+1), Type: paramType}
+	candidates, err := findInjectionCandidatesFor(beanID, param, paramType)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(candidates)
+	switch len(candidates) {
+	case 0:
+		return "", errors.New("no candidates found for parameter " + param.Name + " of " + function + ": " +
+			paramType.String())
+	case 1:
+		return candidates[0], nil
+	}
+	if candidate, _ := breakTie(beanID, param, candidates); candidate != "" {
+		return candidate, nil
+	}
+	if beanID == "" {
+		beanID = function
+	}
+	return "", &AmbiguousDependencyError{BeanID: beanID, Field: param.Name, Type: paramType, Candidates: candidates}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wireConfig struct {
+	URL string
+}
+
+type wireRepository struct {
+	config *wireConfig
+	closed bool
+}
+
+type wireService struct {
+	repository *wireRepository
+	greeter    greeter
+	ctx        context.Context
+}
+
+func newWireConfig() *wireConfig {
+	return &wireConfig{URL: "postgres://localhost"}
+}
+
+func newWireRepository(config *wireConfig) (*wireRepository, func(), error) {
+	repository := &wireRepository{config: config}
+	return repository, func() { repository.closed = true }, nil
+}
+
+func newWireService(ctx context.Context, repository *wireRepository, greeter greeter) (*wireService, error) {
+	return &wireService{repository: repository, greeter: greeter, ctx: ctx}, nil
+}
+
+func (suite *TestSuite) TestRegisterProviders() {
+	err := RegisterProviders(Singleton, newWireConfig, newWireRepository, newWireService)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("english", reflect.TypeOf((*englishGreeter)(nil)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), reflect.TypeOf((*wireService)(nil)), GetBeanTypes()[BeanIDOfT[*wireService]()])
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	service := GetInstance(BeanIDOfT[*wireService]()).(*wireService)
+	repository := GetInstance(BeanIDOfT[*wireRepository]()).(*wireRepository)
+	assert.Same(suite.T(), repository, service.repository)
+	assert.Same(suite.T(), GetInstance(BeanIDOfT[*wireConfig]()), repository.config)
+	assert.Same(suite.T(), GetInstance("english"), service.greeter)
+	assert.NotNil(suite.T(), service.ctx)
+	Close()
+	assert.True(suite.T(), repository.closed)
+}
+
+func (suite *TestSuite) TestRegisterProviderIsInjectedByType() {
+	type Consumer struct {
+		Config *wireConfig `di.inject:""`
+	}
+	_, err := RegisterProvider("config", Prototype, newWireConfig)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterBean("consumer", reflect.TypeOf((*Consumer)(nil)))
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "postgres://localhost", GetInstance("consumer").(*Consumer).Config.URL)
+	assert.NotSame(suite.T(), GetInstance("config"), GetInstance("config"))
+}
+
+func (suite *TestSuite) TestWireProviderErrors() {
+	_, err := RegisterProvider("failing", Singleton, func() (*wireConfig, error) {
+		return nil, errors.New("provider failed")
+	})
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "provider failed")
+}
+
+func (suite *TestSuite) TestProviderParametersMustBeResolvable() {
+	_, err := RegisterProvider("repository", Singleton, newWireRepository)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "no candidates found for parameter #1 of provider of bean repository: *di.wireConfig")
+	Reset()
+	_, err = RegisterProvider("repository", Singleton, newWireRepository)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterProvider("config", Singleton, newWireConfig)
+	assert.NoError(suite.T(), err)
+	_, err = RegisterProvider("otherConfig", Singleton, newWireConfig)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	var ambiguousDependencyError *AmbiguousDependencyError
+	assert.ErrorAs(suite.T(), err, &ambiguousDependencyError)
+	assert.Equal(suite.T(), []string{"config", "otherConfig"}, ambiguousDependencyError.Candidates)
+}
+
+func (suite *TestSuite) TestUnsupportedProviders() {
+	_, err := RegisterProvider("provider", Singleton, "not a function")
+	assert.EqualError(suite.T(), err, "provider must be a function")
+	_, err = RegisterProvider("provider", Singleton, func() wireConfig { return wireConfig{} })
+	assert.EqualError(suite.T(), err, "provider must produce a pointer or an interface: func() di.wireConfig")
+	_, err = RegisterProvider("provider", Singleton, func() (*wireConfig, string) { return nil, "" })
+	assert.EqualError(suite.T(), err, "unsupported provider signature: func() (*di.wireConfig, string)")
+	_, err = RegisterProvider("provider", Singleton, func(...string) *wireConfig { return nil })
+	assert.EqualError(suite.T(), err, "provider can't be variadic: func(...string) *di.wireConfig")
+	err = RegisterProviders(Singleton, newWireConfig, newWireConfig)
+	assert.EqualError(suite.T(), err,
+		"bean ID derived from the type is already registered: github.com/goioc/di.wireConfig")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Worker is an interface marking singleton beans processing work in the background (e.g. consuming a queue). The
+// container starts running them in their own goroutines after the container is initialized and stops upon Close.
+type Worker interface {
+	// Work method is called once in every goroutine of the worker and should process work until the context is
+	// canceled: upon Close the context is canceled and the container waits for all the goroutines to drain their
+	// in-flight work and return. Returned errors are logged.
+	Work(ctx context.Context) error
+}
+
+// Concurrent is an interface that Workers can implement to set the number of goroutines running them. Workers not
+// implementing this interface run in a single goroutine.
+type Concurrent interface {
+	// Concurrency method returns the number of goroutines running the worker.
+	Concurrency() int
+}
+
+type workerPool struct {
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+}
+
+var currentWorkerPool *workerPool
+
+// startWorkers function starts running Worker singletons. Concurrency of all the workers is validated before any of
+// them starts.
+func startWorkers() error {
+	concurrency := make(map[string]int)
+	for beanID, instance := range singletonInstances {
+		if _, ok := instance.(Worker); !ok {
+			continue
+		}
+		goroutines := 1
+		if concurrent, ok := instance.(Concurrent); ok {
+			goroutines = concurrent.Concurrency()
+		}
+		if goroutines <= 0 {
+			return errors.New("concurrency of worker " + beanID + " must be positive: " + strconv.Itoa(goroutines))
+		}
+		concurrency[beanID] = goroutines
+	}
+	if len(concurrency) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	currentWorkerPool = &workerPool{cancel: cancel}
+	for beanID, goroutines := range concurrency {
+		logrus.WithField("beanID", beanID).WithField("concurrency", goroutines).Trace("starting worker")
+		for i := 0; i < goroutines; i++ {
+			currentWorkerPool.waitGroup.Add(1)
+			go runWorker(ctx, &currentWorkerPool.waitGroup, beanID, singletonInstances[beanID].(Worker))
+		}
+	}
+	return nil
+}
+
+func runWorker(ctx context.Context, waitGroup *sync.WaitGroup, beanID string, worker Worker) {
+	defer waitGroup.Done()
+	if err := callWorker(ctx, beanID, worker); err != nil && !errors.Is(err, context.Canceled) {
+		logrus.WithField("beanID", beanID).Error(err)
+	}
+}
+
+// callWorker function runs the worker, converting the panic to the error, so that it doesn't crash the application.
+func callWorker(ctx context.Context, beanID string, worker Worker) (err error) {
+	defer recoverBeanPanic(beanID, "Work", &err)
+	return worker.Work(ctx)
+}
+
+// stopWorkers function cancels the context of the running workers, waiting for them to return if `wait` is set.
+func stopWorkers(wait bool) {
+	if currentWorkerPool == nil {
+		return
+	}
+	currentWorkerPool.cancel()
+	if wait {
+		currentWorkerPool.waitGroup.Wait()
+	}
+	currentWorkerPool = nil
+}
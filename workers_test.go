@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package di
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type queueWorker struct {
+	concurrency int
+	queue       chan int
+	running     int32
+	processed   int32
+	drained     int32
+}
+
+func (qw *queueWorker) Concurrency() int {
+	return qw.concurrency
+}
+
+func (qw *queueWorker) Work(ctx context.Context) error {
+	atomic.AddInt32(&qw.running, 1)
+	for {
+		select {
+		case <-ctx.Done():
+			atomic.AddInt32(&qw.drained, 1)
+			return ctx.Err()
+		case <-qw.queue:
+			atomic.AddInt32(&qw.processed, 1)
+		}
+	}
+}
+
+type failingWorker struct {
+	calls int32
+}
+
+func (fw *failingWorker) Work(context.Context) error {
+	atomic.AddInt32(&fw.calls, 1)
+	return errors.New("can't connect")
+}
+
+func (suite *TestSuite) TestWorkers() {
+	worker := &queueWorker{concurrency: 3, queue: make(chan int)}
+	overwritten, err := RegisterBeanInstance("worker", worker)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	failing := &failingWorker{}
+	overwritten, err = RegisterBeanInstance("failing", failing)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	for i := 0; i < 10; i++ {
+		worker.queue <- i
+	}
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&worker.running) == 3 && atomic.LoadInt32(&failing.calls) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(suite.T(), int32(0), atomic.LoadInt32(&worker.drained))
+	Close()
+	assert.Equal(suite.T(), int32(10), atomic.LoadInt32(&worker.processed))
+	assert.Equal(suite.T(), int32(3), atomic.LoadInt32(&worker.drained))
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(&failing.calls))
+}
+
+type panickingWorker struct {
+	calls int32
+}
+
+func (pw *panickingWorker) Work(context.Context) error {
+	atomic.AddInt32(&pw.calls, 1)
+	panic("queue is gone")
+}
+
+func (suite *TestSuite) TestWorkersRecoverPanics() {
+	worker := &panickingWorker{}
+	overwritten, err := RegisterBeanInstance("worker", worker)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.NoError(suite.T(), err)
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&worker.calls) == 1
+	}, time.Second, time.Millisecond)
+	Close()
+	err = callWorker(context.Background(), "worker", worker)
+	var panicErr *BeanPanicError
+	if assert.ErrorAs(suite.T(), err, &panicErr) {
+		assert.Equal(suite.T(), "worker", panicErr.BeanID)
+		assert.Equal(suite.T(), "Work", panicErr.Phase)
+		assert.Equal(suite.T(), "queue is gone", panicErr.Value)
+	}
+}
+
+func (suite *TestSuite) TestWorkersInvalidConcurrency() {
+	worker := &queueWorker{concurrency: 0, queue: make(chan int)}
+	overwritten, err := RegisterBeanInstance("worker", worker)
+	assert.False(suite.T(), overwritten)
+	assert.NoError(suite.T(), err)
+	err = InitializeContainer()
+	assert.EqualError(suite.T(), err, "concurrency of worker worker must be positive: 0")
+	assert.Equal(suite.T(), int32(0), atomic.LoadInt32(&worker.running))
+}